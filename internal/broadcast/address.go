@@ -0,0 +1,35 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ListTransactionsForAddress returns the txids touching address via the
+// address-index RPC getaddresstxids. It returns ErrMethodUnsupported on nodes
+// without it (e.g. not built or run with -addressindex).
+func (c *Client) ListTransactionsForAddress(ctx context.Context, address string) ([]string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, errors.New("broadcast: address is required")
+	}
+
+	var txids []string
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getaddresstxids", []any{map[string]any{"addresses": []string{address}}}, &txids)
+	})
+	if isMethodNotFoundErr(err) {
+		return nil, fmt.Errorf("%w: getaddresstxids", ErrMethodUnsupported)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("broadcast: getaddresstxids: %w", err)
+	}
+	for i, id := range txids {
+		txids[i] = strings.ToLower(strings.TrimSpace(id))
+	}
+	return txids, nil
+}
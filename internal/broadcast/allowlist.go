@@ -0,0 +1,93 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrAddressNotAllowed = errors.New("broadcast: transaction pays an address that is not on the allowlist")
+
+// AddressNotAllowedError lists the outputs that failed the allowlist check:
+// addresses not on the list, or "vout N (<script type>)" for outputs the node
+// could not derive an address for.
+type AddressNotAllowedError struct {
+	Outputs []string
+}
+
+func (e *AddressNotAllowedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAddressNotAllowed, strings.Join(e.Outputs, ", "))
+}
+
+func (e *AddressNotAllowedError) Unwrap() error { return ErrAddressNotAllowed }
+
+// WithAllowedAddresses makes every submit decode the tx first and refuse it
+// with ErrAddressNotAllowed unless each transparent output pays an address on
+// the list. OP_RETURN (nulldata) outputs are exempt; for multisig outputs
+// every address the node derives must be allowed. Outputs the node cannot
+// derive an address for are refused. Shielded outputs (sapling outputs,
+// orchard actions, sprout joinsplits) are opaque (see TxOutput), so their
+// recipient cannot be checked: txs with any are refused too, unless
+// WithAllowShieldedOutputs is set.
+func WithAllowedAddresses(addrs []string) Option {
+	return func(c *Client) {
+		if len(addrs) == 0 {
+			c.allowedAddrs = nil
+			return
+		}
+		c.allowedAddrs = make(map[string]struct{}, len(addrs))
+		for _, a := range addrs {
+			if a = strings.TrimSpace(a); a != "" {
+				c.allowedAddrs[a] = struct{}{}
+			}
+		}
+	}
+}
+
+// WithAllowShieldedOutputs lets txs with shielded outputs pass
+// WithAllowedAddresses and the exact matching of WithExpectedOutputs, which
+// otherwise refuse them because what they pay, and to whom, is hidden. Only
+// the transparent outputs are then checked.
+func WithAllowShieldedOutputs(enabled bool) Option {
+	return func(c *Client) {
+		c.allowShielded = enabled
+	}
+}
+
+func (c *Client) checkAllowedAddresses(ctx context.Context, rpc RPC, raw string) error {
+	if c.allowedAddrs == nil {
+		return nil
+	}
+
+	outs, err := c.inspectOutputs(ctx, rpc, raw)
+	if err != nil {
+		return err
+	}
+
+	var bad []string
+	for _, out := range outs {
+		if out.Opaque {
+			if !c.allowShielded {
+				bad = append(bad, fmt.Sprintf("%s output %d (shielded)", out.Pool, out.Index))
+			}
+			continue
+		}
+		if out.Type == "nulldata" {
+			continue
+		}
+		if len(out.Addresses) == 0 {
+			bad = append(bad, fmt.Sprintf("vout %d (%s)", out.Index, out.Type))
+			continue
+		}
+		for _, a := range out.Addresses {
+			if _, ok := c.allowedAddrs[a]; !ok {
+				bad = append(bad, a)
+			}
+		}
+	}
+	if len(bad) > 0 {
+		return &AddressNotAllowedError{Outputs: bad}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package broadcast
+
+import (
+	"context"
+	"time"
+)
+
+// AttemptEvent describes one RPC call made by the client, for WithAttemptLog.
+type AttemptEvent struct {
+	Method string
+	// Attempt is the retry attempt the call belongs to, from 1.
+	Attempt int
+	// Poll is the WaitForConfirmations poll iteration, from 1, or 0 for
+	// calls made outside a wait.
+	Poll     int
+	Duration time.Duration
+	Err      error
+}
+
+// WithAttemptLog calls fn after every RPC call with the call's retry attempt
+// and, during waits, poll iteration, so interleaved retries and polls can be
+// reconstructed from logs. fn runs on the calling goroutine and may be called
+// concurrently by concurrent client calls.
+func WithAttemptLog(fn func(AttemptEvent)) Option {
+	return func(c *Client) {
+		c.attemptLog = fn
+	}
+}
+
+type attemptKey struct{}
+
+type pollKey struct{}
+
+// withAttempt records the retry attempt in ctx for attemptLogRPC. The first
+// attempt is implied, so the common case allocates nothing.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	if attempt == 1 && ctx.Value(attemptKey{}) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+func (c *Client) withPoll(ctx context.Context, poll int) context.Context {
+	if c.attemptLog == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, pollKey{}, poll)
+}
+
+type attemptLogRPC struct {
+	next RPC
+	log  func(AttemptEvent)
+}
+
+func (a attemptLogRPC) Call(ctx context.Context, method string, params any, out any) error {
+	start := time.Now()
+	err := a.next.Call(ctx, method, params, out)
+	a.emit(ctx, method, start, err)
+	return err
+}
+
+// CallBatch logs one event per call in the batch, each with the batch's
+// round-trip time.
+func (a attemptLogRPC) CallBatch(ctx context.Context, calls []BatchCall) error {
+	start := time.Now()
+	err := callBatch(ctx, a.next, calls)
+	for _, call := range calls {
+		callErr := call.Err
+		if err != nil {
+			callErr = err
+		}
+		a.emit(ctx, call.Method, start, callErr)
+	}
+	return err
+}
+
+func (a attemptLogRPC) SendRawTransaction(ctx context.Context, txHex string) (string, error) {
+	start := time.Now()
+	txid, err := a.next.SendRawTransaction(ctx, txHex)
+	a.emit(ctx, "sendrawtransaction", start, err)
+	return txid, err
+}
+
+func (a attemptLogRPC) emit(ctx context.Context, method string, start time.Time, err error) {
+	e := AttemptEvent{Method: method, Attempt: 1, Duration: time.Since(start), Err: err}
+	if n, ok := ctx.Value(attemptKey{}).(int); ok {
+		e.Attempt = n
+	}
+	if n, ok := ctx.Value(pollKey{}).(int); ok {
+		e.Poll = n
+	}
+	a.log(e)
+}
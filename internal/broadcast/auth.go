@@ -0,0 +1,128 @@
+package broadcast
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+)
+
+const redactedToken = "[REDACTED]"
+
+// WithBearerToken is a junocashd option that authenticates every RPC request
+// with "Authorization: Bearer <token>", replacing any basic auth credentials.
+// The token is scrubbed from transport errors and error response bodies so it
+// cannot leak into logs or trace spans.
+func WithBearerToken(token string) junocashd.Option {
+	return WithRPCTransport(token, "")
+}
+
+// WithRPCTransport is a junocashd option combining WithBearerToken and
+// WithSOCKS5; each of them replaces the client's HTTP client, so use this when
+// both are needed. Empty values are skipped. The resulting client also fails
+// non-JSON responses with a NotJSONRPCError and responses over
+// DefaultMaxResponseBytes with ErrResponseTooLarge, so with both values empty
+// it only adds those checks.
+func WithRPCTransport(bearerToken, socks5Addr string) junocashd.Option {
+	return WithRPCTransportLimit(bearerToken, socks5Addr, DefaultMaxResponseBytes)
+}
+
+// WithRPCTransportLimit is WithRPCTransport with the WithMaxResponseBytes
+// limit set to maxResponseBytes (<= 0 means DefaultMaxResponseBytes). opts
+// adjust the shape of the requests sent, e.g. WithJSONRPCVersion.
+func WithRPCTransportLimit(bearerToken, socks5Addr string, maxResponseBytes int64, opts ...TransportOption) junocashd.Option {
+	return junocashd.WithHTTPClient(RPCHTTPClient(bearerToken, socks5Addr, maxResponseBytes, opts...))
+}
+
+// RPCHTTPClient returns the HTTP client WithRPCTransportLimit installs in the
+// junocashd client, for NewBatchRPC to send batches through the same
+// transport.
+func RPCHTTPClient(bearerToken, socks5Addr string, maxResponseBytes int64, opts ...TransportOption) *http.Client {
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = DefaultMaxResponseBytes
+	}
+	bearerToken = strings.TrimSpace(bearerToken)
+	socks5Addr = strings.TrimSpace(socks5Addr)
+
+	var rt http.RoundTripper = http.DefaultTransport
+	if socks5Addr != "" {
+		rt = socks5Transport(socks5Addr)
+	}
+	check := jsonRPCTransport{}
+	if bearerToken != "" {
+		bt := bearerTransport{token: bearerToken, next: rt}
+		rt, check.redact = bt, bt.redact
+	}
+	check.next = rt
+	rt = limitTransport{next: check, limit: maxResponseBytes}
+	shape := shapeTransport{next: rt}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&shape)
+		}
+	}
+	if (shape.version != "" && shape.version != "1.0") || shape.nextID != nil {
+		rt = shape
+	}
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: rt,
+	}
+}
+
+type bearerTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, t.redactErr(err)
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader([]byte(t.redact(string(body)))))
+		resp.ContentLength = -1
+	}
+	return resp, nil
+}
+
+func (t bearerTransport) redact(s string) string {
+	return strings.ReplaceAll(s, t.token, redactedToken)
+}
+
+// redactErr scrubs the token from err's message but keeps err reachable
+// through Unwrap, so context and net.Error checks still see it.
+func (t bearerTransport) redactErr(err error) error {
+	re := redactedError{msg: t.redact(err.Error()), err: err}
+	if ne, ok := err.(net.Error); ok {
+		return &redactedNetError{redactedError: re, net: ne}
+	}
+	return &re
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactedNetError is a redactedError whose inner error is a net.Error.
+type redactedNetError struct {
+	redactedError
+	net net.Error
+}
+
+func (e *redactedNetError) Timeout() bool   { return e.net.Timeout() }
+func (e *redactedNetError) Temporary() bool { return e.net.Temporary() }
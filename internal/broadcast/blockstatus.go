@@ -0,0 +1,53 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BlockStatus is where a block stands relative to the node's active chain.
+// Confirmations is the node's count: 1 for the tip, and -1 for a block the
+// node knows but that is not on the active chain (orphaned by a reorg).
+type BlockStatus struct {
+	BlockHash     string `json:"blockhash"`
+	ActiveChain   bool   `json:"active_chain"`
+	Height        int64  `json:"height"`
+	Confirmations int64  `json:"confirmations"`
+	ChainWork     string `json:"chainwork,omitempty"`
+}
+
+// BlockStatus looks up blockHash with getblockheader, to audit whether a
+// block that once confirmed a tx is still on the active chain. Hashes the
+// node does not know report found=false.
+func (c *Client) BlockStatus(ctx context.Context, blockHash string) (BlockStatus, bool, error) {
+	blockHash, ok := normalizeTxID(blockHash)
+	if !ok {
+		return BlockStatus{}, false, errors.New("broadcast: block hash must be 32-byte hex")
+	}
+	var hdr struct {
+		Hash          string `json:"hash"`
+		Height        int64  `json:"height"`
+		Confirmations int64  `json:"confirmations"`
+		ChainWork     string `json:"chainwork"`
+	}
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockheader", []any{blockHash, true}, &hdr)
+	})
+	if isNotFoundErr(err) {
+		return BlockStatus{}, false, nil
+	}
+	if err != nil {
+		return BlockStatus{}, false, fmt.Errorf("broadcast: getblockheader: %w", err)
+	}
+	return BlockStatus{
+		BlockHash:     blockHash,
+		ActiveChain:   hdr.Confirmations > 0,
+		Height:        hdr.Height,
+		Confirmations: hdr.Confirmations,
+		ChainWork:     strings.TrimSpace(hdr.ChainWork),
+	}, true, nil
+}
@@ -3,14 +3,20 @@ package broadcast
 import (
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type TxStatus struct {
@@ -18,6 +24,30 @@ type TxStatus struct {
 	InMempool     bool   `json:"in_mempool"`
 	Confirmations int64  `json:"confirmations"`
 	BlockHash     string `json:"blockhash,omitempty"`
+	BlockTime     int64  `json:"blocktime,omitempty"`
+}
+
+// TxState is a one-word summary of a TxStatus.
+type TxState string
+
+const (
+	TxStateUnknown   TxState = "unknown"
+	TxStateMempool   TxState = "mempool"
+	TxStateConfirmed TxState = "confirmed"
+)
+
+// State summarizes s: confirmed once the tx is in a block, mempool while it
+// waits in the node's mempool, unknown otherwise (including a zero TxStatus
+// for a tx the node does not know).
+func (s TxStatus) State() TxState {
+	switch {
+	case s.BlockHash != "" || s.Confirmations > 0:
+		return TxStateConfirmed
+	case s.InMempool:
+		return TxStateMempool
+	default:
+		return TxStateUnknown
+	}
 }
 
 type RPC interface {
@@ -25,11 +55,87 @@ type RPC interface {
 	SendRawTransaction(ctx context.Context, txHex string) (string, error)
 }
 
+var (
+	ErrWaitTimeout       = errors.New("broadcast: wait for confirmations timed out")
+	ErrNodeSyncing       = errors.New("broadcast: node is in initial block download")
+	ErrMethodUnsupported = errors.New("broadcast: rpc method not supported by node")
+	ErrMaxPollsExceeded  = errors.New("broadcast: wait for confirmations exceeded max polls")
+	ErrAuth              = errors.New("broadcast: rpc authentication failed (check the rpc user/password, cookie, or bearer token)")
+	ErrTxindexRequired   = errors.New("broadcast: node needs -txindex to look up confirmed transactions")
+	ErrMempoolTooLarge   = errors.New("broadcast: mempool too large to scan (enable -txindex on the node)")
+	ErrEmptyTxID         = errors.New("broadcast: sendrawtransaction succeeded but returned an empty txid (a proxy or gateway in front of the node may be mangling responses; check the rpc url)")
+
+	// ErrRetryBudgetExhausted marks a call that stopped retrying because
+	// the next retry's wait would end past ctx's deadline. It wraps the
+	// errors of the attempts made.
+	ErrRetryBudgetExhausted = errors.New("broadcast: retry budget exhausted")
+)
+
+// WaitTimeoutError is returned by WaitForConfirmations when its context
+// deadline passes. It matches both ErrWaitTimeout and context.DeadlineExceeded.
+type WaitTimeoutError struct {
+	Elapsed    time.Duration
+	LastStatus TxStatus
+	Target     int64
+	Err        error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf("%s after %s (last confirmations %d of %d): %v",
+		ErrWaitTimeout, e.Elapsed.Round(time.Millisecond), e.LastStatus.Confirmations, e.Target, e.Err)
+}
+
+func (e *WaitTimeoutError) Unwrap() []error { return []error{ErrWaitTimeout, e.Err} }
+
 type Client struct {
-	rpc           RPC
-	pollInterval  time.Duration
-	chainLookback int64
-	retry         RetryPolicy
+	rpc                RPC
+	batch              BatchCaller
+	pollInterval       time.Duration
+	chainLookback      int64
+	retry              RetryPolicy
+	returnLastOnCancel bool
+	retryMatchers      []string
+	tracer             trace.Tracer
+	cache              *StatusCache
+	requireSynced      bool
+	requireTxindex     bool
+	immediatePoll      bool
+	maxPolls           int
+	settlePolls        int
+	includeWTxID       bool
+	confirmationBase   ConfirmationBase
+	allowedAddrs       map[string]struct{}
+	zmqEndpoint        string
+	maxMempoolScan     int
+	verifyBestChain    bool
+	precheck           bool
+	readOnly           bool
+	expectedOutputs    []ExpectedOutput
+	exactOutputs       bool
+	allowShielded      bool
+	etaSampleBlocks    int64
+	skipTxIDValidation bool
+	emptyTxIDFallback  bool
+	sendRawParams      []json.RawMessage
+	respectLocktime    bool
+	tipGatedPolling    bool
+	dedup              *submitDedup
+	attemptLog         func(AttemptEvent)
+	webhookURL         string
+	webhookClient      *http.Client
+	webhookErr         func(error)
+	webhookWG          sync.WaitGroup
+	webhookCtx         context.Context
+	webhookCancel      context.CancelFunc
+	webhookDrain       time.Duration
+
+	reconnect *reconnectPolicy
+	probe     RPC
+	healthMu  sync.Mutex
+	healthErr error
+	probing   bool
+	closeOnce sync.Once
+	closed    chan struct{}
 }
 
 type Option func(*Client)
@@ -42,6 +148,50 @@ func WithPollInterval(d time.Duration) Option {
 	}
 }
 
+// WithImmediatePoll makes WaitForConfirmations re-poll as soon as the previous
+// poll returns instead of sleeping for the poll interval (for tests and CI;
+// pair it with WithMaxPolls).
+func WithImmediatePoll(enabled bool) Option {
+	return func(c *Client) {
+		c.immediatePoll = enabled
+	}
+}
+
+// WithMaxPolls bounds the number of status polls per WaitForConfirmations call;
+// once exhausted the wait fails with ErrMaxPollsExceeded. 0 means unbounded.
+func WithMaxPolls(n int) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.maxPolls = n
+		}
+	}
+}
+
+// WithSettlePolls makes WaitForConfirmations keep polling for n more polls
+// after the tx first reaches its target, and succeed only if every one of
+// them still sees it at or above the target. A poll that sees fewer
+// confirmations (the confirming block was reorged away) resets the count and
+// the wait resumes, so a confirmation that immediately disappears is not
+// acted on. 0 (the default) succeeds as soon as the target is reached.
+func WithSettlePolls(n int) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.settlePolls = n
+		}
+	}
+}
+
+// WithMaxMempoolScan bounds the getrawmempool fallback Status uses on nodes
+// without getmempoolentry: a mempool with more than n entries fails with
+// ErrMempoolTooLarge instead of being scanned. 0 means unbounded.
+func WithMaxMempoolScan(n int) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.maxMempoolScan = n
+		}
+	}
+}
+
 func WithChainLookback(lookback int64) Option {
 	return func(c *Client) {
 		if lookback >= 0 {
@@ -50,12 +200,135 @@ func WithChainLookback(lookback int64) Option {
 	}
 }
 
+func WithReturnLastOnCancel(enabled bool) Option {
+	return func(c *Client) {
+		c.returnLastOnCancel = enabled
+	}
+}
+
+// WithVerifyBestChain makes WaitForConfirmations, once the target is reached,
+// re-read the confirming block's header now and again one poll interval later,
+// and only succeed if it is on the best chain both times. An orphaned block
+// sends the wait back to polling.
+func WithVerifyBestChain(enabled bool) Option {
+	return func(c *Client) {
+		c.verifyBestChain = enabled
+	}
+}
+
+// WithRequireSynced makes Submit and WaitForConfirmations fail with
+// ErrNodeSyncing while the node reports initialblockdownload.
+func WithRequireSynced(enabled bool) Option {
+	return func(c *Client) {
+		c.requireSynced = enabled
+	}
+}
+
+// WithRequireTxindex makes Status fail with ErrTxindexRequired when
+// getrawtransaction reports that the node runs without -txindex, instead of
+// falling back to the mempool and recent-block scan.
+func WithRequireTxindex(enabled bool) Option {
+	return func(c *Client) {
+		c.requireTxindex = enabled
+	}
+}
+
+// WithIncludeWTxID makes SubmitDetailed also report the witness txid, taken
+// from decoderawtransaction's "hash" field.
+func WithIncludeWTxID(enabled bool) Option {
+	return func(c *Client) {
+		c.includeWTxID = enabled
+	}
+}
+
+// ConfirmationBase selects how WaitForConfirmations counts confirmations.
+//
+// BlockInclusive (the default) uses junocashd's count, where the block
+// containing the tx is confirmation 1, so waiting for n needs n-1 blocks on
+// top of it. BlockExclusive does not count the containing block: waiting for n
+// needs n blocks on top of it (a node count of n+1). TxStatus.Confirmations is
+// always the node's count.
+type ConfirmationBase int
+
+const (
+	BlockInclusive ConfirmationBase = iota
+	BlockExclusive
+)
+
+func ParseConfirmationBase(s string) (ConfirmationBase, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "block-inclusive":
+		return BlockInclusive, nil
+	case "block-exclusive":
+		return BlockExclusive, nil
+	default:
+		return 0, fmt.Errorf("broadcast: unknown confirmation base %q (want block-inclusive or block-exclusive)", s)
+	}
+}
+
+func WithConfirmationBase(base ConfirmationBase) Option {
+	return func(c *Client) {
+		c.confirmationBase = base
+	}
+}
+
+type reconnectPolicy struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// WithAutoReconnect marks the client unhealthy on connection-level RPC errors
+// and probes the node in the background with exponential backoff until it
+// answers again. See Healthy.
+func WithAutoReconnect(baseDelay, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		if baseDelay <= 0 {
+			baseDelay = 500 * time.Millisecond
+		}
+		if maxDelay < baseDelay {
+			maxDelay = 30 * time.Second
+		}
+		c.reconnect = &reconnectPolicy{BaseDelay: baseDelay, MaxDelay: maxDelay}
+	}
+}
+
+// WithRetryableMatchers treats errors whose message contains any of the given
+// substrings (case-insensitive) as retryable. The matchers are added to the
+// built-in transient classification rather than replacing it.
+func WithRetryableMatchers(substrs []string) Option {
+	return func(c *Client) {
+		for _, m := range substrs {
+			m = strings.ToLower(strings.TrimSpace(m))
+			if m != "" {
+				c.retryMatchers = append(c.retryMatchers, m)
+			}
+		}
+	}
+}
+
+// RetryPolicy controls how RPCs that fail with a transient error are retried.
+// The wait before retry n is BaseDelay*2^(n-1), capped at MaxDelay. With
+// Jitter the wait is instead drawn uniformly from [0, that] ("full jitter"),
+// so clients retrying against a struggling node do not retry in lockstep.
+// Budget, when > 0, caps the total time one call spends including its
+// retries: a retry whose wait would end past the budget is not made, and the
+// call fails with the errors so far, as when MaxAttempts is reached.
 type RetryPolicy struct {
 	MaxAttempts int
 	BaseDelay   time.Duration
 	MaxDelay    time.Duration
+	Jitter      bool
+	Budget      time.Duration
+
+	// now, after, and randN stand in for the clock, the retry timer, and
+	// the jitter source in tests.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+	randN func(n int64) int64
 }
 
+// WithRetryPolicy replaces the whole retry policy, including any budget or
+// jitter set by an earlier WithRetryBudget or WithRetryJitter.
 func WithRetryPolicy(p RetryPolicy) Option {
 	return func(c *Client) {
 		if p.MaxAttempts > 0 {
@@ -64,49 +337,194 @@ func WithRetryPolicy(p RetryPolicy) Option {
 	}
 }
 
+// WithRetryBudget caps the cumulative time one call spends retrying; see
+// RetryPolicy.Budget. 0 means no cap besides MaxAttempts and the context.
+func WithRetryBudget(max time.Duration) Option {
+	return func(c *Client) {
+		if max >= 0 {
+			c.retry.Budget = max
+		}
+	}
+}
+
+// WithRetryJitter enables full-jitter backoff; see RetryPolicy.Jitter.
+func WithRetryJitter(enabled bool) Option {
+	return func(c *Client) {
+		c.retry.Jitter = enabled
+	}
+}
+
 func New(rpc RPC, opts ...Option) (*Client, error) {
 	if rpc == nil {
 		return nil, errors.New("broadcast: rpc is nil")
 	}
 	c := &Client{
-		rpc:           rpc,
-		pollInterval:  500 * time.Millisecond,
-		chainLookback: 2000,
+		rpc:            rpc,
+		pollInterval:   500 * time.Millisecond,
+		chainLookback:  2000,
+		maxMempoolScan: 1_000_000,
 		retry: RetryPolicy{
 			MaxAttempts: 5,
 			BaseDelay:   200 * time.Millisecond,
 			MaxDelay:    2 * time.Second,
 		},
+		closed: make(chan struct{}),
 	}
 	for _, opt := range opts {
 		if opt != nil {
 			opt(c)
 		}
 	}
+	// Every wrapper below passes batches on, so they get the same auth
+	// mapping, read-only check, tracing and logging as single calls.
+	_, canBatch := c.rpc.(BatchCaller)
+	c.rpc = authRPC{next: c.rpc}
+	if c.readOnly {
+		c.rpc = readOnlyRPC{next: c.rpc}
+	}
+	if c.tracer != nil {
+		c.rpc = tracingRPC{next: c.rpc, tracer: c.tracer}
+	}
+	if c.attemptLog != nil {
+		c.rpc = attemptLogRPC{next: c.rpc, log: c.attemptLog}
+	}
+	if c.reconnect != nil {
+		c.probe = c.rpc
+		c.rpc = healthRPC{next: c.rpc, c: c}
+	}
+	if canBatch {
+		c.batch = c.rpc.(BatchCaller)
+	}
 	return c, nil
 }
 
+func (c *Client) Healthy() (bool, error) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	return c.healthErr == nil, c.healthErr
+}
+
+// Ping makes one cheap RPC (getblockcount) to check that the node is reachable
+// and accepts the configured credentials, retrying transient failures.
+func (c *Client) Ping(ctx context.Context) error {
+	return doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return ping(ctx, c.rpc)
+	})
+}
+
+func ping(ctx context.Context, rpc RPC) error {
+	var height int64
+	return rpc.Call(ctx, "getblockcount", nil, &height)
+}
+
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	c.drainWebhooks()
+	return nil
+}
+
 func (c *Client) Submit(ctx context.Context, rawTxHex string) (string, error) {
+	if txid, ok := c.dedup.get(rawTxHex); ok {
+		return txid, nil
+	}
+	ctx, end := c.startSpan(ctx, "broadcast.Submit")
+	txid, err := c.submit(ctx, c.rpc, rawTxHex)
+	end(err, attribute.String("juno.txid", txid))
+	if err == nil {
+		c.dedup.put(rawTxHex, txid)
+		c.notify("submitted", TxStatus{TxID: txid, InMempool: true})
+	}
+	return txid, err
+}
+
+type SubmitResult struct {
+	TxID  string `json:"txid"`
+	WTxID string `json:"wtxid,omitempty"`
+}
+
+// SubmitDetailed is Submit that, with WithIncludeWTxID, also returns the
+// wtxid. The tx is already broadcast when the wtxid is looked up, so a failed
+// lookup leaves WTxID empty rather than failing the submit.
+func (c *Client) SubmitDetailed(ctx context.Context, rawTxHex string) (SubmitResult, error) {
+	if txid, ok := c.dedup.get(rawTxHex); ok {
+		res := SubmitResult{TxID: txid}
+		if c.includeWTxID {
+			res.WTxID = c.wtxid(ctx, rawTxHex)
+		}
+		return res, nil
+	}
+	ctx, end := c.startSpan(ctx, "broadcast.Submit")
+	txid, err := c.submit(ctx, c.rpc, rawTxHex)
+	res := SubmitResult{TxID: txid}
+	if err == nil {
+		c.dedup.put(rawTxHex, txid)
+		c.notify("submitted", TxStatus{TxID: txid, InMempool: true})
+	}
+	if err == nil && c.includeWTxID {
+		res.WTxID = c.wtxid(ctx, rawTxHex)
+	}
+	end(err, attribute.String("juno.txid", txid), attribute.String("juno.wtxid", res.WTxID))
+	return res, err
+}
+
+func (c *Client) wtxid(ctx context.Context, rawTxHex string) string {
+	raw, err := normalizeHex(rawTxHex)
+	if err != nil {
+		return ""
+	}
+	var decoded struct {
+		Hash string `json:"hash"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "decoderawtransaction", []any{raw}, &decoded)
+	}); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(decoded.Hash))
+}
+
+func (c *Client) submit(ctx context.Context, rpc RPC, rawTxHex string) (string, error) {
+	if c.readOnly {
+		return "", readOnlyErr("sendrawtransaction")
+	}
 	raw, err := normalizeHex(rawTxHex)
 	if err != nil {
 		return "", err
 	}
+	if err := c.checkSynced(ctx, rpc); err != nil {
+		return "", err
+	}
+	if err := c.checkAllowedAddresses(ctx, rpc, raw); err != nil {
+		return "", err
+	}
+	if err := c.checkExpectedOutputs(ctx, rpc, raw); err != nil {
+		return "", err
+	}
+	if err := c.checkLocktime(ctx, rpc, raw); err != nil {
+		return "", err
+	}
+	if err := c.checkAccepted(ctx, rpc, raw); err != nil {
+		return "", explainFee(c.explainCoinbase(ctx, rpc, raw, err))
+	}
 
 	var txid string
 	if err := doWithRetry(ctx, c.retry, func(err error) bool {
-		return isRetryableErr(err)
+		return c.isRetryable(err)
 	}, func(ctx context.Context) error {
-		got, err := c.rpc.SendRawTransaction(ctx, raw)
+		got, err := c.sendRaw(ctx, rpc, raw)
 		if err != nil {
 			return err
 		}
 		txid = got
 		return nil
 	}); err != nil {
-		return "", err
+		return "", explainFee(c.explainCoinbase(ctx, rpc, raw, c.explainReject(ctx, rpc, raw, err)))
 	}
 
 	txid = strings.ToLower(strings.TrimSpace(txid))
+	if txid == "" {
+		return c.emptyTxID(ctx, rpc, raw)
+	}
 	if _, err := hex.DecodeString(txid); err != nil || len(txid) != 64 {
 		return "", errors.New("broadcast: node returned invalid txid")
 	}
@@ -114,33 +532,156 @@ func (c *Client) Submit(ctx context.Context, rawTxHex string) (string, error) {
 }
 
 func (c *Client) Status(ctx context.Context, txid string) (TxStatus, bool, error) {
-	txid = strings.ToLower(strings.TrimSpace(txid))
-	if _, err := hex.DecodeString(txid); err != nil || len(txid) != 64 {
-		return TxStatus{}, false, errors.New("broadcast: txid must be 32-byte hex")
+	ctx, end := c.startSpan(ctx, "broadcast.Status", attribute.String("juno.txid", txid))
+	st, found, err := c.status(ctx, txid)
+	end(err, statusAttrs(st, found)...)
+	return st, found, err
+}
+
+// StatusRaw is Status that also returns the node's complete verbose
+// getrawtransaction response for the tx, for debugging. raw is nil when the
+// tx was found without it, in the mempool through getmempoolentry or by the
+// recent-block scan, and when it was not found. StatusRaw always asks the
+// node, bypassing the status cache.
+func (c *Client) StatusRaw(ctx context.Context, txid string) (TxStatus, bool, json.RawMessage, error) {
+	txid, ok := c.checkTxID(txid)
+	if !ok {
+		return TxStatus{}, false, nil, errInvalidTxID
+	}
+	var raw json.RawMessage
+	st, found, err := c.lookupStatusRaw(ctx, txid, &raw)
+	if err != nil || !found {
+		return st, found, nil, err
+	}
+	return st, found, raw, nil
+}
+
+func (c *Client) status(ctx context.Context, txid string) (TxStatus, bool, error) {
+	check := c.checkTxID
+	if c.cache != nil {
+		check = normalizeTxID // cache file names are built from the txid
+	}
+	txid, ok := check(txid)
+	if !ok {
+		return TxStatus{}, false, errInvalidTxID
+	}
+
+	if c.cache == nil {
+		return c.lookupStatus(ctx, txid)
+	}
+	if st, ok, err := c.cachedStatus(ctx, txid); err != nil || ok {
+		return st, ok, err
+	}
+	st, found, err := c.lookupStatus(ctx, txid)
+	if err == nil && found {
+		c.storeCachedStatus(ctx, st)
+	}
+	return st, found, err
+}
+
+// StatusAt is Status for callers that already know the chain tip: it makes a
+// single getrawtransaction call and computes confirmations as tipHeight -
+// height + 1 from the tx's block height, so counts for many txids are taken
+// against the same tip. It does not consult the cache, scan the mempool, or
+// search recent blocks, so without -txindex confirmed txs the node cannot
+// look up are reported as not found. Nodes that omit the block height fall
+// back to the node's own confirmation count.
+func (c *Client) StatusAt(ctx context.Context, txid string, tipHeight int64) (TxStatus, bool, error) {
+	txid, ok := c.checkTxID(txid)
+	if !ok {
+		return TxStatus{}, false, errInvalidTxID
 	}
+	if tipHeight < 0 {
+		return TxStatus{}, false, errors.New("broadcast: tip height must be >= 0")
+	}
+
+	var verbose verboseTx
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getrawtransaction", []any{txid, 1}, &verbose)
+	})
+	if err != nil {
+		if c.requireTxindex && isTxindexRequiredErr(err) {
+			return TxStatus{}, false, fmt.Errorf("%w: %w", ErrTxindexRequired, err)
+		}
+		if isNotFoundErr(err) {
+			return TxStatus{}, false, nil
+		}
+		return TxStatus{}, false, err
+	}
+	return verbose.status(txid, tipHeight), true, nil
+}
+
+// verboseTx is the part of verbose getrawtransaction that StatusAt and
+// StatusBulk read.
+type verboseTx struct {
+	BlockHash     string `json:"blockhash"`
+	Height        int64  `json:"height"`
+	Confirmations int64  `json:"confirmations"`
+	BlockTime     int64  `json:"blocktime"`
+}
+
+// status computes the tx's status against tipHeight rather than the node's
+// own confirmations count.
+func (v verboseTx) status(txid string, tipHeight int64) TxStatus {
+	st := TxStatus{
+		TxID:          txid,
+		InMempool:     v.Confirmations == 0 && v.BlockHash == "",
+		Confirmations: v.Confirmations,
+		BlockHash:     strings.TrimSpace(v.BlockHash),
+		BlockTime:     v.BlockTime,
+	}
+	if st.BlockHash != "" && v.Height > 0 && v.Confirmations > 0 {
+		// A block above the supplied tip still counts as one confirmation.
+		st.Confirmations = max(tipHeight-v.Height+1, 1)
+	}
+	return st
+}
+
+func (c *Client) lookupStatus(ctx context.Context, txid string) (TxStatus, bool, error) {
+	return c.lookupStatusRaw(ctx, txid, nil)
+}
 
+// lookupStatusRaw is lookupStatus that, if raw is set, stores the complete
+// verbose getrawtransaction response in it when the tx is found that way.
+func (c *Client) lookupStatusRaw(ctx context.Context, txid string, raw *json.RawMessage) (TxStatus, bool, error) {
 	// Prefer a direct lookup (works for mempool; and for chain when txindex is enabled or the tx is wallet-owned).
 	var verbose struct {
 		TxID          string `json:"txid"`
 		BlockHash     string `json:"blockhash"`
 		Confirmations int64  `json:"confirmations"`
+		BlockTime     int64  `json:"blocktime"`
 	}
+	var resp json.RawMessage
 	err := doWithRetry(ctx, c.retry, func(err error) bool {
-		return isRetryableErr(err) && !isNotFoundErr(err)
+		return c.isRetryable(err) && !isNotFoundErr(err)
 	}, func(ctx context.Context) error {
-		return c.rpc.Call(ctx, "getrawtransaction", []any{txid, 1}, &verbose)
+		return c.rpc.Call(ctx, "getrawtransaction", []any{txid, 1}, &resp)
 	})
 	if err == nil {
+		if len(resp) > 0 {
+			if err := json.Unmarshal(resp, &verbose); err != nil {
+				return TxStatus{}, false, fmt.Errorf("broadcast: getrawtransaction: %w", err)
+			}
+		}
+		if raw != nil {
+			*raw = resp
+		}
 		return TxStatus{
 			TxID:          txid,
 			InMempool:     verbose.Confirmations == 0 && verbose.BlockHash == "",
 			Confirmations: verbose.Confirmations,
 			BlockHash:     strings.TrimSpace(verbose.BlockHash),
+			BlockTime:     verbose.BlockTime,
 		}, true, nil
 	}
 	if err != nil && !isNotFoundErr(err) {
 		return TxStatus{}, false, err
 	}
+	if c.requireTxindex && isTxindexRequiredErr(err) {
+		return TxStatus{}, false, fmt.Errorf("%w: %w", ErrTxindexRequired, err)
+	}
 
 	inMempool, err := c.mempoolContains(ctx, txid)
 	if err != nil {
@@ -169,20 +710,108 @@ func (c *Client) Status(ctx context.Context, txid string) (TxStatus, bool, error
 }
 
 func (c *Client) WaitForConfirmations(ctx context.Context, txid string, confirmations int64) (TxStatus, error) {
+	return c.wait(ctx, txid, confirmations, nil)
+}
+
+// WaitForConfirmationsChan is a non-blocking WaitForConfirmations. The first
+// channel receives the tx's status each time it changes, ending with the
+// final status on success; the second receives the error if the wait fails.
+// Both are closed when the wait ends. A consumer that stops reading must
+// cancel ctx so the wait can exit.
+func (c *Client) WaitForConfirmationsChan(ctx context.Context, txid string, confirmations int64) (<-chan TxStatus, <-chan error) {
+	statuses := make(chan TxStatus)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(statuses)
+
+		var sent TxStatus
+		send := func(st TxStatus) bool {
+			select {
+			case statuses <- st:
+				sent = st
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		st, err := c.wait(ctx, txid, confirmations, func(st TxStatus) { send(st) })
+		if err == nil && st != sent && !send(st) {
+			err = ctx.Err()
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return statuses, errs
+}
+
+// wait implements WaitForConfirmations, calling progress (if set) with each
+// newly observed status.
+func (c *Client) wait(ctx context.Context, txid string, confirmations int64, progress func(TxStatus)) (TxStatus, error) {
 	if confirmations < 0 {
 		return TxStatus{}, errors.New("broadcast: confirmations must be >= 0")
 	}
+	if err := c.checkSynced(ctx, c.rpc); err != nil {
+		return TxStatus{}, err
+	}
+	start := time.Now()
+	required := confirmations
+	if c.confirmationBase == BlockExclusive {
+		required++
+	}
 
-	ticker := time.NewTicker(c.pollInterval)
-	defer ticker.Stop()
+	var tick <-chan time.Time
+	var blocks <-chan struct{}
+	var ticker *time.Ticker
+	if !c.immediatePoll {
+		interval := c.pollInterval
+		if c.zmqEndpoint != "" {
+			if sub, err := subscribeZMQ(ctx, c.zmqEndpoint, "hashblock"); err == nil {
+				defer sub.Close()
+				blocks = sub.C
+				interval *= zmqSafetyPollFactor
+			}
+		}
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 
 	var pinnedBlockHash string
+	var last TxStatus
+	var found bool
+	var gate tipGate
+	// settled counts the polls since the target was first reached that
+	// still see it reached; see WithSettlePolls.
+	var settled int
+	observe := func(st TxStatus) {
+		if st != last && progress != nil {
+			progress(st)
+		}
+		last = st
+	}
 
-	for {
-		if pinnedBlockHash != "" {
-			confs, ok, err := c.blockConfirmations(ctx, pinnedBlockHash)
+	for polls := 1; ; polls++ {
+		pctx := c.withPoll(ctx, polls)
+		skip := false
+		var reached bool
+		var reachedSt TxStatus
+		if c.tipGatedPolling {
+			same, err := gate.unchanged(pctx, c)
 			if err != nil {
-				return TxStatus{}, err
+				return c.waitErr(ctx, start, confirmations, last, err)
+			}
+			skip = same && found
+		}
+		switch {
+		case skip:
+			// No new block since the last lookup; nothing to re-read.
+			reached, reachedSt = settled > 0, last
+		case pinnedBlockHash != "":
+			confs, ok, err := c.blockConfirmations(pctx, pinnedBlockHash)
+			if err != nil {
+				return c.waitErr(ctx, start, confirmations, last, err)
 			}
 			if !ok {
 				pinnedBlockHash = ""
@@ -192,30 +821,247 @@ func (c *Client) WaitForConfirmations(ctx context.Context, txid string, confirma
 					InMempool:     false,
 					Confirmations: confs,
 					BlockHash:     pinnedBlockHash,
+					BlockTime:     last.BlockTime,
 				}
-				if confirmations == 0 || confs >= confirmations {
-					return st, nil
+				observe(st)
+				if confirmations == 0 || confs >= required {
+					st, ok, err := c.settleOnBestChain(pctx, st, required)
+					if err != nil {
+						return c.waitErr(ctx, start, confirmations, last, err)
+					}
+					if ok {
+						reached, reachedSt = true, st
+					} else {
+						pinnedBlockHash = ""
+					}
 				}
 			}
-		} else {
-			st, found, err := c.Status(ctx, txid)
+		default:
+			st, ok, err := c.Status(pctx, txid)
 			if err != nil {
-				return TxStatus{}, err
+				return c.waitErr(ctx, start, confirmations, last, err)
+			}
+			found = ok
+			if found {
+				observe(st)
 			}
 			if found && st.BlockHash != "" {
 				pinnedBlockHash = st.BlockHash
 			}
-			if found && (confirmations == 0 || st.Confirmations >= confirmations) {
-				return st, nil
+			if found && (confirmations == 0 || st.Confirmations >= required) {
+				st, ok, err := c.settleOnBestChain(pctx, st, required)
+				if err != nil {
+					return c.waitErr(ctx, start, confirmations, last, err)
+				}
+				if ok {
+					reached, reachedSt = true, st
+				} else {
+					pinnedBlockHash = ""
+				}
 			}
 		}
+		if reached && settled >= c.settlePolls {
+			return c.confirmed(reachedSt), nil
+		}
+		if reached {
+			settled++
+		} else {
+			settled = 0
+		}
 
+		if c.maxPolls > 0 && polls >= c.maxPolls {
+			return last, fmt.Errorf("%w (%d)", ErrMaxPollsExceeded, c.maxPolls)
+		}
+		if tick == nil && blocks == nil {
+			if err := ctx.Err(); err != nil {
+				return c.waitErr(ctx, start, confirmations, last, err)
+			}
+			continue
+		}
 		select {
 		case <-ctx.Done():
-			return TxStatus{}, ctx.Err()
-		case <-ticker.C:
+			return c.waitErr(ctx, start, confirmations, last, ctx.Err())
+		case <-tick:
+		case _, ok := <-blocks:
+			if !ok {
+				// The subscription dropped; poll for the rest of the wait.
+				blocks = nil
+				ticker.Reset(c.pollInterval)
+			}
+		}
+	}
+}
+
+// settleOnBestChain applies WithVerifyBestChain to a wait that reached its
+// target: st is accepted only if its block is on the best chain with at least
+// required confirmations, both now and after a settle delay of one poll
+// interval. Mempool statuses are accepted as they are.
+func (c *Client) settleOnBestChain(ctx context.Context, st TxStatus, required int64) (TxStatus, bool, error) {
+	if !c.verifyBestChain || st.BlockHash == "" {
+		return st, true, nil
+	}
+	for check := 0; check < 2; check++ {
+		if check > 0 && !c.immediatePoll {
+			timer := time.NewTimer(c.pollInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return TxStatus{}, false, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		confs, ok, err := c.blockConfirmations(ctx, st.BlockHash)
+		if err != nil || !ok || confs < required {
+			return TxStatus{}, false, err
 		}
+		st.Confirmations = confs
 	}
+	return st, true, nil
+}
+
+// waitErr reports a failed wait. A passed deadline becomes a WaitTimeoutError
+// carrying the last observed status; the returned TxStatus is only populated
+// with WithReturnLastOnCancel.
+func (c *Client) waitErr(ctx context.Context, start time.Time, target int64, last TxStatus, err error) (TxStatus, error) {
+	ret := TxStatus{}
+	if c.returnLastOnCancel {
+		ret = last
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ret, &WaitTimeoutError{Elapsed: time.Since(start), LastStatus: last, Target: target, Err: ctx.Err()}
+	}
+	if !c.returnLastOnCancel || ctx.Err() == nil {
+		return TxStatus{}, err
+	}
+	return last, fmt.Errorf("%w: %w", ErrWaitTimeout, ctx.Err())
+}
+
+// authRPC classifies HTTP 401/403 responses from the transport as ErrAuth.
+type authRPC struct {
+	next RPC
+}
+
+func (a authRPC) Call(ctx context.Context, method string, params any, out any) error {
+	return authErr(a.next.Call(ctx, method, params, out))
+}
+
+func (a authRPC) SendRawTransaction(ctx context.Context, txHex string) (string, error) {
+	txid, err := a.next.SendRawTransaction(ctx, txHex)
+	return txid, authErr(err)
+}
+
+func (a authRPC) CallBatch(ctx context.Context, calls []BatchCall) error {
+	err := callBatch(ctx, a.next, calls)
+	for i := range calls {
+		calls[i].Err = authErr(calls[i].Err)
+	}
+	return authErr(err)
+}
+
+func authErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	// Drop the transport's "junocashd: request: Post ..." wrapping; the URL
+	// adds nothing to the advice in the error itself.
+	var notJSON *NotJSONRPCError
+	if errors.As(err, &notJSON) {
+		return notJSON
+	}
+	if status, ok := parseHTTPStatus(err.Error()); ok && (status == 401 || status == 403) {
+		return fmt.Errorf("%w: %w", ErrAuth, err)
+	}
+	return err
+}
+
+type healthRPC struct {
+	next RPC
+	c    *Client
+}
+
+func (h healthRPC) Call(ctx context.Context, method string, params any, out any) error {
+	err := h.next.Call(ctx, method, params, out)
+	h.c.observeRPCErr(err)
+	return err
+}
+
+func (h healthRPC) SendRawTransaction(ctx context.Context, txHex string) (string, error) {
+	txid, err := h.next.SendRawTransaction(ctx, txHex)
+	h.c.observeRPCErr(err)
+	return txid, err
+}
+
+func (h healthRPC) CallBatch(ctx context.Context, calls []BatchCall) error {
+	err := callBatch(ctx, h.next, calls)
+	h.c.observeRPCErr(err)
+	return err
+}
+
+func (c *Client) observeRPCErr(err error) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	if !isConnectionErr(err) {
+		if !isContextErr(err) {
+			c.healthErr = nil
+		}
+		return
+	}
+	c.healthErr = err
+	if c.probing {
+		return
+	}
+	c.probing = true
+	go c.reconnectLoop()
+}
+
+func (c *Client) reconnectLoop() {
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(backoff(c.reconnect.BaseDelay, c.reconnect.MaxDelay, min(attempt, 30)))
+		select {
+		case <-c.closed:
+			timer.Stop()
+			c.healthMu.Lock()
+			c.probing = false
+			c.healthMu.Unlock()
+			return
+		case <-timer.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := ping(ctx, c.probe)
+		cancel()
+
+		c.healthMu.Lock()
+		if !isConnectionErr(err) {
+			c.healthErr = nil
+			c.probing = false
+			c.healthMu.Unlock()
+			return
+		}
+		c.healthErr = err
+		c.healthMu.Unlock()
+	}
+}
+
+func (c *Client) checkSynced(ctx context.Context, rpc RPC) error {
+	if !c.requireSynced {
+		return nil
+	}
+	var info struct {
+		Blocks               int64 `json:"blocks"`
+		Headers              int64 `json:"headers"`
+		InitialBlockDownload bool  `json:"initialblockdownload"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return rpc.Call(ctx, "getblockchaininfo", nil, &info)
+	}); err != nil {
+		return fmt.Errorf("broadcast: getblockchaininfo: %w", err)
+	}
+	if info.InitialBlockDownload {
+		return fmt.Errorf("%w (blocks=%d headers=%d)", ErrNodeSyncing, info.Blocks, info.Headers)
+	}
+	return nil
 }
 
 func normalizeHex(s string) (string, error) {
@@ -240,6 +1086,18 @@ func isNotFoundErr(err error) bool {
 		strings.Contains(msg, "not found")
 }
 
+// isTxindexRequiredErr reports getrawtransaction's hint that the node can only
+// look up mempool transactions, e.g. "No such mempool transaction. Use -txindex
+// to enable blockchain transaction queries. Use gettransaction for wallet
+// transactions."
+func isTxindexRequiredErr(err error) bool {
+	var rpcErr *junocashd.RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(rpcErr.Message), "use -txindex")
+}
+
 func isMethodNotFoundErr(err error) bool {
 	var rpcErr *junocashd.RPCError
 	if !errors.As(err, &rpcErr) {
@@ -257,7 +1115,7 @@ func isMethodNotFoundErr(err error) bool {
 func (c *Client) mempoolContains(ctx context.Context, txid string) (bool, error) {
 	var entry map[string]any
 	err := doWithRetry(ctx, c.retry, func(err error) bool {
-		return isRetryableErr(err) && !isNotFoundErr(err) && !isMethodNotFoundErr(err)
+		return c.isRetryable(err) && !isNotFoundErr(err) && !isMethodNotFoundErr(err)
 	}, func(ctx context.Context) error {
 		return c.rpc.Call(ctx, "getmempoolentry", []any{txid}, &entry)
 	})
@@ -271,11 +1129,15 @@ func (c *Client) mempoolContains(ctx context.Context, txid string) (bool, error)
 		return false, fmt.Errorf("broadcast: getmempoolentry: %w", err)
 	}
 
-	var mempool []string
-	if err := doWithRetry(ctx, c.retry, isRetryableErr, func(ctx context.Context) error {
-		return c.rpc.Call(ctx, "getrawmempool", []any{false}, &mempool)
-	}); err != nil {
-		return false, fmt.Errorf("broadcast: getrawmempool: %w", err)
+	if err := c.checkMempoolScanSize(ctx); err != nil {
+		return false, err
+	}
+	mempool, err := c.rawMempool(ctx)
+	if err != nil {
+		return false, err
+	}
+	if c.maxMempoolScan > 0 && len(mempool) > c.maxMempoolScan {
+		return false, fmt.Errorf("%w: %d entries exceed the scan limit of %d", ErrMempoolTooLarge, len(mempool), c.maxMempoolScan)
 	}
 	for _, id := range mempool {
 		if strings.ToLower(strings.TrimSpace(id)) == txid {
@@ -285,8 +1147,35 @@ func (c *Client) mempoolContains(ctx context.Context, txid string) (bool, error)
 	return false, nil
 }
 
+// checkMempoolScanSize refuses to scan a mempool getmempoolinfo already
+// reports as larger than maxMempoolScan, before getrawmempool is fetched and
+// decoded. Nodes that cannot answer getmempoolinfo are left to the check on
+// the decoded list.
+func (c *Client) checkMempoolScanSize(ctx context.Context) error {
+	if c.maxMempoolScan <= 0 {
+		return nil
+	}
+	var info struct {
+		Size int64 `json:"size"`
+	}
+	if err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getmempoolinfo", nil, &info)
+	}); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return nil
+	}
+	if info.Size > int64(c.maxMempoolScan) {
+		return fmt.Errorf("%w: %d entries exceed the scan limit of %d", ErrMempoolTooLarge, info.Size, c.maxMempoolScan)
+	}
+	return nil
+}
+
 func (c *Client) findInRecentBlocks(ctx context.Context, txid string, lookback int64) (TxStatus, bool, error) {
-	tipHash, err := callString(ctx, c.retry, c.rpc, "getbestblockhash", nil)
+	tipHash, err := c.callString(ctx, "getbestblockhash", nil)
 	if err != nil {
 		return TxStatus{}, false, err
 	}
@@ -297,10 +1186,11 @@ func (c *Client) findInRecentBlocks(ctx context.Context, txid string, lookback i
 			Hash              string   `json:"hash"`
 			Confirmations     int64    `json:"confirmations"`
 			PreviousBlockHash string   `json:"previousblockhash"`
+			Time              int64    `json:"time"`
 			Tx                []string `json:"tx"`
 		}
 
-		if err := doWithRetry(ctx, c.retry, isRetryableErr, func(ctx context.Context) error {
+		if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
 			return c.rpc.Call(ctx, "getblock", []any{curHash, 1}, &blk)
 		}); err != nil {
 			return TxStatus{}, false, err
@@ -313,6 +1203,7 @@ func (c *Client) findInRecentBlocks(ctx context.Context, txid string, lookback i
 					InMempool:     false,
 					Confirmations: blk.Confirmations,
 					BlockHash:     blk.Hash,
+					BlockTime:     blk.Time,
 				}, true, nil
 			}
 		}
@@ -334,7 +1225,7 @@ func (c *Client) blockConfirmations(ctx context.Context, blockHash string) (int6
 		Confirmations int64  `json:"confirmations"`
 	}
 	if err := doWithRetry(ctx, c.retry, func(err error) bool {
-		return isRetryableErr(err) && !isNotFoundErr(err)
+		return c.isRetryable(err) && !isNotFoundErr(err)
 	}, func(ctx context.Context) error {
 		return c.rpc.Call(ctx, "getblockheader", []any{blockHash, true}, &hdr)
 	}); err != nil {
@@ -350,16 +1241,49 @@ func (c *Client) blockConfirmations(ctx context.Context, blockHash string) (int6
 	return hdr.Confirmations, true, nil
 }
 
-func callString(ctx context.Context, retry RetryPolicy, rpc RPC, method string, params any) (string, error) {
+func (c *Client) callString(ctx context.Context, method string, params any) (string, error) {
 	var out string
-	if err := doWithRetry(ctx, retry, isRetryableErr, func(ctx context.Context) error {
-		return rpc.Call(ctx, method, params, &out)
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, method, params, &out)
 	}); err != nil {
 		return "", err
 	}
 	return out, nil
 }
 
+// AttemptsError is returned by a retried RPC that failed more than once. It
+// reads and unwraps as the final attempt's error; Attempts holds every
+// attempt's error in order.
+type AttemptsError struct {
+	Attempts []error
+}
+
+func (e *AttemptsError) Error() string { return e.last().Error() }
+
+func (e *AttemptsError) Unwrap() error { return e.last() }
+
+func (e *AttemptsError) last() error { return e.Attempts[len(e.Attempts)-1] }
+
+// AttemptErrors returns the per-attempt errors recorded in err's chain, or
+// just err when it was not retried.
+func AttemptErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	var ae *AttemptsError
+	if errors.As(err, &ae) {
+		return ae.Attempts
+	}
+	return []error{err}
+}
+
+func attemptsErr(attempts []error) error {
+	if len(attempts) == 1 {
+		return attempts[0]
+	}
+	return &AttemptsError{Attempts: attempts}
+}
+
 func doWithRetry(ctx context.Context, p RetryPolicy, shouldRetry func(error) bool, fn func(context.Context) error) error {
 	if p.MaxAttempts <= 0 {
 		p.MaxAttempts = 1
@@ -370,32 +1294,52 @@ func doWithRetry(ctx context.Context, p RetryPolicy, shouldRetry func(error) boo
 	if p.MaxDelay <= 0 {
 		p.MaxDelay = 2 * time.Second
 	}
+	if p.now == nil {
+		p.now = time.Now
+	}
+	if p.after == nil {
+		p.after = time.After
+	}
+	if p.randN == nil {
+		p.randN = rand.Int64N
+	}
 
-	var lastErr error
+	start := p.now()
+	var attempts []error
 	for attempt := 1; attempt <= p.MaxAttempts; attempt++ {
 		if ctx.Err() != nil {
-			return ctx.Err()
+			return attemptsErr(append(attempts, ctx.Err()))
 		}
-		if err := fn(ctx); err == nil {
+		err := fn(withAttempt(ctx, attempt))
+		if err == nil {
 			return nil
-		} else {
-			lastErr = err
 		}
+		attempts = append(attempts, err)
 
-		if attempt == p.MaxAttempts || shouldRetry == nil || !shouldRetry(lastErr) {
+		if attempt == p.MaxAttempts || shouldRetry == nil || !shouldRetry(err) {
 			break
 		}
 
 		sleep := backoff(p.BaseDelay, p.MaxDelay, attempt)
-		timer := time.NewTimer(sleep)
+		if p.Jitter {
+			sleep = time.Duration(p.randN(int64(sleep) + 1))
+		}
+		wake := p.now().Add(sleep)
+		if p.Budget > 0 && wake.Sub(start) > p.Budget {
+			break
+		}
+		// Waiting past the caller's deadline only to give up then gains
+		// nothing; fail now with what the attempts so far returned.
+		if deadline, ok := ctx.Deadline(); ok && !wake.Before(deadline) {
+			return fmt.Errorf("%w before the deadline: %w", ErrRetryBudgetExhausted, attemptsErr(attempts))
+		}
 		select {
 		case <-ctx.Done():
-			timer.Stop()
-			return ctx.Err()
-		case <-timer.C:
+			return attemptsErr(append(attempts, ctx.Err()))
+		case <-p.after(sleep):
 		}
 	}
-	return lastErr
+	return attemptsErr(attempts)
 }
 
 func backoff(base, max time.Duration, attempt int) time.Duration {
@@ -409,6 +1353,22 @@ func backoff(base, max time.Duration, attempt int) time.Duration {
 	return d
 }
 
+func (c *Client) isRetryable(err error) bool {
+	if isRetryableErr(err) {
+		return true
+	}
+	if err == nil || isContextErr(err) || len(c.retryMatchers) == 0 {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range c.retryMatchers {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
 func isRetryableErr(err error) bool {
 	if err == nil {
 		return false
@@ -446,6 +1406,35 @@ func isRetryableErr(err error) bool {
 	return false
 }
 
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// isConnectionErr reports transport-level failures (the node could not be
+// reached at all), as opposed to errors returned by a reachable node.
+func isConnectionErr(err error) bool {
+	if err == nil || isContextErr(err) || errors.Is(err, ErrResponseTooLarge) {
+		return false
+	}
+	var rpcErr *junocashd.RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	if status, ok := parseHTTPStatus(msg); ok {
+		return status == 502 || status == 503 || status == 504
+	}
+	return strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "EOF")
+}
+
 func parseHTTPStatus(msg string) (int, bool) {
 	const prefix = "junocashd: http "
 	if !strings.HasPrefix(msg, prefix) {
@@ -1,13 +1,28 @@
 package broadcast
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type fakeRPC struct {
@@ -170,41 +185,26 @@ func TestStatus_FallbacksToChainScanWithoutTxIndex(t *testing.T) {
 			case "getblock":
 				ps := params.([]any)
 				hash := ps[0].(string)
-				dst := out.(*struct {
-					Hash              string   `json:"hash"`
-					Confirmations     int64    `json:"confirmations"`
-					PreviousBlockHash string   `json:"previousblockhash"`
-					Tx                []string `json:"tx"`
-				})
 				switch hash {
 				case "h2":
-					*dst = struct {
-						Hash              string   `json:"hash"`
-						Confirmations     int64    `json:"confirmations"`
-						PreviousBlockHash string   `json:"previousblockhash"`
-						Tx                []string `json:"tx"`
-					}{
-						Hash:              "h2",
-						Confirmations:     1,
-						PreviousBlockHash: "h1",
-						Tx:                []string{"x"},
-					}
+					return setOut(out, map[string]any{
+						"hash":              "h2",
+						"confirmations":     1,
+						"previousblockhash": "h1",
+						"time":              1700000100,
+						"tx":                []string{"x"},
+					})
 				case "h1":
-					*dst = struct {
-						Hash              string   `json:"hash"`
-						Confirmations     int64    `json:"confirmations"`
-						PreviousBlockHash string   `json:"previousblockhash"`
-						Tx                []string `json:"tx"`
-					}{
-						Hash:              "h1",
-						Confirmations:     2,
-						PreviousBlockHash: "h0",
-						Tx:                []string{strings.ToUpper(txid)},
-					}
+					return setOut(out, map[string]any{
+						"hash":              "h1",
+						"confirmations":     2,
+						"previousblockhash": "h0",
+						"time":              1700000000,
+						"tx":                []string{strings.ToUpper(txid)},
+					})
 				default:
 					return errors.New("unexpected block hash: " + hash)
 				}
-				return nil
 			default:
 				return errors.New("unexpected method: " + method)
 			}
@@ -218,7 +218,3599 @@ func TestStatus_FallbacksToChainScanWithoutTxIndex(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Status: %v", err)
 	}
-	if !found || st.InMempool || st.Confirmations != 2 || st.BlockHash != "h1" {
+	if !found || st.InMempool || st.Confirmations != 2 || st.BlockHash != "h1" || st.BlockTime != 1700000000 {
 		t.Fatalf("unexpected status: %+v found=%v", st, found)
 	}
 }
+
+func TestWaitForConfirmations_CancelReturnsEmptyStatusByDefault(t *testing.T) {
+	txid := strings.Repeat("e", 64)
+
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			switch method {
+			case "getrawtransaction":
+				return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+			case "getmempoolentry":
+				return nil
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	}, WithPollInterval(1*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	st, err := c.WaitForConfirmations(ctx, txid, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v want deadline exceeded", err)
+	}
+	var timeoutErr *WaitTimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("err=%v want *WaitTimeoutError", err)
+	}
+	if timeoutErr.Target != 1 || timeoutErr.Elapsed <= 0 || !timeoutErr.LastStatus.InMempool {
+		t.Fatalf("unexpected timeout details: %+v", timeoutErr)
+	}
+	if st != (TxStatus{}) {
+		t.Fatalf("expected empty status, got %+v", st)
+	}
+}
+
+func TestWaitForConfirmations_ReturnLastOnCancel(t *testing.T) {
+	txid := strings.Repeat("e", 64)
+
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			switch method {
+			case "getrawtransaction":
+				return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+			case "getmempoolentry":
+				return nil
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	}, WithPollInterval(1*time.Millisecond), WithReturnLastOnCancel(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	st, err := c.WaitForConfirmations(ctx, txid, 1)
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("err=%v want ErrWaitTimeout", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v want deadline exceeded", err)
+	}
+	if st.TxID != txid || !st.InMempool {
+		t.Fatalf("expected last mempool status, got %+v", st)
+	}
+}
+
+func TestAutoReconnect_MarksUnhealthyUntilNodeAnswers(t *testing.T) {
+	var mu sync.Mutex
+	down := true
+
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if down {
+				return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+			}
+			if method == "getblockcount" {
+				*out.(*int64) = 10
+			}
+			return nil
+		},
+	}, WithAutoReconnect(1*time.Millisecond, 2*time.Millisecond), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer c.Close()
+
+	if healthy, _ := c.Healthy(); !healthy {
+		t.Fatalf("expected healthy before any error")
+	}
+
+	if _, _, err := c.Status(context.Background(), strings.Repeat("f", 64)); err == nil {
+		t.Fatalf("expected connection error")
+	}
+	if healthy, herr := c.Healthy(); healthy || herr == nil {
+		t.Fatalf("expected unhealthy after connection error")
+	}
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if healthy, _ := c.Healthy(); healthy {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected client to become healthy again")
+}
+
+func TestIsConnectionErr(t *testing.T) {
+	if !isConnectionErr(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}) {
+		t.Fatalf("expected dial error to be a connection error")
+	}
+	if !isConnectionErr(errors.New("junocashd: http 503: Service Unavailable")) {
+		t.Fatalf("expected 503 to be a connection error")
+	}
+	if isConnectionErr(&junocashd.RPCError{Code: -26, Message: "bad-txns"}) {
+		t.Fatalf("rpc errors are not connection errors")
+	}
+	if isConnectionErr(context.Canceled) {
+		t.Fatalf("context errors are not connection errors")
+	}
+}
+
+func TestSubmit_RetryableMatchersAugmentBuiltIns(t *testing.T) {
+	var attempts int
+	c, err := New(fakeRPC{
+		sendRawTransaction: func(ctx context.Context, txHex string) (string, error) {
+			attempts++
+			if attempts < 3 {
+				return "", &junocashd.RPCError{Code: -1, Message: "Node Is Busy, try later"}
+			}
+			return strings.Repeat("a", 64), nil
+		},
+	}, WithRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}), WithRetryableMatchers([]string{" node is busy "}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts=%d want 3", attempts)
+	}
+
+	if !c.isRetryable(&junocashd.RPCError{Code: -28, Message: "Loading block index..."}) {
+		t.Fatalf("expected built-in transient errors to remain retryable")
+	}
+	if c.isRetryable(&junocashd.RPCError{Code: -26, Message: "bad-txns-inputs-spent"}) {
+		t.Fatalf("unexpected retryable classification")
+	}
+}
+
+func TestWithTracer_RecordsSubmitAndRPCSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	c, err := New(fakeRPC{
+		sendRawTransaction: func(ctx context.Context, txHex string) (string, error) {
+			return strings.Repeat("a", 64), nil
+		},
+	}, WithTracer(tp.Tracer("test")))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans=%d want 2", len(spans))
+	}
+	rpcSpan, submitSpan := spans[0], spans[1]
+	if rpcSpan.Name() != "junocashd.sendrawtransaction" || submitSpan.Name() != "broadcast.Submit" {
+		t.Fatalf("unexpected span names: %q %q", rpcSpan.Name(), submitSpan.Name())
+	}
+	if rpcSpan.Parent().SpanID() != submitSpan.SpanContext().SpanID() {
+		t.Fatalf("expected rpc span to be a child of the submit span")
+	}
+	var gotTxID bool
+	for _, kv := range submitSpan.Attributes() {
+		if kv.Key == "juno.txid" && kv.Value.AsString() == strings.Repeat("a", 64) {
+			gotTxID = true
+		}
+	}
+	if !gotTxID {
+		t.Fatalf("expected juno.txid attribute, got %v", submitSpan.Attributes())
+	}
+}
+
+func setOut(out any, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func TestStatusCache_SkipsLookupForCachedConfirmedTx(t *testing.T) {
+	txid := strings.Repeat("1", 64)
+	blockHash := strings.Repeat("2", 64)
+
+	calls := map[string]int{}
+	tip := int64(110)
+	onChain := true
+	rpc := fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			calls[method]++
+			switch method {
+			case "getrawtransaction":
+				return setOut(out, map[string]any{"txid": txid, "blockhash": blockHash, "confirmations": tip - 100 + 1})
+			case "getblockheader":
+				if !onChain {
+					return setOut(out, map[string]any{"hash": blockHash, "height": 100, "confirmations": -1})
+				}
+				return setOut(out, map[string]any{"hash": blockHash, "height": 100, "confirmations": tip - 100 + 1})
+			case "getblockcount":
+				return setOut(out, tip)
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	}
+
+	cache, err := NewStatusCache(t.TempDir(), 6, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStatusCache: %v", err)
+	}
+	c, err := New(rpc, WithStatusCache(cache))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	st, found, err := c.Status(context.Background(), txid)
+	if err != nil || !found || st.Confirmations != 11 {
+		t.Fatalf("first Status: st=%+v found=%v err=%v", st, found, err)
+	}
+
+	tip = 120
+	st, found, err = c.Status(context.Background(), txid)
+	if err != nil || !found || st.Confirmations != 21 || st.BlockHash != blockHash {
+		t.Fatalf("cached Status: st=%+v found=%v err=%v", st, found, err)
+	}
+	if calls["getrawtransaction"] != 1 || calls["getblockcount"] != 1 {
+		t.Fatalf("expected cache hit to skip getrawtransaction, calls=%v", calls)
+	}
+
+	// Force a best-chain re-check and report the block as orphaned: the
+	// entry must be dropped and the full lookup performed again.
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	onChain = false
+	if _, _, err := c.Status(context.Background(), txid); err != nil {
+		t.Fatalf("Status after orphan: %v", err)
+	}
+	if calls["getrawtransaction"] != 2 {
+		t.Fatalf("expected full lookup after invalidation, calls=%v", calls)
+	}
+}
+
+func TestStatusCache_CachedBlockTimeIgnoresOldRecords(t *testing.T) {
+	txid := strings.Repeat("5", 64)
+	blockHash := strings.Repeat("6", 64)
+
+	cache, err := NewStatusCache(t.TempDir(), 1, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStatusCache: %v", err)
+	}
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			switch method {
+			case "getrawtransaction":
+				return setOut(out, map[string]any{"txid": txid, "blockhash": blockHash, "confirmations": 10, "blocktime": 1700000000})
+			case "getblockheader":
+				return setOut(out, map[string]any{"hash": blockHash, "height": 100, "time": 1700000000})
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	}, WithStatusCache(cache))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, ok := c.CachedBlockTime(txid); ok {
+		t.Fatalf("CachedBlockTime before Status: want miss")
+	}
+	if _, _, err := c.Status(context.Background(), txid); err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if bt, ok := c.CachedBlockTime(txid); !ok || bt != 1700000000 {
+		t.Fatalf("CachedBlockTime=%d,%v", bt, ok)
+	}
+
+	// A record written before block times were cached has the old magic
+	// and must read as a miss, not as a zero block time.
+	old := append([]byte("JBC1"), make([]byte, 32+8+8+8)...)
+	if err := os.WriteFile(cache.path(txid), old, 0o600); err != nil {
+		t.Fatalf("write old record: %v", err)
+	}
+	if _, ok := c.CachedBlockTime(txid); ok {
+		t.Fatalf("CachedBlockTime on an old record: want miss")
+	}
+}
+
+func TestStatusCache_SkipsShallowConfirmations(t *testing.T) {
+	txid := strings.Repeat("3", 64)
+
+	var lookups int
+	cache, err := NewStatusCache(t.TempDir(), 6, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStatusCache: %v", err)
+	}
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			if method != "getrawtransaction" {
+				return errors.New("unexpected method: " + method)
+			}
+			lookups++
+			return setOut(out, map[string]any{"txid": txid, "blockhash": strings.Repeat("4", 64), "confirmations": 2})
+		},
+	}, WithStatusCache(cache))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.Status(context.Background(), txid); err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+	}
+	if lookups != 2 {
+		t.Fatalf("lookups=%d want 2 (shallow results must not be cached)", lookups)
+	}
+}
+
+func TestRequireSynced_RefusesDuringIBD(t *testing.T) {
+	ibd := true
+	var sent bool
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			if method != "getblockchaininfo" {
+				return errors.New("unexpected method: " + method)
+			}
+			return setOut(out, map[string]any{"blocks": 10, "headers": 500, "initialblockdownload": ibd})
+		},
+		sendRawTransaction: func(ctx context.Context, txHex string) (string, error) {
+			sent = true
+			return strings.Repeat("a", 64), nil
+		},
+	}, WithRequireSynced(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); !errors.Is(err, ErrNodeSyncing) {
+		t.Fatalf("err=%v want ErrNodeSyncing", err)
+	}
+	if _, err := c.WaitForConfirmations(context.Background(), strings.Repeat("a", 64), 1); !errors.Is(err, ErrNodeSyncing) {
+		t.Fatalf("err=%v want ErrNodeSyncing", err)
+	}
+	if sent {
+		t.Fatalf("sendrawtransaction must not be called while syncing")
+	}
+
+	ibd = false
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("Submit after sync: %v", err)
+	}
+}
+
+func TestMempoolInfo_FallsBackToRawMempoolCount(t *testing.T) {
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			switch method {
+			case "getmempoolinfo":
+				return &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+			case "getrawmempool":
+				return setOut(out, []string{"a", "b"})
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	info, err := c.MempoolInfo(context.Background())
+	if err != nil {
+		t.Fatalf("MempoolInfo: %v", err)
+	}
+	if info.Size != 2 || info.Bytes != nil || info.Usage != nil {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+}
+
+func TestSubmitToAll_ReportsPerEndpointOutcome(t *testing.T) {
+	c, err := New(fakeRPC{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ok := NamedRPC("http://a:8232", fakeRPC{sendRawTransaction: func(context.Context, string) (string, error) {
+		return strings.Repeat("ab", 32), nil
+	}})
+	bad := NamedRPC("http://b:8232", fakeRPC{sendRawTransaction: func(context.Context, string) (string, error) {
+		return "", errors.New("tx rejected")
+	}})
+
+	accepted, errs := c.SubmitToAll(context.Background(), "00", []RPC{ok, bad})
+	if got := accepted["http://a:8232"]; got != strings.Repeat("ab", 32) {
+		t.Fatalf("accepted=%v", accepted)
+	}
+	if len(accepted) != 1 || len(errs) != 1 {
+		t.Fatalf("accepted=%v errs=%v", accepted, errs)
+	}
+	var ee *EndpointError
+	if !errors.As(errs[0], &ee) || ee.Endpoint != "http://b:8232" {
+		t.Fatalf("errs[0]=%v", errs[0])
+	}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	self := strings.Repeat("11", 32)
+	prev := strings.Repeat("22", 32)
+	other := strings.Repeat("33", 32)
+
+	spenders := []any{
+		map[string]any{"txid": prev, "vout": 0, "spendingtxid": other},
+		map[string]any{"txid": prev, "vout": 1, "spendingtxid": self},
+		map[string]any{"txid": prev, "vout": 2},
+	}
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "decoderawtransaction":
+			return setOut(out, map[string]any{"txid": self, "vin": []any{
+				map[string]any{"txid": prev, "vout": 0},
+				map[string]any{"txid": prev, "vout": 1},
+				map[string]any{"txid": prev, "vout": 2},
+			}})
+		case "gettxspendingprevout":
+			if spenders == nil {
+				return &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+			}
+			return setOut(out, spenders)
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	conflicts, err := c.CheckConflicts(context.Background(), "00")
+	if err != nil {
+		t.Fatalf("CheckConflicts: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0] != (Conflict{TxID: prev, Vout: 0, SpendingTxID: other}) {
+		t.Fatalf("conflicts=%+v", conflicts)
+	}
+
+	spenders = nil
+	if _, err := c.CheckConflicts(context.Background(), "00"); !errors.Is(err, ErrMethodUnsupported) {
+		t.Fatalf("expected ErrMethodUnsupported, got %v", err)
+	}
+}
+
+func TestWithBearerToken_SetsHeaderAndRedacts(t *testing.T) {
+	const token = "s3cr3t-token"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Errorf("unexpected basic auth")
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer "+token {
+			t.Errorf("Authorization=%q", got)
+		}
+		http.Error(w, "denied: "+r.Header.Get("Authorization"), http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	rpc := junocashd.New(srv.URL, "", "", WithBearerToken(token))
+	err := rpc.Call(context.Background(), "getblockcount", nil, nil)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if strings.Contains(err.Error(), token) || !strings.Contains(err.Error(), redactedToken) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestListTransactionsForAddress(t *testing.T) {
+	supported := true
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		if method != "getaddresstxids" {
+			return errors.New("unexpected method " + method)
+		}
+		if !supported {
+			return &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+		}
+		b, _ := json.Marshal(params)
+		if string(b) != `[{"addresses":["t1abc"]}]` {
+			return errors.New("unexpected params " + string(b))
+		}
+		return setOut(out, []string{strings.Repeat("AB", 32)})
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	txids, err := c.ListTransactionsForAddress(context.Background(), " t1abc ")
+	if err != nil {
+		t.Fatalf("ListTransactionsForAddress: %v", err)
+	}
+	if len(txids) != 1 || txids[0] != strings.Repeat("ab", 32) {
+		t.Fatalf("txids=%v", txids)
+	}
+
+	supported = false
+	if _, err := c.ListTransactionsForAddress(context.Background(), "t1abc"); !errors.Is(err, ErrMethodUnsupported) {
+		t.Fatalf("expected ErrMethodUnsupported, got %v", err)
+	}
+}
+
+func TestWait_ImmediatePollWithMaxPolls(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var polls int
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method != "getrawtransaction" {
+			return errors.New("unexpected method " + method)
+		}
+		polls++
+		return setOut(out, map[string]any{"txid": txid})
+	}}
+	c, err := New(rpc, WithPollInterval(time.Hour), WithImmediatePoll(true), WithMaxPolls(3))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	st, err := c.WaitForConfirmations(context.Background(), txid, 1)
+	if !errors.Is(err, ErrMaxPollsExceeded) {
+		t.Fatalf("expected ErrMaxPollsExceeded, got %v", err)
+	}
+	if polls != 3 || !st.InMempool {
+		t.Fatalf("polls=%d st=%+v", polls, st)
+	}
+}
+
+func TestTranscript_RecordAndReplay(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var calls int
+	live := fakeRPC{
+		call: func(_ context.Context, method string, _ any, out any) error {
+			if method != "getrawtransaction" {
+				return errors.New("unexpected method " + method)
+			}
+			calls++
+			if calls == 1 {
+				return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+			}
+			return setOut(out, map[string]any{"txid": txid, "blockhash": strings.Repeat("cd", 32), "confirmations": 3})
+		},
+		sendRawTransaction: func(context.Context, string) (string, error) { return txid, nil },
+	}
+
+	var buf bytes.Buffer
+	rec := NewTranscriptRecorder(live, &buf)
+	if got, err := rec.SendRawTransaction(context.Background(), "00"); err != nil || got != txid {
+		t.Fatalf("SendRawTransaction=%q err=%v", got, err)
+	}
+	var out map[string]any
+	for i := 0; i < 2; i++ {
+		_ = rec.Call(context.Background(), "getrawtransaction", []any{txid, 1}, &out)
+	}
+
+	replay, err := NewTranscriptReplay(&buf)
+	if err != nil {
+		t.Fatalf("NewTranscriptReplay: %v", err)
+	}
+	if got, err := replay.SendRawTransaction(context.Background(), "00"); err != nil || got != txid {
+		t.Fatalf("replayed SendRawTransaction=%q err=%v", got, err)
+	}
+	err = replay.Call(context.Background(), "getrawtransaction", []any{txid, 1}, &out)
+	if !isNotFoundErr(err) {
+		t.Fatalf("expected recorded not-found RPC error, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		var st struct {
+			Confirmations int64 `json:"confirmations"`
+		}
+		if err := replay.Call(context.Background(), "getrawtransaction", []any{txid, 1}, &st); err != nil || st.Confirmations != 3 {
+			t.Fatalf("replay %d: st=%+v err=%v", i, st, err)
+		}
+	}
+	if err := replay.Call(context.Background(), "getblockcount", nil, nil); err == nil {
+		t.Fatalf("expected error for unrecorded call")
+	}
+}
+
+func TestSubmitDetailed_IncludesWTxID(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	wtxid := strings.Repeat("cd", 32)
+	rpc := fakeRPC{
+		sendRawTransaction: func(context.Context, string) (string, error) { return txid, nil },
+		call: func(_ context.Context, method string, _ any, out any) error {
+			if method != "decoderawtransaction" {
+				return errors.New("unexpected method " + method)
+			}
+			return setOut(out, map[string]any{"txid": txid, "hash": strings.ToUpper(wtxid)})
+		},
+	}
+
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	res, err := c.SubmitDetailed(context.Background(), "00")
+	if err != nil || res != (SubmitResult{TxID: txid}) {
+		t.Fatalf("without option: res=%+v err=%v", res, err)
+	}
+
+	c, err = New(rpc, WithIncludeWTxID(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	res, err = c.SubmitDetailed(context.Background(), "00")
+	if err != nil || res != (SubmitResult{TxID: txid, WTxID: wtxid}) {
+		t.Fatalf("with option: res=%+v err=%v", res, err)
+	}
+}
+
+func TestOutpoint(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	spender := strings.Repeat("cd", 32)
+
+	var txout any
+	var spending error
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "gettxout":
+			if txout == nil {
+				return nil
+			}
+			return setOut(out, txout)
+		case "gettxspendingprevout":
+			if spending != nil {
+				return spending
+			}
+			return setOut(out, []any{map[string]any{"txid": txid, "vout": 1, "spendingtxid": spender}})
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	txout = map[string]any{"confirmations": 4}
+	if st, err := c.Outpoint(context.Background(), txid, 1); err != nil || st != (OutpointStatus{Confirmations: 4}) {
+		t.Fatalf("unspent: st=%+v err=%v", st, err)
+	}
+
+	txout = nil
+	spent, by, err := c.IsSpent(context.Background(), txid, 1)
+	if err != nil || !spent || by != spender {
+		t.Fatalf("mempool spend: spent=%v by=%q err=%v", spent, by, err)
+	}
+
+	spending = &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+	spent, by, err = c.IsSpent(context.Background(), txid, 1)
+	if err != nil || !spent || by != "" {
+		t.Fatalf("unknown spender: spent=%v by=%q err=%v", spent, by, err)
+	}
+}
+
+func TestSubmit_ClassifiesAuthFailures(t *testing.T) {
+	var calls int
+	c, err := New(fakeRPC{sendRawTransaction: func(context.Context, string) (string, error) {
+		calls++
+		return "", errors.New("junocashd: http 401: Unauthorized")
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); !errors.Is(err, ErrAuth) {
+		t.Fatalf("expected ErrAuth, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("auth failures should not be retried, calls=%d", calls)
+	}
+}
+
+func TestWait_ConfirmationBase(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	blockhash := strings.Repeat("cd", 32)
+
+	for _, tc := range []struct {
+		base ConfirmationBase
+		want int64
+	}{
+		{BlockInclusive, 2},
+		{BlockExclusive, 3},
+	} {
+		var confs int64
+		rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+			switch method {
+			case "getrawtransaction":
+				confs++
+				return setOut(out, map[string]any{"txid": txid, "blockhash": blockhash, "confirmations": confs})
+			case "getblockheader":
+				confs++
+				return setOut(out, map[string]any{"hash": blockhash, "confirmations": confs})
+			}
+			return errors.New("unexpected method " + method)
+		}}
+		c, err := New(rpc, WithImmediatePoll(true), WithMaxPolls(10), WithConfirmationBase(tc.base))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		st, err := c.WaitForConfirmations(context.Background(), txid, 2)
+		if err != nil {
+			t.Fatalf("base=%d: %v", tc.base, err)
+		}
+		if st.Confirmations != tc.want {
+			t.Fatalf("base=%d: confirmations=%d want %d", tc.base, st.Confirmations, tc.want)
+		}
+	}
+}
+
+func TestDecodePSBT(t *testing.T) {
+	psbt := base64.StdEncoding.EncodeToString([]byte("psbt\xff\x01\x00"))
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		if method != "decodepsbt" {
+			return errors.New("unexpected method " + method)
+		}
+		return setOut(out, map[string]any{"fee": 0.0001})
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, bad := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("nope"))} {
+		if _, err := c.DecodePSBT(context.Background(), bad); !errors.Is(err, ErrInvalidPSBT) {
+			t.Fatalf("DecodePSBT(%q): expected ErrInvalidPSBT, got %v", bad, err)
+		}
+	}
+
+	decoded, err := c.DecodePSBT(context.Background(), psbt)
+	if err != nil {
+		t.Fatalf("DecodePSBT: %v", err)
+	}
+	if string(decoded) != `{"fee":0.0001}` {
+		t.Fatalf("decoded=%s", decoded)
+	}
+}
+
+func TestSubmitPSBT(t *testing.T) {
+	psbt := base64.StdEncoding.EncodeToString([]byte("psbt\xff\x01\x00"))
+	txid := strings.Repeat("ab", 32)
+
+	complete := false
+	var sent string
+	rpc := fakeRPC{
+		call: func(_ context.Context, method string, _ any, out any) error {
+			switch method {
+			case "finalizepsbt":
+				if complete {
+					return setOut(out, map[string]any{"hex": "0011", "complete": true})
+				}
+				return setOut(out, map[string]any{"psbt": psbt, "complete": false})
+			case "decodepsbt":
+				return setOut(out, map[string]any{"inputs": []any{
+					map[string]any{"final_scriptSig": map[string]any{"hex": "00"}},
+					map[string]any{"partial_signatures": map[string]any{}},
+				}})
+			}
+			return errors.New("unexpected method " + method)
+		},
+		sendRawTransaction: func(_ context.Context, txHex string) (string, error) {
+			sent = txHex
+			return txid, nil
+		},
+	}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = c.SubmitPSBT(context.Background(), psbt)
+	var incomplete *PSBTIncompleteError
+	if !errors.As(err, &incomplete) || !errors.Is(err, ErrPSBTIncomplete) {
+		t.Fatalf("expected PSBTIncompleteError, got %v", err)
+	}
+	if len(incomplete.Inputs) != 1 || incomplete.Inputs[0] != 1 {
+		t.Fatalf("inputs=%v", incomplete.Inputs)
+	}
+
+	complete = true
+	got, err := c.SubmitPSBT(context.Background(), psbt)
+	if err != nil || got != txid || sent != "0011" {
+		t.Fatalf("SubmitPSBT=%q sent=%q err=%v", got, sent, err)
+	}
+}
+
+func TestWithAllowedAddresses(t *testing.T) {
+	var sent bool
+	vout := []any{
+		map[string]any{"n": 0, "scriptPubKey": map[string]any{"type": "pubkeyhash", "addresses": []string{"t1ok"}}},
+		map[string]any{"n": 1, "scriptPubKey": map[string]any{"type": "nulldata"}},
+		map[string]any{"n": 2, "scriptPubKey": map[string]any{"type": "multisig", "addresses": []string{"t1ok", "t1other"}}},
+		map[string]any{"n": 3, "scriptPubKey": map[string]any{"type": "nonstandard"}},
+	}
+	rpc := fakeRPC{
+		call: func(_ context.Context, method string, _ any, out any) error {
+			if method != "decoderawtransaction" {
+				return errors.New("unexpected method " + method)
+			}
+			return setOut(out, map[string]any{"vout": vout})
+		},
+		sendRawTransaction: func(context.Context, string) (string, error) {
+			sent = true
+			return strings.Repeat("ab", 32), nil
+		},
+	}
+	c, err := New(rpc, WithAllowedAddresses([]string{"t1ok"}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = c.Submit(context.Background(), "00")
+	var notAllowed *AddressNotAllowedError
+	if !errors.As(err, &notAllowed) || !errors.Is(err, ErrAddressNotAllowed) {
+		t.Fatalf("expected AddressNotAllowedError, got %v", err)
+	}
+	if got := strings.Join(notAllowed.Outputs, "|"); got != "t1other|vout 3 (nonstandard)" || sent {
+		t.Fatalf("outputs=%q sent=%v", got, sent)
+	}
+
+	vout = vout[:2]
+	if _, err := c.Submit(context.Background(), "00"); err != nil || !sent {
+		t.Fatalf("allowed tx: err=%v sent=%v", err, sent)
+	}
+}
+
+func TestTxIDFromHex(t *testing.T) {
+	// Bitcoin genesis coinbase: a v1 tx whose txid is the double SHA-256.
+	const raw = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	got, err := TxIDFromHex(" " + raw + "\n")
+	if err != nil {
+		t.Fatalf("TxIDFromHex: %v", err)
+	}
+	if want := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"; got != want {
+		t.Fatalf("txid=%s want %s", got, want)
+	}
+
+	if _, err := TxIDFromHex("050000800a27a726"); err == nil {
+		t.Fatalf("expected error for v5 tx")
+	}
+	if _, err := TxIDFromHex("zz"); err == nil {
+		t.Fatalf("expected error for non-hex")
+	}
+}
+
+// fakeZMQPublisher accepts one SUB connection, completes the ZMTP handshake,
+// and publishes a hashblock message for every value sent on blocks.
+func fakeZMQPublisher(t *testing.T, blocks <-chan struct{}) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		greeting := make([]byte, 64)
+		greeting[0], greeting[9], greeting[10] = 0xff, 0x7f, 3
+		copy(greeting[12:], "NULL")
+		if _, err := conn.Write(greeting); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(r, make([]byte, 64)); err != nil {
+			return
+		}
+		if _, _, err := zmqReadFrame(r); err != nil { // READY
+			return
+		}
+		if err := zmqWriteFrame(conn, zmqFlagCommand, []byte("\x05READY")); err != nil {
+			return
+		}
+		if _, sub, err := zmqReadFrame(r); err != nil || string(sub) != "\x01hashblock" {
+			return
+		}
+		for range blocks {
+			_ = zmqWriteFrame(conn, zmqFlagMore, []byte("hashblock"))
+			_ = zmqWriteFrame(conn, zmqFlagMore, bytes.Repeat([]byte{0xcd}, 32))
+			_ = zmqWriteFrame(conn, 0, []byte{1, 0, 0, 0})
+		}
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestWait_ZMQBlockTriggersRecheck(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var mu sync.Mutex
+	mined := false
+	polled := make(chan struct{}, 10)
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method != "getrawtransaction" {
+			return errors.New("unexpected method " + method)
+		}
+		polled <- struct{}{}
+		mu.Lock()
+		defer mu.Unlock()
+		if !mined {
+			return setOut(out, map[string]any{"txid": txid})
+		}
+		return setOut(out, map[string]any{"txid": txid, "blockhash": strings.Repeat("cd", 32), "confirmations": 1})
+	}}
+
+	blocks := make(chan struct{})
+	defer close(blocks)
+	c, err := New(rpc, WithPollInterval(time.Hour), WithZMQ(fakeZMQPublisher(t, blocks)))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	go func() {
+		<-polled
+		mu.Lock()
+		mined = true
+		mu.Unlock()
+		blocks <- struct{}{}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	st, err := c.WaitForConfirmations(ctx, txid, 1)
+	if err != nil {
+		t.Fatalf("WaitForConfirmations: %v", err)
+	}
+	if st.Confirmations != 1 {
+		t.Fatalf("st=%+v", st)
+	}
+}
+
+func TestWait_ZMQKeepsSlowSafetyPoll(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var polls atomic.Int32
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if polls.Add(1) < 3 {
+			return setOut(out, map[string]any{"txid": txid})
+		}
+		return setOut(out, map[string]any{"txid": txid, "blockhash": strings.Repeat("cd", 32), "confirmations": 1})
+	}}
+
+	// The publisher never sends a block, as if every notification was lost.
+	blocks := make(chan struct{})
+	defer close(blocks)
+	c, err := New(rpc, WithPollInterval(10*time.Millisecond), WithZMQ(fakeZMQPublisher(t, blocks)))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if st, err := c.WaitForConfirmations(ctx, txid, 1); err != nil || st.Confirmations != 1 {
+		t.Fatalf("st=%+v err=%v", st, err)
+	}
+}
+
+func TestWait_ZMQUnavailableFallsBackToPolling(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	endpoint := "tcp://" + ln.Addr().String()
+	ln.Close()
+
+	txid := strings.Repeat("ab", 32)
+	var polls int
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		polls++
+		if polls < 3 {
+			return setOut(out, map[string]any{"txid": txid})
+		}
+		return setOut(out, map[string]any{"txid": txid, "blockhash": strings.Repeat("cd", 32), "confirmations": 1})
+	}}
+	c, err := New(rpc, WithPollInterval(10*time.Millisecond), WithZMQ(endpoint))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.WaitForConfirmations(ctx, txid, 1); err != nil {
+		t.Fatalf("WaitForConfirmations: %v", err)
+	}
+}
+
+func TestNodeHealth_WarnsWithoutPeers(t *testing.T) {
+	peers := 0
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getconnectioncount":
+			return setOut(out, peers)
+		case "getblockchaininfo":
+			return setOut(out, map[string]any{"blocks": 100, "headers": 100, "initialblockdownload": false})
+		case "getnetworkinfo":
+			return setOut(out, map[string]any{"warnings": ""})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	h, err := c.NodeHealth(context.Background())
+	if err != nil {
+		t.Fatalf("NodeHealth: %v", err)
+	}
+	if h.Peers != 0 || h.Blocks != 100 || len(h.Warnings) != 1 || !strings.Contains(h.Warnings[0], "may not propagate") {
+		t.Fatalf("h=%+v", h)
+	}
+
+	peers = 8
+	h, err = c.NodeHealth(context.Background())
+	if err != nil {
+		t.Fatalf("NodeHealth: %v", err)
+	}
+	if h.Peers != 8 || len(h.Warnings) != 0 {
+		t.Fatalf("h=%+v", h)
+	}
+}
+
+func TestStatus_TxindexRequiredHint(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	hint := &junocashd.RPCError{Code: -5, Message: "No such mempool transaction. Use -txindex to enable blockchain transaction queries. Use gettransaction for wallet transactions."}
+	if !isTxindexRequiredErr(hint) {
+		t.Fatalf("expected hint to be detected")
+	}
+	if isTxindexRequiredErr(&junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}) {
+		t.Fatalf("plain not-found must not be treated as the txindex hint")
+	}
+
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getrawtransaction":
+			return hint
+		case "getmempoolentry":
+			return &junocashd.RPCError{Code: -5, Message: "No such mempool transaction"}
+		case "getbestblockhash":
+			return setOut(out, "")
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, found, err := c.Status(context.Background(), txid); err != nil || found {
+		t.Fatalf("fallback: found=%v err=%v", found, err)
+	}
+
+	c, err = New(rpc, WithRequireTxindex(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, _, err := c.Status(context.Background(), txid); !errors.Is(err, ErrTxindexRequired) {
+		t.Fatalf("expected ErrTxindexRequired, got %v", err)
+	}
+}
+
+func TestMempoolFeeRate(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var entry map[string]any
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method != "getmempoolentry" {
+			return errors.New("unexpected method " + method)
+		}
+		if entry == nil {
+			return &junocashd.RPCError{Code: -5, Message: "Transaction not in mempool"}
+		}
+		return setOut(out, entry)
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	entry = map[string]any{"size": 250, "fee": 0.00001}
+	if rate, err := c.MempoolFeeRate(context.Background(), txid); err != nil || rate != 4 {
+		t.Fatalf("fee/size: rate=%v err=%v", rate, err)
+	}
+	entry = map[string]any{"size": 300, "vsize": 200, "fees": map[string]any{"base": 0.00002}, "fee": 1}
+	if rate, err := c.MempoolFeeRate(context.Background(), txid); err != nil || rate != 10 {
+		t.Fatalf("fees.base/vsize: rate=%v err=%v", rate, err)
+	}
+	entry = nil
+	if _, err := c.MempoolFeeRate(context.Background(), txid); err == nil {
+		t.Fatalf("expected error for tx not in mempool")
+	}
+}
+
+// socks5Stub is a minimal no-auth SOCKS5 server that forwards every CONNECT to
+// backend and records the requested destination.
+func socks5Stub(t *testing.T, backend string) (addr string, targets <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				hdr := make([]byte, 2)
+				if _, err := io.ReadFull(r, hdr); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(r, make([]byte, hdr[1])); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte{5, 0})
+
+				req := make([]byte, 4)
+				if _, err := io.ReadFull(r, req); err != nil || req[3] != 3 {
+					return // only domain-name targets are expected
+				}
+				n, _ := r.ReadByte()
+				host := make([]byte, int(n)+2)
+				if _, err := io.ReadFull(r, host); err != nil {
+					return
+				}
+				ch <- string(host[:n])
+
+				up, err := net.Dial("tcp", backend)
+				if err != nil {
+					return
+				}
+				defer up.Close()
+				_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go func() { _, _ = io.Copy(up, r) }()
+				_, _ = io.Copy(conn, up)
+			}()
+		}
+	}()
+	return ln.Addr().String(), ch
+}
+
+func TestWithRPCTransport_DialsThroughSOCKS5(t *testing.T) {
+	const token = "s3cr3t-token"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer "+token {
+			t.Errorf("Authorization=%q", got)
+		}
+		_, _ = w.Write([]byte(`{"result":42,"error":null,"id":1}`))
+	}))
+	defer srv.Close()
+
+	proxyAddr, targets := socks5Stub(t, strings.TrimPrefix(srv.URL, "http://"))
+	rpc := junocashd.New("http://exampleonionaddress.onion:8232", "", "", WithRPCTransport(token, proxyAddr))
+
+	var height int64
+	if err := rpc.Call(context.Background(), "getblockcount", nil, &height); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if height != 42 {
+		t.Fatalf("height=%d", height)
+	}
+	if got := <-targets; got != "exampleonionaddress.onion" {
+		t.Fatalf("proxy target=%q", got)
+	}
+}
+
+func TestMempoolPolicy_FallsBackToNetworkInfo(t *testing.T) {
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getmempoolinfo":
+			return setOut(out, map[string]any{"size": 3, "mempoolminfee": 0.00002})
+		case "getnetworkinfo":
+			return setOut(out, map[string]any{"relayfee": 0.00001, "incrementalfee": 0.000005})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	p, err := c.MempoolPolicy(context.Background())
+	if err != nil {
+		t.Fatalf("MempoolPolicy: %v", err)
+	}
+	if p.MempoolMinFee == nil || *p.MempoolMinFee != 0.00002 ||
+		p.MinRelayTxFee == nil || *p.MinRelayTxFee != 0.00001 ||
+		p.IncrementalRelayFee == nil || *p.IncrementalRelayFee != 0.000005 {
+		t.Fatalf("p=%+v", p)
+	}
+}
+
+func TestSubmit_AttemptErrorsKeepsEveryFailure(t *testing.T) {
+	msgs := []string{"Loading block index...", "Warming up", "Rescanning..."}
+	var calls int
+	c, err := New(fakeRPC{sendRawTransaction: func(context.Context, string) (string, error) {
+		msg := msgs[calls]
+		calls++
+		return "", &junocashd.RPCError{Code: -28, Message: msg}
+	}}, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = c.Submit(context.Background(), "00")
+	attempts := AttemptErrors(err)
+	if len(attempts) != 3 {
+		t.Fatalf("attempts=%v", attempts)
+	}
+	for i, a := range attempts {
+		if !strings.Contains(a.Error(), msgs[i]) {
+			t.Fatalf("attempt %d: %v", i+1, a)
+		}
+	}
+	var rpcErr *junocashd.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != msgs[2] || err.Error() != attempts[2].Error() {
+		t.Fatalf("err=%v should read as the last attempt", err)
+	}
+}
+
+func TestVerifyInclusion(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	blockHash := strings.Repeat("cd", 32)
+	confirmed := true
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getrawtransaction":
+			if !confirmed {
+				return setOut(out, map[string]any{"txid": txid})
+			}
+			return setOut(out, map[string]any{"txid": txid, "blockhash": blockHash, "confirmations": 3})
+		case "gettxoutproof":
+			if p := params.([]any); p[1] != blockHash {
+				return errors.New("unexpected block hash")
+			}
+			return setOut(out, "00ff")
+		case "verifytxoutproof":
+			return setOut(out, []string{strings.ToUpper(txid)})
+		case "getblockheader":
+			return setOut(out, map[string]any{"hash": blockHash, "height": 1234})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	proof, found, err := c.VerifyInclusion(context.Background(), txid)
+	if err != nil || !found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+	if !proof.Verified || proof.BlockHash != blockHash || proof.Height != 1234 {
+		t.Fatalf("proof=%+v", proof)
+	}
+
+	confirmed = false
+	if _, _, err := c.VerifyInclusion(context.Background(), txid); !errors.Is(err, ErrTxUnconfirmed) {
+		t.Fatalf("expected ErrTxUnconfirmed, got %v", err)
+	}
+}
+
+func TestStatus_MaxMempoolScan(t *testing.T) {
+	txid := strings.Repeat("c", 64)
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getrawtransaction":
+			return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+		case "getmempoolentry":
+			return &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+		case "getrawmempool":
+			return setOut(out, []string{strings.Repeat("1", 64), strings.Repeat("2", 64), txid})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+
+	c, err := New(rpc, WithMaxMempoolScan(2))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, _, err := c.Status(context.Background(), txid); !errors.Is(err, ErrMempoolTooLarge) {
+		t.Fatalf("expected ErrMempoolTooLarge, got %v", err)
+	}
+
+	c, err = New(rpc, WithMaxMempoolScan(3))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if st, found, err := c.Status(context.Background(), txid); err != nil || !found || !st.InMempool {
+		t.Fatalf("st=%+v found=%v err=%v", st, found, err)
+	}
+}
+
+func TestStatus_MaxMempoolScanChecksSizeFirst(t *testing.T) {
+	txid := strings.Repeat("c", 64)
+	var listed bool
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getrawtransaction":
+			return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+		case "getmempoolentry":
+			return &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+		case "getmempoolinfo":
+			return setOut(out, map[string]any{"size": 500000})
+		case "getrawmempool":
+			listed = true
+			return setOut(out, []string{txid})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+
+	c, err := New(rpc, WithMaxMempoolScan(1000))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, _, err := c.Status(context.Background(), txid); !errors.Is(err, ErrMempoolTooLarge) {
+		t.Fatalf("expected ErrMempoolTooLarge, got %v", err)
+	}
+	if listed {
+		t.Fatalf("getrawmempool fetched despite getmempoolinfo size over the limit")
+	}
+}
+
+func TestWait_VerifyBestChainSkipsOrphanedBlock(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	orphan, best := strings.Repeat("01", 32), strings.Repeat("02", 32)
+	var orphanChecks int
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getrawtransaction":
+			hash := best
+			if orphanChecks == 0 {
+				hash = orphan
+			}
+			return setOut(out, map[string]any{"txid": txid, "blockhash": hash, "confirmations": 2})
+		case "getblockheader":
+			hash := params.([]any)[0].(string)
+			if hash == orphan {
+				orphanChecks++
+				if orphanChecks > 1 {
+					return setOut(out, map[string]any{"hash": hash, "confirmations": -1})
+				}
+			}
+			return setOut(out, map[string]any{"hash": hash, "confirmations": 2})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+	c, err := New(rpc, WithImmediatePoll(true), WithMaxPolls(5), WithVerifyBestChain(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	st, err := c.WaitForConfirmations(context.Background(), txid, 2)
+	if err != nil {
+		t.Fatalf("WaitForConfirmations: %v", err)
+	}
+	if st.BlockHash != best || st.Confirmations != 2 || orphanChecks != 2 {
+		t.Fatalf("st=%+v orphanChecks=%d", st, orphanChecks)
+	}
+}
+
+func TestSubmit_ExplainsGenericAcceptToMemoryPoolReject(t *testing.T) {
+	rejected := &junocashd.RPCError{Code: -26, Message: "Transaction rejected by AcceptToMemoryPool"}
+	for _, tc := range []struct {
+		name       string
+		accept     func(out any) error
+		wantReason string
+	}{
+		{"reason", func(out any) error {
+			return setOut(out, []map[string]any{{"txid": strings.Repeat("a", 64), "allowed": false, "reject-reason": "bad-txns-in-belowout"}})
+		}, "bad-txns-in-belowout"},
+		{"unsupported", func(any) error {
+			return &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+		}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := New(fakeRPC{
+				sendRawTransaction: func(context.Context, string) (string, error) { return "", rejected },
+				call: func(_ context.Context, method string, _ any, out any) error {
+					if method != "testmempoolaccept" {
+						return errors.New("unexpected method " + method)
+					}
+					return tc.accept(out)
+				},
+			})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			_, err = c.Submit(context.Background(), "00")
+			var rpcErr *junocashd.RPCError
+			if !errors.As(err, &rpcErr) || rpcErr != rejected {
+				t.Fatalf("err=%v", err)
+			}
+			var re *RejectedError
+			if tc.wantReason == "" {
+				if errors.As(err, &re) {
+					t.Fatalf("unexpected RejectedError: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &re) || re.Reason != tc.wantReason || !strings.Contains(err.Error(), tc.wantReason) {
+				t.Fatalf("err=%v", err)
+			}
+		})
+	}
+}
+
+func TestWaitForConfirmationsChan_EmitsStatusChanges(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	blockHash := strings.Repeat("01", 32)
+	var polls int
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getrawtransaction":
+			polls++
+			if polls <= 2 {
+				return setOut(out, map[string]any{"txid": txid})
+			}
+			return setOut(out, map[string]any{"txid": txid, "blockhash": blockHash, "confirmations": 1})
+		case "getblockheader":
+			return setOut(out, map[string]any{"hash": blockHash, "confirmations": 2})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+	c, err := New(rpc, WithImmediatePoll(true), WithMaxPolls(10))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	statuses, errs := c.WaitForConfirmationsChan(context.Background(), txid, 2)
+	var got []TxStatus
+	for st := range statuses {
+		got = append(got, st)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(got) != 3 || !got[0].InMempool || got[1].Confirmations != 1 || got[2].Confirmations != 2 {
+		t.Fatalf("got=%+v", got)
+	}
+}
+
+func TestWaitForConfirmationsChan_ClosesOnCancelWithoutReader(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		return setOut(out, map[string]any{"txid": txid})
+	}}
+	c, err := New(rpc, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	statuses, errs := c.WaitForConfirmationsChan(ctx, txid, 1)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("err=%v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait did not exit after cancel")
+	}
+	for range statuses {
+	}
+}
+
+func TestSubmit_PrecheckRejectsWithoutBroadcast(t *testing.T) {
+	var accepted bool
+	var sends int
+	c, err := New(fakeRPC{
+		sendRawTransaction: func(context.Context, string) (string, error) {
+			sends++
+			return strings.Repeat("a", 64), nil
+		},
+		call: func(_ context.Context, method string, params any, out any) error {
+			if method != "testmempoolaccept" {
+				return errors.New("unexpected method " + method)
+			}
+			if raws := params.([]any)[0].([]string); len(raws) != 1 || raws[0] != "00" {
+				t.Fatalf("params=%v", params)
+			}
+			if accepted {
+				return setOut(out, []map[string]any{{"txid": strings.Repeat("a", 64), "allowed": true}})
+			}
+			return setOut(out, []map[string]any{{"txid": strings.Repeat("a", 64), "allowed": false, "reject-reason": "min relay fee not met"}})
+		},
+	}, WithPrecheck(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = c.Submit(context.Background(), "00")
+	var re *RejectedError
+	if !errors.Is(err, ErrRejected) || !errors.As(err, &re) || re.Reason != "min relay fee not met" || sends != 0 {
+		t.Fatalf("err=%v sends=%d", err, sends)
+	}
+
+	accepted = true
+	if txid, err := c.Submit(context.Background(), "00"); err != nil || txid != strings.Repeat("a", 64) || sends != 1 {
+		t.Fatalf("txid=%q err=%v sends=%d", txid, err, sends)
+	}
+}
+
+func TestStatusAt_ComputesConfirmationsFromTip(t *testing.T) {
+	confirmed, pending, missing := strings.Repeat("1", 64), strings.Repeat("2", 64), strings.Repeat("3", 64)
+	calls := map[string]int{}
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		calls[method]++
+		if method != "getrawtransaction" {
+			return errors.New("unexpected method " + method)
+		}
+		switch params.([]any)[0].(string) {
+		case confirmed:
+			return setOut(out, map[string]any{"txid": confirmed, "blockhash": strings.Repeat("a", 64), "height": 100, "confirmations": 3})
+		case pending:
+			return setOut(out, map[string]any{"txid": pending})
+		default:
+			return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+		}
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, tc := range []struct {
+		txid      string
+		tip       int64
+		found     bool
+		confs     int64
+		inMempool bool
+	}{
+		{confirmed, 105, true, 6, false},
+		{confirmed, 99, true, 1, false},
+		{pending, 105, true, 0, true},
+		{missing, 105, false, 0, false},
+	} {
+		st, found, err := c.StatusAt(context.Background(), tc.txid, tc.tip)
+		if err != nil || found != tc.found || st.Confirmations != tc.confs || st.InMempool != tc.inMempool {
+			t.Fatalf("%s@%d: st=%+v found=%v err=%v", tc.txid[:4], tc.tip, st, found, err)
+		}
+	}
+	if len(calls) != 1 || calls["getrawtransaction"] != 4 {
+		t.Fatalf("calls=%v", calls)
+	}
+}
+
+func TestPrioritise_SendsFeeDeltaOnce(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var calls int
+	c, err := New(fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		calls++
+		if method != "prioritisetransaction" {
+			return errors.New("unexpected method " + method)
+		}
+		p := params.([]any)
+		if p[0] != txid || p[2] != int64(5000) {
+			t.Fatalf("params=%v", params)
+		}
+		if calls > 1 {
+			return errors.New("connection reset by peer")
+		}
+		return setOut(out, true)
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := c.Prioritise(context.Background(), strings.ToUpper(txid), 5000); err != nil {
+		t.Fatalf("Prioritise: %v", err)
+	}
+	if err := c.Prioritise(context.Background(), txid, 5000); err == nil || calls != 2 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+	if err := c.Prioritise(context.Background(), "zz", 5000); err == nil || calls != 2 {
+		t.Fatalf("expected txid validation error, calls=%d", calls)
+	}
+}
+
+func TestReadOnly_RefusesMutatingRPCs(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var methods []string
+	rpc := fakeRPC{
+		sendRawTransaction: func(context.Context, string) (string, error) {
+			t.Fatal("sendrawtransaction reached the node")
+			return "", nil
+		},
+		call: func(_ context.Context, method string, _ any, out any) error {
+			methods = append(methods, method)
+			return setOut(out, map[string]any{"txid": txid})
+		},
+	}
+	c, err := New(rpc, WithReadOnly(true), WithRequireSynced(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Submit err=%v", err)
+	}
+	if _, errs := c.SubmitToAll(context.Background(), "00", []RPC{rpc}); len(errs) != 1 || !errors.Is(errs[0], ErrReadOnly) {
+		t.Fatalf("SubmitToAll errs=%v", errs)
+	}
+	if err := c.Prioritise(context.Background(), txid, 100); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Prioritise err=%v", err)
+	}
+	if _, _, err := c.SubmitWithFeeBump(context.Background(), "00", 3); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("SubmitWithFeeBump err=%v", err)
+	}
+	for _, method := range []string{"bumpfee", "abandontransaction"} {
+		if err := c.rpc.Call(context.Background(), method, []any{txid}, nil); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("%s err=%v", method, err)
+		}
+	}
+	if len(methods) != 0 {
+		t.Fatalf("rpc calls before refusal: %v", methods)
+	}
+
+	if st, found, err := c.Status(context.Background(), txid); err != nil || !found || !st.InMempool {
+		t.Fatalf("Status st=%+v found=%v err=%v", st, found, err)
+	}
+}
+
+func TestWithRPCTransport_RejectsHTMLResponses(t *testing.T) {
+	const token = "s3cr3t-token"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<!DOCTYPE html>\n<html><head><title>Sign in</title></head>\n<body>token " + token + "</body></html>"))
+	}))
+	defer srv.Close()
+
+	c, err := New(junocashd.New(srv.URL, "", "", WithRPCTransport(token, "")))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = c.Ping(context.Background())
+	var notJSON *NotJSONRPCError
+	if !errors.Is(err, ErrNotJSONRPC) || !errors.As(err, &notJSON) {
+		t.Fatalf("err=%v", err)
+	}
+	if notJSON.StatusCode != http.StatusOK || !strings.HasPrefix(notJSON.ContentType, "text/html") {
+		t.Fatalf("err=%+v", notJSON)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "<title>Sign in</title>") || strings.Contains(msg, token) || strings.Contains(msg, "\n") || strings.Contains(msg, "junocashd: request") {
+		t.Fatalf("message=%q", msg)
+	}
+	if len(AttemptErrors(err)) != 1 {
+		t.Fatalf("expected no retries, got %v", AttemptErrors(err))
+	}
+}
+
+func TestWithWebhook_PostsSubmittedAndConfirmedEvents(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	blockHash := strings.Repeat("01", 32)
+
+	var mu sync.Mutex
+	var events []WebhookEvent
+	var requests int
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var ev WebhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		events = append(events, ev)
+	}))
+	defer hook.Close()
+
+	rpc := fakeRPC{
+		sendRawTransaction: func(context.Context, string) (string, error) { return txid, nil },
+		call: func(_ context.Context, method string, _ any, out any) error {
+			return setOut(out, map[string]any{"txid": txid, "blockhash": blockHash, "confirmations": 1})
+		},
+	}
+	var hookErrs []error
+	c, err := New(rpc, WithImmediatePoll(true), WithWebhook(hook.URL, nil), WithWebhookErrorHandler(func(err error) { hookErrs = append(hookErrs, err) }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	c.webhookWG.Wait()
+	if _, err := c.WaitForConfirmations(context.Background(), txid, 1); err != nil {
+		t.Fatalf("WaitForConfirmations: %v", err)
+	}
+	_ = c.Close()
+
+	if len(hookErrs) != 0 || len(events) != 2 {
+		t.Fatalf("events=%+v errs=%v", events, hookErrs)
+	}
+	if events[0].Type != "submitted" || events[0].TxID != txid || events[1].Type != "confirmed" || events[1].BlockHash != blockHash || events[1].Confirmations != 1 || events[1].Timestamp.IsZero() {
+		t.Fatalf("events=%+v", events)
+	}
+}
+
+func TestWithWebhook_ReportsFailedDelivery(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer hook.Close()
+
+	var hookErrs []error
+	c, err := New(fakeRPC{sendRawTransaction: func(context.Context, string) (string, error) {
+		return strings.Repeat("a", 64), nil
+	}}, WithWebhook(strings.Replace(hook.URL, "http://", "http://user:pw@", 1)+"/?token=s3cret", nil), WithWebhookErrorHandler(func(err error) { hookErrs = append(hookErrs, err) }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	_ = c.Close()
+	if len(hookErrs) != 1 || !strings.Contains(hookErrs[0].Error(), "http 400") || strings.Contains(hookErrs[0].Error(), "s3cret") || strings.Contains(hookErrs[0].Error(), "pw") {
+		t.Fatalf("errs=%v", hookErrs)
+	}
+}
+
+func TestWithWebhook_CloseStopsRetriesAndBoundsDrain(t *testing.T) {
+	release := make(chan struct{})
+	var hang atomic.Bool
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hang.Load() {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer hook.Close()
+	defer close(release)
+
+	var mu sync.Mutex
+	var hookErrs []error
+	newClient := func() *Client {
+		c, err := New(fakeRPC{sendRawTransaction: func(context.Context, string) (string, error) {
+			return strings.Repeat("a", 64), nil
+		}}, WithWebhook(hook.URL, nil), WithWebhookErrorHandler(func(err error) {
+			mu.Lock()
+			hookErrs = append(hookErrs, err)
+			mu.Unlock()
+		}))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		return c
+	}
+
+	// A 503 is retried with a 500ms+ backoff, which the drain deadline
+	// cuts short.
+	c := newClient()
+	c.webhookDrain = 100 * time.Millisecond
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	start := time.Now()
+	_ = c.Close()
+	if d := time.Since(start); d > 400*time.Millisecond {
+		t.Fatalf("Close took %v with a retry backing off", d)
+	}
+	mu.Lock()
+	if len(hookErrs) != 1 || !strings.Contains(hookErrs[0].Error(), "http 503") {
+		t.Fatalf("errs=%v", hookErrs)
+	}
+	hookErrs = nil
+	mu.Unlock()
+
+	// A POST that never answers is cancelled at the drain deadline.
+	hang.Store(true)
+	c = newClient()
+	c.webhookDrain = 100 * time.Millisecond
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	start = time.Now()
+	_ = c.Close()
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("Close took %v with a hanging webhook", d)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hookErrs) != 1 || !errors.Is(hookErrs[0], context.Canceled) {
+		t.Fatalf("errs=%v", hookErrs)
+	}
+}
+
+func TestRawTransaction(t *testing.T) {
+	known := strings.Repeat("1", 64)
+	c, err := New(fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		p := params.([]any)
+		if method != "getrawtransaction" || p[1] != 0 {
+			return errors.New("unexpected call " + method)
+		}
+		if p[0] == known {
+			return setOut(out, " 0100FF\n")
+		}
+		return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	raw, found, err := c.RawTransaction(context.Background(), known)
+	if err != nil || !found || raw != "0100ff" {
+		t.Fatalf("raw=%q found=%v err=%v", raw, found, err)
+	}
+	if _, found, err := c.RawTransaction(context.Background(), strings.Repeat("2", 64)); err != nil || found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+}
+
+func TestWithExpectedOutputs(t *testing.T) {
+	var sent int
+	vout := []any{
+		map[string]any{"n": 0, "value": 1.5, "scriptPubKey": map[string]any{"type": "pubkeyhash", "addresses": []string{"t1pay"}}},
+		map[string]any{"n": 1, "value": 0.1, "valueZat": 10000000, "scriptPubKey": map[string]any{"type": "pubkeyhash", "address": "t1change"}},
+	}
+	rpc := fakeRPC{
+		call: func(_ context.Context, method string, _ any, out any) error {
+			if method != "decoderawtransaction" {
+				return errors.New("unexpected method " + method)
+			}
+			return setOut(out, map[string]any{"vout": vout})
+		},
+		sendRawTransaction: func(context.Context, string) (string, error) {
+			sent++
+			return strings.Repeat("ab", 32), nil
+		},
+	}
+	submit := func(exact bool, outs ...ExpectedOutput) error {
+		c, err := New(rpc, WithExpectedOutputs(outs, exact))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		_, err = c.Submit(context.Background(), "00")
+		return err
+	}
+
+	if err := submit(false, ExpectedOutput{Address: "t1pay", Amount: 150000000}); err != nil || sent != 1 {
+		t.Fatalf("matching tx: err=%v sent=%d", err, sent)
+	}
+	if err := submit(true, ExpectedOutput{Address: "t1pay", Amount: 150000000}, ExpectedOutput{Address: "t1change", Amount: 10000000}); err != nil || sent != 2 {
+		t.Fatalf("exact match: err=%v sent=%d", err, sent)
+	}
+
+	err := submit(true, ExpectedOutput{Address: "t1pay", Amount: 150000001})
+	var mismatch *OutputMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrOutputMismatch) || sent != 2 {
+		t.Fatalf("expected OutputMismatchError, got %v (sent=%d)", err, sent)
+	}
+	if got := strings.Join(mismatch.Problems, "|"); got != "missing 1.50000001 to t1pay|unexpected vout 0 (1.5 t1pay)|unexpected vout 1 (0.1 t1change)" {
+		t.Fatalf("problems=%q", got)
+	}
+
+	// The same output cannot satisfy two expectations.
+	err = submit(false, ExpectedOutput{Address: "t1pay", Amount: 150000000}, ExpectedOutput{Address: "t1pay", Amount: 150000000})
+	if !errors.Is(err, ErrOutputMismatch) {
+		t.Fatalf("expected ErrOutputMismatch for a duplicated expectation, got %v", err)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for in, want := range map[string]int64{"1": 100000000, "1.5": 150000000, "0.00000001": 1, " 20999999.99999999 ": 2099999999999999} {
+		if got, err := ParseAmount(in); err != nil || got != want {
+			t.Fatalf("ParseAmount(%q)=%d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "-1", "1.000000001", ".5", "1e8", "1.-5", "21000001"} {
+		if _, err := ParseAmount(in); err == nil {
+			t.Fatalf("ParseAmount(%q): expected error", in)
+		}
+	}
+}
+
+func TestResubmitWallet(t *testing.T) {
+	pending := strings.Repeat("a", 64)
+	known := strings.Repeat("b", 64)
+	bad := strings.Repeat("c", 64)
+	var sent []string
+	rpc := fakeRPC{
+		call: func(_ context.Context, method string, params any, out any) error {
+			switch method {
+			case "listtransactions":
+				return setOut(out, []map[string]any{
+					{"txid": pending, "confirmations": 0},
+					{"txid": pending, "confirmations": 0},
+					{"txid": known, "confirmations": 0},
+					{"txid": bad, "confirmations": 0},
+					{"txid": strings.Repeat("d", 64), "confirmations": 3},
+					{"txid": strings.Repeat("e", 64), "confirmations": -1},
+					{"txid": strings.Repeat("f", 64), "confirmations": 0, "generated": true},
+				})
+			case "gettransaction":
+				txid := params.([]any)[0].(string)
+				return setOut(out, map[string]any{"hex": txid[:4]})
+			default:
+				return errors.New("unexpected method " + method)
+			}
+		},
+		sendRawTransaction: func(_ context.Context, raw string) (string, error) {
+			sent = append(sent, raw)
+			switch raw {
+			case known[:4]:
+				return "", &junocashd.RPCError{Code: -27, Message: "transaction already in block chain"}
+			case bad[:4]:
+				return "", &junocashd.RPCError{Code: -26, Message: "18: bad-txns-inputs-spent"}
+			}
+			return pending, nil
+		},
+	}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	got, err := c.ResubmitWallet(context.Background())
+	if err == nil || !strings.Contains(err.Error(), bad) || !strings.Contains(err.Error(), "bad-txns-inputs-spent") {
+		t.Fatalf("expected error for %s, got %v", bad, err)
+	}
+	if strings.Join(got, ",") != pending+","+known || len(sent) != 3 {
+		t.Fatalf("handled=%v sent=%v", got, sent)
+	}
+
+	ro, err := New(rpc, WithReadOnly(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := ro.ResubmitWallet(context.Background()); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestTestAcceptBatch(t *testing.T) {
+	var gotRaws []string
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		if method != "testmempoolaccept" {
+			return errors.New("unexpected method " + method)
+		}
+		gotRaws, _ = params.([]any)[0].([]string)
+		return setOut(out, []map[string]any{
+			{"txid": strings.Repeat("a", 64), "allowed": true, "fees": map[string]any{"base": 0.0001}},
+			{"txid": strings.Repeat("b", 64), "allowed": false, "reject-reason": "missing-inputs"},
+		})
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, err := c.TestAcceptBatch(context.Background(), []string{" 00 ", "01"})
+	if err != nil {
+		t.Fatalf("TestAcceptBatch: %v", err)
+	}
+	if strings.Join(gotRaws, ",") != "00,01" {
+		t.Fatalf("raws=%v", gotRaws)
+	}
+	if len(res) != 2 || !res[0].Allowed || res[0].Fees == nil || res[0].Fees.Base != 0.0001 || res[1].Allowed || res[1].RejectReason != "missing-inputs" {
+		t.Fatalf("results=%+v", res)
+	}
+
+	if _, err := c.TestAcceptBatch(context.Background(), []string{"00"}); err == nil || !strings.Contains(err.Error(), "2 results for 1 txs") {
+		t.Fatalf("expected result count error, got %v", err)
+	}
+	if _, err := c.TestAcceptBatch(context.Background(), []string{"00", "zz"}); err == nil || !strings.Contains(err.Error(), "tx 2") {
+		t.Fatalf("expected invalid hex error, got %v", err)
+	}
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	body := `{"result":123,"error":null,"id":1,"pad":"` + strings.Repeat("x", 200) + `"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Has("chunked") {
+			// Flushing before the body is written drops Content-Length.
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	for _, url := range []string{srv.URL, srv.URL + "/?chunked=1"} {
+		c, err := New(junocashd.New(url, "", "", WithMaxResponseBytes(100)))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := c.Ping(context.Background()); !errors.Is(err, ErrResponseTooLarge) || len(AttemptErrors(err)) != 1 {
+			t.Fatalf("%s: expected one ErrResponseTooLarge attempt, got %v", url, err)
+		}
+
+		c, err = New(junocashd.New(url, "", "", WithMaxResponseBytes(int64(len(body)))))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := c.Ping(context.Background()); err != nil {
+			t.Fatalf("%s: body at the limit: %v", url, err)
+		}
+	}
+}
+
+func TestWithRPCTransport_DefaultLimitBelowClientCap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(`{"result":123,"error":null,"id":1,"pad":"` + strings.Repeat("x", 9<<20) + `"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(junocashd.New(srv.URL, "", "", WithRPCTransport("", "")))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.Ping(context.Background()); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+// fakeBatchRPC answers verbose getrawtransaction from txs, one JSON-RPC batch
+// per CallBatch, and counts round trips.
+type fakeBatchRPC struct {
+	fakeRPC
+	tip     int64
+	txs     map[string]map[string]any
+	batches int
+}
+
+func (f *fakeBatchRPC) Call(_ context.Context, method string, _ any, out any) error {
+	if method != "getblockcount" {
+		return errors.New("unexpected method " + method)
+	}
+	return setOut(out, f.tip)
+}
+
+func (f *fakeBatchRPC) CallBatch(_ context.Context, calls []BatchCall) error {
+	f.batches++
+	for i := range calls {
+		txid := calls[i].Params.([]any)[0].(string)
+		tx, ok := f.txs[txid]
+		if !ok {
+			calls[i].Err = &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+			continue
+		}
+		if err := setOut(calls[i].Out, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStatusBulk(t *testing.T) {
+	mined := strings.Repeat("a", 64)
+	pending := strings.Repeat("b", 64)
+	unknown := strings.Repeat("c", 64)
+	rpc := &fakeBatchRPC{tip: 110, txs: map[string]map[string]any{
+		// The node's own count is stale; the snapshot tip wins.
+		mined:   {"blockhash": strings.Repeat("01", 32), "height": 101, "confirmations": 3, "blocktime": 1700000000},
+		pending: {"confirmations": 0},
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	txids := []string{mined, strings.ToUpper(pending)}
+	for i := 0; i < bulkBatchSize; i++ {
+		txids = append(txids, unknown)
+	}
+	got, err := c.StatusBulk(context.Background(), txids)
+	if err != nil {
+		t.Fatalf("StatusBulk: %v", err)
+	}
+	if len(got) != len(txids) || rpc.batches != 2 {
+		t.Fatalf("len=%d batches=%d", len(got), rpc.batches)
+	}
+	if got[0].Confirmations != 10 || got[0].InMempool || got[0].BlockTime != 1700000000 {
+		t.Fatalf("mined=%+v", got[0])
+	}
+	if got[1] != (TxStatus{TxID: pending, InMempool: true}) {
+		t.Fatalf("pending=%+v", got[1])
+	}
+	if got[len(got)-1] != (TxStatus{TxID: unknown}) {
+		t.Fatalf("unknown=%+v", got[len(got)-1])
+	}
+
+	if _, err := c.StatusBulk(context.Background(), []string{mined, "zz"}); err == nil || !strings.Contains(err.Error(), "txid 2") {
+		t.Fatalf("expected invalid txid error, got %v", err)
+	}
+}
+
+func TestStatusBulk_WithoutBatching(t *testing.T) {
+	mined := strings.Repeat("a", 64)
+	var tipCalls int
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getblockcount":
+			tipCalls++
+			return setOut(out, int64(200))
+		case "getrawtransaction":
+			if params.([]any)[0] != mined {
+				return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+			}
+			return setOut(out, map[string]any{"blockhash": strings.Repeat("01", 32), "height": 200, "confirmations": 1})
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	got, err := c.StatusBulk(context.Background(), []string{mined, strings.Repeat("b", 64)})
+	if err != nil || tipCalls != 1 {
+		t.Fatalf("err=%v tipCalls=%d", err, tipCalls)
+	}
+	if got[0].Confirmations != 1 || got[1] != (TxStatus{TxID: strings.Repeat("b", 64)}) {
+		t.Fatalf("statuses=%+v", got)
+	}
+}
+
+func BenchmarkStatusBulk(b *testing.B) {
+	rpc := &fakeBatchRPC{tip: 1000, txs: map[string]map[string]any{}}
+	txids := make([]string, 5000)
+	for i := range txids {
+		txids[i] = fmt.Sprintf("%064x", i)
+		rpc.txs[txids[i]] = map[string]any{"blockhash": strings.Repeat("01", 32), "height": int64(i%1000 + 1), "confirmations": 1}
+	}
+	c, err := New(rpc)
+	if err != nil {
+		b.Fatalf("New: %v", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.StatusBulk(context.Background(), txids); err != nil {
+			b.Fatalf("StatusBulk: %v", err)
+		}
+	}
+}
+
+func TestWaitForAll_MinSuccess(t *testing.T) {
+	fast := strings.Repeat("a", 64)
+	slow := strings.Repeat("b", 64)
+	stuck := strings.Repeat("c", 64)
+	var tip int64 = 100
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getblockcount":
+			tip++
+			return setOut(out, tip)
+		case "getrawtransaction":
+			switch params.([]any)[0] {
+			case fast:
+				return setOut(out, map[string]any{"blockhash": strings.Repeat("01", 32), "height": 100, "confirmations": 1})
+			case slow:
+				return setOut(out, map[string]any{"blockhash": strings.Repeat("02", 32), "height": 103, "confirmations": 1})
+			}
+			return setOut(out, map[string]any{"confirmations": 0})
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, err := c.WaitForAll(context.Background(), []string{fast, slow, stuck}, 3, 2)
+	if err != nil {
+		t.Fatalf("WaitForAll: %v", err)
+	}
+	if !res[0].Met || !res[1].Met || res[2].Met || !res[2].InMempool || res[1].Confirmations != 3 {
+		t.Fatalf("results=%+v", res)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err = c.WaitForAll(ctx, []string{fast, stuck}, 3, 0)
+	if !errors.Is(err, ErrWaitTimeout) || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "1 of 2 txids") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if len(res) != 2 || !res[0].Met || res[1].Met || res[1].TxID != stuck {
+		t.Fatalf("results=%+v", res)
+	}
+}
+
+func TestWaitForAll_KeepsResultsWhenLookupFails(t *testing.T) {
+	done := strings.Repeat("a", 64)
+	pending := strings.Repeat("b", 64)
+	var polls int
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getblockcount":
+			polls++
+			if polls > 1 {
+				return &junocashd.RPCError{Code: -1, Message: "node shutting down"}
+			}
+			return setOut(out, int64(100))
+		case "getrawtransaction":
+			if params.([]any)[0] == done {
+				return setOut(out, map[string]any{"blockhash": strings.Repeat("01", 32), "height": 100, "confirmations": 1})
+			}
+			return setOut(out, map[string]any{"confirmations": 0})
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, err := c.WaitForAll(context.Background(), []string{done, pending}, 1, 0)
+	if err == nil || !strings.Contains(err.Error(), "node shutting down") {
+		t.Fatalf("err=%v", err)
+	}
+	if len(res) != 2 || !res[0].Met || res[0].TxID != done || res[1].Met || res[1].TxID != pending {
+		t.Fatalf("results=%+v", res)
+	}
+}
+
+func TestEstimateConfirmationTime(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	var tx map[string]any
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getblockcount":
+			return setOut(out, int64(100))
+		case "getblockhash":
+			return setOut(out, fmt.Sprintf("%064d", params.([]any)[0]))
+		case "getblockheader":
+			// Heights 90..100 are 75s apart.
+			var h int64
+			fmt.Sscanf(params.([]any)[0].(string), "%d", &h)
+			return setOut(out, map[string]any{"time": 1700000000 + h*75})
+		case "getrawtransaction":
+			return setOut(out, tx)
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc, WithETASampleBlocks(10))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if d, err := c.AverageBlockInterval(context.Background()); err != nil || d != 75*time.Second {
+		t.Fatalf("AverageBlockInterval=%s err=%v", d, err)
+	}
+
+	tx = map[string]any{"confirmations": 0}
+	if d, err := c.EstimateConfirmationTime(context.Background(), txid, 3); err != nil || d != 225*time.Second {
+		t.Fatalf("mempool eta=%s err=%v", d, err)
+	}
+	tx = map[string]any{"blockhash": strings.Repeat("01", 32), "confirmations": 2}
+	if d, err := c.EstimateConfirmationTime(context.Background(), txid, 3); err != nil || d != 75*time.Second {
+		t.Fatalf("mined eta=%s err=%v", d, err)
+	}
+	if d, err := c.EstimateConfirmationTime(context.Background(), txid, 2); err != nil || d != 0 {
+		t.Fatalf("reached eta=%s err=%v", d, err)
+	}
+}
+
+func TestStatusAt_SkipTxIDValidation(t *testing.T) {
+	var got []string
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		got = append(got, params.([]any)[0].(string))
+		return setOut(out, map[string]any{"confirmations": 0})
+	}}
+
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, _, err := c.StatusAt(context.Background(), "not-a-txid", 10); err == nil || len(got) != 0 {
+		t.Fatalf("expected validation error before any RPC, err=%v calls=%q", err, got)
+	}
+
+	c, err = New(rpc, WithSkipTxIDValidation(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, found, err := c.StatusAt(context.Background(), " AB ", 10); err != nil || !found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+	if len(got) != 1 || got[0] != " AB " {
+		t.Fatalf("expected txid passed through unchanged, got %q", got)
+	}
+}
+
+func BenchmarkStatusAt_TxIDValidation(b *testing.B) {
+	rpc := fakeRPC{call: func(_ context.Context, _ string, _ any, out any) error {
+		*out.(*verboseTx) = verboseTx{BlockHash: "00", Height: 90, Confirmations: 11}
+		return nil
+	}}
+	txid := strings.Repeat("ab", 32)
+	for _, skip := range []bool{false, true} {
+		b.Run(fmt.Sprintf("skip=%v", skip), func(b *testing.B) {
+			c, err := New(rpc, WithSkipTxIDValidation(skip))
+			if err != nil {
+				b.Fatalf("New: %v", err)
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := c.StatusAt(context.Background(), txid, 100); err != nil {
+					b.Fatalf("StatusAt: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitForConfirmationsAcross_FirstConfirmedWins(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	blockHash := strings.Repeat("01", 32)
+	behind := NamedRPC("http://behind:8232", fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method == "getrawtransaction" {
+			return setOut(out, map[string]any{"txid": txid})
+		}
+		return errors.New("unexpected method " + method)
+	}})
+	ahead := NamedRPC("http://ahead:8232", fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getrawtransaction":
+			return setOut(out, map[string]any{"txid": txid, "blockhash": blockHash, "confirmations": 2})
+		case "getblockheader":
+			return setOut(out, map[string]any{"hash": blockHash, "confirmations": 2})
+		}
+		return errors.New("unexpected method " + method)
+	}})
+	primary := fakeRPC{call: func(context.Context, string, any, any) error {
+		t.Fatalf("primary rpc should not be polled")
+		return nil
+	}}
+	c, err := New(primary, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	st, err := c.WaitForConfirmationsAcross(ctx, txid, 2, []RPC{behind, ahead})
+	if err != nil || st.Confirmations != 2 || st.BlockHash != blockHash {
+		t.Fatalf("st=%+v err=%v", st, err)
+	}
+}
+
+func TestWaitForConfirmationsAcross_TimeoutReportsFurthestEndpoint(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	mempool := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method == "getrawtransaction" {
+			return setOut(out, map[string]any{"txid": txid})
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	down := fakeRPC{call: func(context.Context, string, any, any) error {
+		return errors.New("connection refused")
+	}}
+	c, err := New(fakeRPC{}, WithPollInterval(time.Millisecond), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err = c.WaitForConfirmationsAcross(ctx, txid, 1, []RPC{down, mempool})
+	var timeoutErr *WaitTimeoutError
+	if !errors.As(err, &timeoutErr) || !timeoutErr.LastStatus.InMempool || timeoutErr.Target != 1 {
+		t.Fatalf("err=%v", err)
+	}
+
+	_, err = c.WaitForConfirmationsAcross(context.Background(), txid, 1, []RPC{down, NamedRPC("http://b:8232", down)})
+	var ee *EndpointError
+	if err == nil || !errors.As(err, &ee) || !strings.Contains(err.Error(), "http://b:8232: ") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestSubmit_ImmatureCoinbase(t *testing.T) {
+	coinbase := strings.Repeat("cb", 32)
+	other := strings.Repeat("0a", 32)
+	nodeErr := &junocashd.RPCError{Code: -26, Message: "16: bad-txns-premature-spend-of-coinbase"}
+	lookups := true
+	rpc := fakeRPC{
+		sendRawTransaction: func(context.Context, string) (string, error) { return "", nodeErr },
+		call: func(_ context.Context, method string, params any, out any) error {
+			if !lookups {
+				return errors.New("no txindex")
+			}
+			switch method {
+			case "decoderawtransaction":
+				return setOut(out, map[string]any{"vin": []any{
+					map[string]any{"txid": other, "vout": 0},
+					map[string]any{"txid": strings.ToUpper(coinbase), "vout": 1},
+				}})
+			case "getrawtransaction":
+				if params.([]any)[0] == other {
+					return setOut(out, map[string]any{"confirmations": 5, "vin": []any{map[string]any{"txid": coinbase, "vout": 0}}})
+				}
+				return setOut(out, map[string]any{"confirmations": 37, "vin": []any{map[string]any{"coinbase": "03a08601"}}})
+			}
+			return errors.New("unexpected method " + method)
+		},
+	}
+	c, err := New(rpc, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = c.Submit(context.Background(), "00")
+	var ce *ImmatureCoinbaseError
+	if !errors.Is(err, ErrImmatureCoinbase) || !errors.Is(err, nodeErr) || !errors.As(err, &ce) {
+		t.Fatalf("err=%v", err)
+	}
+	if ce.Input != coinbase+":1" || ce.Confirmations != 37 || ce.BlocksRemaining() != 63 {
+		t.Fatalf("err=%+v remaining=%d", ce, ce.BlocksRemaining())
+	}
+
+	lookups = false
+	_, err = c.Submit(context.Background(), "00")
+	if !errors.As(err, &ce) || ce.Input != "" || ce.BlocksRemaining() != -1 {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestTxStatus_State(t *testing.T) {
+	for _, tc := range []struct {
+		st   TxStatus
+		want TxState
+	}{
+		{TxStatus{}, TxStateUnknown},
+		{TxStatus{TxID: "a"}, TxStateUnknown},
+		{TxStatus{InMempool: true}, TxStateMempool},
+		{TxStatus{Confirmations: 3, BlockHash: "01"}, TxStateConfirmed},
+		{TxStatus{Confirmations: 1}, TxStateConfirmed},
+	} {
+		if got := tc.st.State(); got != tc.want {
+			t.Fatalf("%+v: state=%s want %s", tc.st, got, tc.want)
+		}
+	}
+}
+
+func TestWaitForAllTargets_PerTxIDThresholds(t *testing.T) {
+	small := strings.Repeat("a", 64)
+	large := strings.Repeat("b", 64)
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getblockcount":
+			return setOut(out, int64(102))
+		case "getrawtransaction":
+			// Both txs have 3 confirmations against tip 102.
+			return setOut(out, map[string]any{"blockhash": strings.Repeat("01", 32), "height": 100, "confirmations": 3})
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err := c.WaitForAllTargets(ctx, map[string]int64{large: 6, small: 1}, 0)
+	if !errors.Is(err, ErrWaitTimeout) || !strings.Contains(err.Error(), "1 of 2 txids reached their confirmation targets") {
+		t.Fatalf("err=%v", err)
+	}
+	if len(res) != 2 || res[0].TxID != small || !res[0].Met || res[0].RequiredConfs != 1 || res[1].Met || res[1].RequiredConfs != 6 {
+		t.Fatalf("results=%+v", res)
+	}
+
+	res, err = c.WaitForAllTargets(context.Background(), map[string]int64{large: 6, small: 1}, 1)
+	if err != nil || !res[0].Met {
+		t.Fatalf("min-success 1: res=%+v err=%v", res, err)
+	}
+}
+
+func TestTxindexEnabled(t *testing.T) {
+	coinbase := strings.Repeat("cb", 32)
+	var rawErr error
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		switch method {
+		case "getblockcount":
+			return setOut(out, int64(10))
+		case "getblockhash":
+			return setOut(out, strings.Repeat("01", 32))
+		case "getblock":
+			return setOut(out, map[string]any{"tx": []string{coinbase}})
+		case "getrawtransaction":
+			if params.([]any)[0] != coinbase {
+				t.Fatalf("probed %v", params)
+			}
+			if rawErr != nil {
+				return rawErr
+			}
+			return setOut(out, "00")
+		}
+		return errors.New("unexpected method " + method)
+	}}
+	c, err := New(rpc, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if ok, err := c.TxindexEnabled(context.Background()); err != nil || !ok {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	rawErr = &junocashd.RPCError{Code: -5, Message: "No such mempool transaction. Use -txindex to enable blockchain transaction queries."}
+	if ok, err := c.TxindexEnabled(context.Background()); err != nil || ok {
+		t.Fatalf("without txindex: ok=%v err=%v", ok, err)
+	}
+	rawErr = errors.New("connection reset")
+	if _, err := c.TxindexEnabled(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestSubmitWithFeeBump(t *testing.T) {
+	origTxID := strings.Repeat("0a", 32)
+	bumpedTxID := strings.Repeat("0b", 32)
+	lowFee := &junocashd.RPCError{Code: -26, Message: "66: min relay fee not met"}
+	var sent []string
+	walletOwns := true
+	rpc := fakeRPC{
+		sendRawTransaction: func(_ context.Context, txHex string) (string, error) {
+			sent = append(sent, txHex)
+			if txHex == "00" {
+				return "", lowFee
+			}
+			return "", &junocashd.RPCError{Code: -27, Message: "transaction already in block chain"}
+		},
+		call: func(_ context.Context, method string, params any, out any) error {
+			switch method {
+			case "decoderawtransaction":
+				return setOut(out, map[string]any{"txid": origTxID})
+			case "bumpfee":
+				if !walletOwns {
+					return &junocashd.RPCError{Code: -8, Message: "Invalid or non-wallet transaction id"}
+				}
+				if params.([]any)[0] != origTxID {
+					t.Fatalf("bumpfee params=%v", params)
+				}
+				return setOut(out, map[string]any{"txid": bumpedTxID, "origfee": 0.00001, "fee": 0.00002})
+			case "gettransaction":
+				return setOut(out, map[string]any{"hex": "01"})
+			}
+			return errors.New("unexpected method " + method)
+		},
+	}
+	c, err := New(rpc, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00"); !errors.Is(err, ErrFeeTooLow) || !errors.Is(err, lowFee) {
+		t.Fatalf("Submit err=%v", err)
+	}
+
+	txid, bumps, err := c.SubmitWithFeeBump(context.Background(), "00", 3)
+	if err != nil {
+		t.Fatalf("SubmitWithFeeBump: %v", err)
+	}
+	if txid != bumpedTxID || len(bumps) != 1 || bumps[0] != (FeeBump{OrigTxID: origTxID, TxID: bumpedTxID, OrigFee: 0.00001, Fee: 0.00002}) {
+		t.Fatalf("txid=%s bumps=%+v", txid, bumps)
+	}
+	if strings.Join(sent, ",") != "00,00,01" {
+		t.Fatalf("sent=%v", sent)
+	}
+
+	walletOwns = false
+	_, bumps, err = c.SubmitWithFeeBump(context.Background(), "00", 3)
+	if !errors.Is(err, ErrFeeTooLow) || !errors.Is(err, ErrNotWalletTx) || len(bumps) != 0 {
+		t.Fatalf("err=%v bumps=%+v", err, bumps)
+	}
+
+	_, bumps, err = c.SubmitWithFeeBump(context.Background(), "00", 0)
+	if !errors.Is(err, ErrFeeTooLow) || bumps != nil {
+		t.Fatalf("maxBumps=0: err=%v bumps=%+v", err, bumps)
+	}
+}
+
+func TestSubmit_SendRawParams(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var got []byte
+	rpc := fakeRPC{
+		sendRawTransaction: func(context.Context, string) (string, error) {
+			t.Fatalf("expected sendrawtransaction through Call")
+			return "", nil
+		},
+		call: func(_ context.Context, method string, params any, out any) error {
+			if method != "sendrawtransaction" {
+				return errors.New("unexpected method " + method)
+			}
+			got, _ = json.Marshal(params)
+			return setOut(out, txid)
+		},
+	}
+	c, err := New(rpc, WithSendRawParams([]json.RawMessage{json.RawMessage(`0`), json.RawMessage(`{"a":1}`)}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if id, err := c.Submit(context.Background(), "00"); err != nil || id != txid {
+		t.Fatalf("id=%s err=%v", id, err)
+	}
+	if string(got) != `["00",0,{"a":1}]` {
+		t.Fatalf("params=%s", got)
+	}
+}
+
+func TestSubmit_RespectLocktime(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var locktime, sequence uint32
+	sent := 0
+	rpc := fakeRPC{
+		sendRawTransaction: func(context.Context, string) (string, error) {
+			sent++
+			return txid, nil
+		},
+		call: func(_ context.Context, method string, _ any, out any) error {
+			switch method {
+			case "decoderawtransaction":
+				return setOut(out, map[string]any{"locktime": locktime, "vin": []any{map[string]any{"sequence": sequence}}})
+			case "getblockchaininfo":
+				return setOut(out, map[string]any{"blocks": 1000, "mediantime": 1_700_000_000})
+			}
+			return errors.New("unexpected method " + method)
+		},
+	}
+	c, err := New(rpc, WithRespectLocktime(true), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, tc := range []struct {
+		locktime, sequence uint32
+		ok                 bool
+	}{
+		{0, 0, true},
+		{1000, 0, true},
+		{1001, 0, false},
+		{5000, 0xffffffff, true},
+		{1_699_999_999, 0, true},
+		{1_700_000_000, 0, false},
+	} {
+		locktime, sequence = tc.locktime, tc.sequence
+		sent = 0
+		_, err := c.Submit(context.Background(), "00")
+		if tc.ok && (err != nil || sent != 1) {
+			t.Fatalf("locktime=%d sequence=%x: err=%v sent=%d", tc.locktime, tc.sequence, err, sent)
+		}
+		if !tc.ok && (!errors.Is(err, ErrLocktimeNotMet) || sent != 0) {
+			t.Fatalf("locktime=%d sequence=%x: err=%v sent=%d", tc.locktime, tc.sequence, err, sent)
+		}
+	}
+}
+
+func TestWithAttemptLog_TagsAttemptsAndPolls(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	calls := 0
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method != "getrawtransaction" {
+			return errors.New("unexpected method " + method)
+		}
+		calls++
+		switch calls {
+		case 1:
+			return errors.New("connection refused")
+		case 2:
+			return setOut(out, map[string]any{"confirmations": 0})
+		}
+		return setOut(out, map[string]any{"confirmations": 1, "blockhash": strings.Repeat("01", 32)})
+	}}
+	var got []string
+	c, err := New(rpc,
+		WithAttemptLog(func(e AttemptEvent) {
+			got = append(got, fmt.Sprintf("%s poll=%d attempt=%d err=%v", e.Method, e.Poll, e.Attempt, e.Err != nil))
+		}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		WithImmediatePoll(true),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.WaitForConfirmations(context.Background(), txid, 1); err != nil {
+		t.Fatalf("WaitForConfirmations: %v", err)
+	}
+	want := []string{
+		"getrawtransaction poll=1 attempt=1 err=true",
+		"getrawtransaction poll=1 attempt=2 err=false",
+		"getrawtransaction poll=2 attempt=1 err=false",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("events:\n%s", strings.Join(got, "\n"))
+	}
+
+	got = nil
+	if _, _, err := c.Status(context.Background(), txid); err != nil || len(got) != 1 || got[0] != "getrawtransaction poll=0 attempt=1 err=false" {
+		t.Fatalf("err=%v events=%v", err, got)
+	}
+}
+
+func TestMempoolEntry(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	parent := strings.Repeat("cd", 32)
+	var entry any
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method != "getmempoolentry" {
+			return errors.New("unexpected method " + method)
+		}
+		if entry == nil {
+			return &junocashd.RPCError{Code: -5, Message: "Transaction not in mempool"}
+		}
+		return setOut(out, entry)
+	}}
+	c, err := New(rpc, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// Descendant-only node: fees in coins, descendantfees in zatoshis.
+	entry = map[string]any{
+		"size": 250, "fee": 0.0001, "modifiedfee": 0.0002, "time": 1700000000, "height": 100,
+		"descendantcount": 2, "descendantsize": 500, "descendantfees": 30000,
+		"depends": []any{strings.ToUpper(parent)},
+	}
+	e, err := c.MempoolEntry(context.Background(), txid)
+	if err != nil {
+		t.Fatalf("MempoolEntry: %v", err)
+	}
+	if e.TxID != txid || e.Size != 250 || e.Fee != 0.0001 || e.ModifiedFee != 0.0002 || e.DescendantCount != 2 || e.DescendantSize != 500 || e.DescendantFees != 0.0003 ||
+		e.AncestorCount != nil || e.AncestorFees != nil || len(e.Depends) != 1 || e.Depends[0] != parent {
+		t.Fatalf("entry=%+v", e)
+	}
+
+	// Package-aware node: the fees object wins.
+	entry = map[string]any{
+		"vsize": 200, "size": 250, "fee": 0.0001, "time": 1700000000, "height": 100,
+		"ancestorcount": 2, "ancestorsize": 400, "ancestorfees": 20000,
+		"descendantcount": 1, "descendantsize": 200, "descendantfees": 10000,
+		"fees": map[string]any{"base": 0.0001, "modified": 0.0001, "ancestor": 0.0002, "descendant": 0.0001},
+	}
+	e, err = c.MempoolEntry(context.Background(), txid)
+	if err != nil {
+		t.Fatalf("MempoolEntry: %v", err)
+	}
+	if e.Size != 200 || e.AncestorCount == nil || *e.AncestorCount != 2 || *e.AncestorSize != 400 || *e.AncestorFees != 0.0002 || e.DescendantFees != 0.0001 || e.Depends == nil {
+		t.Fatalf("entry=%+v", e)
+	}
+
+	entry = nil
+	if _, err := c.MempoolEntry(context.Background(), txid); !errors.Is(err, ErrNotInMempool) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestWaitForOperation(t *testing.T) {
+	opid := "opid-1234"
+	txid := strings.Repeat("ab", 32)
+	polls := 0
+	var methods []string
+	fail := false
+	rpc := fakeRPC{call: func(_ context.Context, method string, params any, out any) error {
+		methods = append(methods, method)
+		if ids := params.([]any)[0].([]string); len(ids) != 1 || ids[0] != opid {
+			return setOut(out, []any{})
+		}
+		switch method {
+		case "z_getoperationstatus":
+			polls++
+			if polls < 3 {
+				return setOut(out, []any{map[string]any{"id": opid, "status": "executing"}})
+			}
+		case "z_getoperationresult":
+		default:
+			return errors.New("unexpected method " + method)
+		}
+		if fail {
+			return setOut(out, []any{map[string]any{"id": opid, "status": "failed", "error": map[string]any{"code": -6, "message": "Insufficient funds"}}})
+		}
+		return setOut(out, []any{map[string]any{"id": opid, "status": "success", "execution_secs": 1.5, "result": map[string]any{"txid": strings.ToUpper(txid)}}})
+	}}
+	c, err := New(rpc, WithImmediatePoll(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, err := c.WaitForOperation(context.Background(), opid)
+	if err != nil || res.Status != "success" || res.TxID != txid || res.ExecutionSecs != 1.5 {
+		t.Fatalf("res=%+v err=%v", res, err)
+	}
+	if strings.Join(methods, ",") != "z_getoperationstatus,z_getoperationstatus,z_getoperationstatus,z_getoperationresult" {
+		t.Fatalf("methods=%v", methods)
+	}
+
+	fail = true
+	res, err = c.WaitForOperation(context.Background(), opid)
+	if !errors.Is(err, ErrOperationFailed) || res.Error != "Insufficient funds" {
+		t.Fatalf("res=%+v err=%v", res, err)
+	}
+
+	if _, err := c.Operation(context.Background(), "other"); !errors.Is(err, ErrOperationNotFound) {
+		t.Fatalf("err=%v", err)
+	}
+
+	fail, polls, methods = false, 0, nil
+	c, err = New(rpc, WithImmediatePoll(true), WithReadOnly(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if res, err := c.WaitForOperation(context.Background(), opid); err != nil || res.TxID != txid {
+		t.Fatalf("read-only res=%+v err=%v", res, err)
+	}
+	if strings.Join(methods, ",") != "z_getoperationstatus,z_getoperationstatus,z_getoperationstatus" {
+		t.Fatalf("read-only methods=%v", methods)
+	}
+	if _, err := c.operation(context.Background(), "z_getoperationresult", opid); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("z_getoperationresult err=%v", err)
+	}
+}
+
+func TestInspectOutputs(t *testing.T) {
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		if method != "decoderawtransaction" {
+			return errors.New("unexpected method " + method)
+		}
+		return setOut(out, map[string]any{
+			"vout": []any{
+				map[string]any{"n": 0, "value": 1.5, "valueZat": 150000000, "scriptPubKey": map[string]any{"type": "pubkeyhash", "addresses": []string{"t1pay"}}},
+				map[string]any{"n": 1, "value": 0, "scriptPubKey": map[string]any{"type": "nulldata"}},
+			},
+			"vShieldedOutput": []any{map[string]any{"cmu": "00"}},
+			"orchard":         map[string]any{"actions": []any{map[string]any{}, map[string]any{}}},
+		})
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	outs, err := c.InspectOutputs(context.Background(), "00")
+	if err != nil {
+		t.Fatalf("InspectOutputs: %v", err)
+	}
+	var got []string
+	for _, o := range outs {
+		got = append(got, fmt.Sprintf("%s/%d/%s/%s/%v", o.Pool, o.Index, strings.Join(o.Addresses, ","), o.Value, o.Opaque))
+	}
+	if want := "transparent/0/t1pay/1.5/false transparent/1//0/false sapling/0///true orchard/0///true orchard/1///true"; strings.Join(got, " ") != want {
+		t.Fatalf("outputs=%q", got)
+	}
+	if outs[0].ValueZat == nil || *outs[0].ValueZat != 150000000 {
+		t.Fatalf("value_zat=%v", outs[0].ValueZat)
+	}
+	if len(transparentOutputs(outs)) != 2 {
+		t.Fatalf("transparent=%d", len(transparentOutputs(outs)))
+	}
+}
+
+func TestWithExpectedOutputs_ShieldedHint(t *testing.T) {
+	rpc := fakeRPC{call: func(_ context.Context, _ string, _ any, out any) error {
+		return setOut(out, map[string]any{"vout": []any{}, "orchard": map[string]any{"actions": []any{map[string]any{}}}})
+	}}
+	c, err := New(rpc, WithExpectedOutputs([]ExpectedOutput{{Address: "j1shielded", Amount: 100000000}}, false))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = c.Submit(context.Background(), "00")
+	if !errors.Is(err, ErrOutputMismatch) || !strings.Contains(err.Error(), "1 shielded output(s) cannot be checked") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestWaitForConfirmations_TipGatedPolling(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	for _, gated := range []bool{false, true} {
+		tips := []string{"aa", "aa", "aa", "bb"}
+		var polls, lookups int
+		rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+			switch method {
+			case "getbestblockhash":
+				tip := tips[min(polls, len(tips)-1)]
+				polls++
+				return setOut(out, tip)
+			case "getrawtransaction":
+				lookups++
+				if lookups < 4 && (!gated || polls < 4) {
+					return setOut(out, map[string]any{"txid": txid, "confirmations": 0})
+				}
+				return setOut(out, map[string]any{"txid": txid, "confirmations": 1, "blockhash": "bb"})
+			default:
+				return errors.New("unexpected method " + method)
+			}
+		}}
+		c, err := New(rpc, WithImmediatePoll(true), WithMaxPolls(10), WithTipGatedPolling(gated))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		st, err := c.WaitForConfirmations(context.Background(), txid, 1)
+		if err != nil || st.Confirmations != 1 {
+			t.Fatalf("gated=%v: st=%+v err=%v", gated, st, err)
+		}
+		if want := map[bool]int{false: 4, true: 2}[gated]; lookups != want {
+			t.Fatalf("gated=%v: %d getrawtransaction lookups, want %d", gated, lookups, want)
+		}
+		if gated && polls != 4 {
+			t.Fatalf("getbestblockhash polls=%d, want 4", polls)
+		}
+	}
+}
+
+func TestNodeWarnings(t *testing.T) {
+	networkInfo := true
+	rpc := fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		switch method {
+		case "getconnectioncount":
+			return setOut(out, 8)
+		case "getblockchaininfo":
+			return setOut(out, map[string]any{"blocks": 100, "headers": 100, "warnings": "Warning: unknown new rules activated (versionbit 28)"})
+		case "getnetworkinfo":
+			if !networkInfo {
+				return &junocashd.RPCError{Code: -32601, Message: "Method not found"}
+			}
+			return setOut(out, map[string]any{"warnings": []string{"Warning: unknown new rules activated (versionbit 28)", " ", "This is a pre-release test build"}})
+		default:
+			return errors.New("unexpected method " + method)
+		}
+	}}
+	c, err := New(rpc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ws, err := c.NodeWarnings(context.Background())
+	if err != nil || strings.Join(ws, "|") != "Warning: unknown new rules activated (versionbit 28)|This is a pre-release test build" {
+		t.Fatalf("warnings=%q err=%v", ws, err)
+	}
+	h, err := c.NodeHealth(context.Background())
+	if err != nil || len(h.Warnings) != 2 || h.Warnings[1] != "node reports: This is a pre-release test build" {
+		t.Fatalf("h=%+v err=%v", h, err)
+	}
+
+	networkInfo = false
+	ws, err = c.NodeWarnings(context.Background())
+	if err != nil || len(ws) != 1 {
+		t.Fatalf("without getnetworkinfo: warnings=%q err=%v", ws, err)
+	}
+}
+
+func TestWithRPCTransportLimit_RequestShape(t *testing.T) {
+	var got []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		got = append(got, req)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": req["jsonrpc"], "id": req["id"], "result": 42})
+	}))
+	defer srv.Close()
+
+	call := func(opts ...TransportOption) {
+		t.Helper()
+		rpc := junocashd.New(srv.URL, "u", "p", WithRPCTransportLimit("", "", 0, opts...))
+		var n int64
+		if err := rpc.Call(context.Background(), "getblockcount", nil, &n); err != nil || n != 42 {
+			t.Fatalf("Call: n=%d err=%v", n, err)
+		}
+	}
+
+	call()
+	call(WithJSONRPCVersion("2.0"), WithIDGenerator(func(seq uint64) any { return fmt.Sprintf("juno-%d", seq) }))
+	call(WithJSONRPCVersion("2.0"))
+	if len(got) != 3 {
+		t.Fatalf("requests=%d", len(got))
+	}
+	if got[0]["jsonrpc"] != "1.0" || got[0]["id"] != float64(1) {
+		t.Fatalf("default request=%v", got[0])
+	}
+	if got[1]["jsonrpc"] != "2.0" || got[1]["id"] != "juno-1" || got[1]["method"] != "getblockcount" {
+		t.Fatalf("shaped request=%v", got[1])
+	}
+	if got[2]["jsonrpc"] != "2.0" || got[2]["id"] != float64(1) {
+		t.Fatalf("version-only request=%v", got[2])
+	}
+}
+
+func TestWaitForPropagation(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	notFound := &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+	var mu sync.Mutex
+	lookups := map[string]int{}
+	node := func(name string, seenFrom int) RPC {
+		return NamedRPC(name, fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+			mu.Lock()
+			lookups[name+" "+method]++
+			n := lookups[name+" getrawtransaction"]
+			mu.Unlock()
+			if method == "getrawtransaction" && seenFrom > 0 && n >= seenFrom {
+				return setOut(out, map[string]any{"txid": txid, "confirmations": 0})
+			}
+			return notFound
+		}})
+	}
+	rpcs := []RPC{node("a", 1), node("b", 3), node("never", 0)}
+
+	c, err := New(fakeRPC{}, WithImmediatePoll(true), WithMaxPolls(5), WithChainLookback(0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.WaitForPropagation(context.Background(), txid, rpcs, 2); err != nil {
+		t.Fatalf("WaitForPropagation: %v", err)
+	}
+	// A node that has seen the tx is not asked again.
+	if lookups["a getrawtransaction"] != 1 || lookups["b getrawtransaction"] != 3 {
+		t.Fatalf("lookups=%v", lookups)
+	}
+
+	err = c.WaitForPropagation(context.Background(), txid, rpcs, 3)
+	if !errors.Is(err, ErrMaxPollsExceeded) || !strings.Contains(err.Error(), "seen by 2 of 3") {
+		t.Fatalf("expected ErrMaxPollsExceeded after 2 of 3 nodes, got %v", err)
+	}
+	if err := c.WaitForPropagation(context.Background(), txid, rpcs, 4); err == nil {
+		t.Fatal("expected an error for k above the node count")
+	}
+}
+
+func TestStatusRaw_ReturnsVerboseResponse(t *testing.T) {
+	txid := strings.Repeat("d", 64)
+	txindex := true
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			switch method {
+			case "getrawtransaction":
+				if !txindex {
+					return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+				}
+				return setOut(out, map[string]any{"txid": txid, "confirmations": 3, "blockhash": strings.Repeat("e", 64), "size": 250})
+			case "getmempoolentry":
+				return nil
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	st, found, raw, err := c.StatusRaw(context.Background(), strings.ToUpper(txid))
+	if err != nil || !found || st.Confirmations != 3 || st.TxID != txid {
+		t.Fatalf("StatusRaw: st=%+v found=%v err=%v", st, found, err)
+	}
+	if !strings.Contains(string(raw), `"size":250`) {
+		t.Fatalf("raw=%s", raw)
+	}
+
+	// Found through the getmempoolentry fallback: no verbose response.
+	txindex = false
+	st, found, raw, err = c.StatusRaw(context.Background(), txid)
+	if err != nil || !found || !st.InMempool || raw != nil {
+		t.Fatalf("StatusRaw: st=%+v found=%v raw=%s err=%v", st, found, raw, err)
+	}
+}
+
+// fakeRetryClock is a manual clock for doWithRetry: waits advance it
+// instantly and are recorded.
+type fakeRetryClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (f *fakeRetryClock) policy(p RetryPolicy) RetryPolicy {
+	p.now = func() time.Time { return f.now }
+	p.after = func(d time.Duration) <-chan time.Time {
+		f.waits = append(f.waits, d)
+		f.now = f.now.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- f.now
+		return ch
+	}
+	return p
+}
+
+func TestDoWithRetry_BudgetStopsBeforeAttempts(t *testing.T) {
+	clock := &fakeRetryClock{now: time.Unix(1_700_000_000, 0)}
+	p := clock.policy(RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Budget: 1 * time.Second})
+
+	var calls int
+	err := doWithRetry(context.Background(), p, func(error) bool { return true }, func(context.Context) error {
+		calls++
+		clock.now = clock.now.Add(50 * time.Millisecond) // each attempt takes 50ms
+		return errors.New("busy")
+	})
+	if err == nil || err.Error() != "busy" {
+		t.Fatalf("err=%v", err)
+	}
+	// 50 +100 +50 +200 +50 +400 +50 = 900ms; the next 800ms wait would end past 1s.
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	if calls != 4 || fmt.Sprint(clock.waits) != fmt.Sprint(want) {
+		t.Fatalf("calls=%d waits=%v", calls, clock.waits)
+	}
+	if got := len(AttemptErrors(err)); got != 4 {
+		t.Fatalf("attempt errors=%d", got)
+	}
+}
+
+func TestDoWithRetry_FullJitter(t *testing.T) {
+	clock := &fakeRetryClock{now: time.Unix(1_700_000_000, 0)}
+	p := clock.policy(RetryPolicy{MaxAttempts: 6, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: true})
+	rng := rand.New(rand.NewPCG(1, 2))
+	p.randN = rng.Int64N
+
+	err := doWithRetry(context.Background(), p, func(error) bool { return true }, func(context.Context) error {
+		return errors.New("busy")
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if len(clock.waits) != 5 {
+		t.Fatalf("waits=%v", clock.waits)
+	}
+	for i, w := range clock.waits {
+		if ceil := backoff(p.BaseDelay, p.MaxDelay, i+1); w < 0 || w > ceil {
+			t.Fatalf("wait %d = %v, want within [0, %v]", i, w, ceil)
+		}
+	}
+
+	// The same seed gives the same waits.
+	first := clock.waits
+	clock.waits = nil
+	rng = rand.New(rand.NewPCG(1, 2))
+	p.randN = rng.Int64N
+	_ = doWithRetry(context.Background(), p, func(error) bool { return true }, func(context.Context) error {
+		return errors.New("busy")
+	})
+	if fmt.Sprint(clock.waits) != fmt.Sprint(first) {
+		t.Fatalf("waits=%v, want %v", clock.waits, first)
+	}
+}
+
+func TestDoWithRetry_FailsFastPastDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second}
+
+	var calls int
+	started := time.Now()
+	busy := errors.New("busy")
+	err := doWithRetry(ctx, p, func(error) bool { return true }, func(context.Context) error {
+		calls++
+		return busy
+	})
+	if !errors.Is(err, ErrRetryBudgetExhausted) || !errors.Is(err, busy) || errors.Is(err, context.DeadlineExceeded) || calls != 1 {
+		t.Fatalf("calls=%d err=%v", calls, err)
+	}
+	if elapsed := time.Since(started); elapsed > 100*time.Millisecond {
+		t.Fatalf("waited %v for a retry the deadline rules out", elapsed)
+	}
+}
+
+func TestInProcessDedup_SkipsRepeatSubmits(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	var mu sync.Mutex
+	var sends int
+	c, err := New(fakeRPC{
+		sendRawTransaction: func(ctx context.Context, rawTxHex string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			sends++
+			return txid, nil
+		},
+	}, WithInProcessDedup(time.Minute))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := c.Submit(context.Background(), "00aa"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.Submit(context.Background(), " 00AA\n")
+			if err != nil || got != txid {
+				t.Errorf("Submit: %s, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if sends != 1 {
+		t.Fatalf("sends=%d, want 1", sends)
+	}
+
+	if _, err := c.Submit(context.Background(), "00bb"); err != nil || sends != 2 {
+		t.Fatalf("sends=%d err=%v", sends, err)
+	}
+}
+
+func TestSubmitDedup_ExpiresAndEvicts(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	d := newSubmitDedup(time.Minute, func() time.Time { return now })
+
+	d.put("00aa", "t1")
+	if got, ok := d.get("00aa"); !ok || got != "t1" {
+		t.Fatalf("get=%q %v", got, ok)
+	}
+	now = now.Add(time.Minute)
+	if _, ok := d.get("00aa"); ok {
+		t.Fatalf("expected expiry after ttl")
+	}
+
+	for i := 0; i <= dedupMaxEntries; i++ {
+		d.put(fmt.Sprintf("%04x", i), "t")
+	}
+	if _, ok := d.get("0000"); ok {
+		t.Fatalf("expected the oldest entry to be evicted")
+	}
+	if _, ok := d.get(fmt.Sprintf("%04x", dedupMaxEntries)); !ok {
+		t.Fatalf("expected the newest entry to be kept")
+	}
+}
+
+func TestBlockStatus_ReportsActiveChain(t *testing.T) {
+	active, orphaned := strings.Repeat("1", 64), strings.Repeat("2", 64)
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			if method != "getblockheader" {
+				return errors.New("unexpected method: " + method)
+			}
+			switch params.([]any)[0] {
+			case active:
+				return setOut(out, map[string]any{"hash": active, "height": 100, "confirmations": 3, "chainwork": "00ff"})
+			case orphaned:
+				return setOut(out, map[string]any{"hash": orphaned, "height": 99, "confirmations": -1})
+			default:
+				return &junocashd.RPCError{Code: -5, Message: "Block not found"}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	st, found, err := c.BlockStatus(context.Background(), strings.ToUpper(active))
+	if err != nil || !found || !st.ActiveChain || st.Height != 100 || st.Confirmations != 3 || st.ChainWork != "00ff" || st.BlockHash != active {
+		t.Fatalf("active: %+v found=%v err=%v", st, found, err)
+	}
+	st, found, err = c.BlockStatus(context.Background(), orphaned)
+	if err != nil || !found || st.ActiveChain || st.Confirmations != -1 {
+		t.Fatalf("orphaned: %+v found=%v err=%v", st, found, err)
+	}
+	if _, found, err = c.BlockStatus(context.Background(), strings.Repeat("3", 64)); err != nil || found {
+		t.Fatalf("unknown: found=%v err=%v", found, err)
+	}
+	if _, _, err = c.BlockStatus(context.Background(), "abc"); err == nil {
+		t.Fatalf("expected invalid hash error")
+	}
+}
+
+func TestWaitForConfirmations_SettleRestartsWhenConfirmationsDrop(t *testing.T) {
+	txid := strings.Repeat("e", 64)
+	blockA, blockB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+
+	// Poll by poll: reaches 2 confs in block A, A is reorged away during
+	// the settle window, then the tx confirms again in block B and holds.
+	type view struct {
+		block string
+		confs int64
+	}
+	script := []view{{"", 0}, {blockA, 2}, {blockA, 2}, {"", 0}, {blockB, 2}, {blockB, 3}, {blockB, 3}}
+	var poll int
+	cur := func() view {
+		if poll >= len(script) {
+			return script[len(script)-1]
+		}
+		return script[poll]
+	}
+
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			defer func() { poll++ }()
+			v := cur()
+			switch method {
+			case "getrawtransaction":
+				return setOut(out, map[string]any{"txid": txid, "blockhash": v.block, "confirmations": v.confs})
+			case "getblockheader":
+				if params.([]any)[0] != v.block {
+					return setOut(out, map[string]any{"confirmations": -1})
+				}
+				return setOut(out, map[string]any{"confirmations": v.confs})
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	}, WithImmediatePoll(true), WithSettlePolls(2))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	st, err := c.WaitForConfirmations(context.Background(), txid, 2)
+	if err != nil {
+		t.Fatalf("WaitForConfirmations: %v", err)
+	}
+	if st.BlockHash != blockB || st.Confirmations != 3 || poll != 7 {
+		t.Fatalf("st=%+v polls=%d", st, poll)
+	}
+}
+
+func TestTxFee_SumsPrevoutsAndValueBalance(t *testing.T) {
+	txid := strings.Repeat("1", 64)
+	prevA := strings.Repeat("a", 64)
+	prevB := strings.Repeat("b", 64)
+
+	c, err := New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			p := params.([]any)
+			switch {
+			case method == "getrawtransaction" && p[0] == txid:
+				return setOut(out, map[string]any{
+					"vin": []map[string]any{{"txid": prevA, "vout": 1}, {"txid": prevB, "vout": 0}},
+					"vout": []map[string]any{
+						{"n": 0, "value": 1.5, "valueZat": 150_000_000},
+						{"n": 1, "value": 0.2},
+					},
+					"valueBalance":    -0.1,
+					"vShieldedOutput": []map[string]any{{}},
+				})
+			case method == "getrawtransaction" && p[0] == prevA:
+				return setOut(out, map[string]any{"vout": []map[string]any{{"n": 0, "value": 9}, {"n": 1, "value": 1, "valueZat": 100_000_000}}})
+			case method == "getrawtransaction":
+				return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+			case method == "gettxout" && p[0] == prevB && p[2] == false:
+				return setOut(out, map[string]any{"value": 0.8001})
+			default:
+				return errors.New("unexpected method: " + method)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	fee, found, err := c.TxFee(context.Background(), txid)
+	if err != nil || !found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+	// 1.8001 in - 1.7 out - 0.1 into the shielded pool.
+	if fee.TransparentIn != 180_010_000 || fee.TransparentOut != 170_000_000 || fee.ShieldedValueBalance != -10_000_000 ||
+		fee.Fee != 10_000 || !fee.Shielded || !strings.Contains(fee.Note, "-0.10000000") {
+		t.Fatalf("fee=%+v", fee)
+	}
+	if got, err := c.ComputeFee(context.Background(), txid); err != nil || got != 10_000 {
+		t.Fatalf("ComputeFee=%d err=%v", got, err)
+	}
+
+	// An unknown tx is not found; an unresolvable prevout needs -txindex.
+	if _, found, err := c.TxFee(context.Background(), strings.Repeat("2", 64)); err != nil || found {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+	c, _ = New(fakeRPC{
+		call: func(ctx context.Context, method string, params any, out any) error {
+			if method == "gettxout" {
+				return setOut(out, nil)
+			}
+			if params.([]any)[0] == txid {
+				return setOut(out, map[string]any{"vin": []map[string]any{{"txid": prevA, "vout": 0}}})
+			}
+			return &junocashd.RPCError{Code: -5, Message: "No such mempool or blockchain transaction"}
+		},
+	})
+	if _, _, err := c.TxFee(context.Background(), txid); !errors.Is(err, ErrTxindexRequired) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestSubmit_EmptyTxID(t *testing.T) {
+	// Bitcoin genesis coinbase, as in TestTxIDFromHex.
+	const raw = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	const v5 = "050000800a27a726"
+	v5TxID := strings.Repeat("5e", 32)
+	result := " \n"
+	var decodes int
+	rpc := fakeRPC{
+		sendRawTransaction: func(ctx context.Context, txHex string) (string, error) { return result, nil },
+		call: func(_ context.Context, method string, params any, out any) error {
+			if method != "decoderawtransaction" || params.([]any)[0] != v5 {
+				return errors.New("unexpected call " + method)
+			}
+			decodes++
+			return setOut(out, map[string]any{"txid": strings.ToUpper(v5TxID)})
+		},
+	}
+
+	c, _ := New(rpc)
+	if _, err := c.Submit(context.Background(), raw); !errors.Is(err, ErrEmptyTxID) {
+		t.Fatalf("err=%v", err)
+	}
+	result = "bad"
+	if _, err := c.Submit(context.Background(), raw); err == nil || errors.Is(err, ErrEmptyTxID) {
+		t.Fatalf("err=%v", err)
+	}
+
+	result = ""
+	c, _ = New(rpc, WithEmptyTxIDFallback(true))
+	txid, err := c.Submit(context.Background(), raw)
+	if err != nil || txid != "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b" {
+		t.Fatalf("txid=%s err=%v", txid, err)
+	}
+	if decodes != 0 {
+		t.Fatalf("v4 txid decoded by the node")
+	}
+	// v5 txids cannot be hashed locally, so the node decodes them.
+	if txid, err := c.Submit(context.Background(), v5); err != nil || txid != v5TxID || decodes != 1 {
+		t.Fatalf("txid=%s err=%v decodes=%d", txid, err, decodes)
+	}
+	if _, err := TxIDFromHex(v5); !errors.Is(err, ErrLocalTxIDUnsupported) {
+		t.Fatalf("TxIDFromHex err=%v", err)
+	}
+}
+
+func TestShieldedOutputsFailClosed(t *testing.T) {
+	var sent int
+	rpc := fakeRPC{
+		call: func(_ context.Context, _ string, _ any, out any) error {
+			return setOut(out, map[string]any{
+				"vout": []any{
+					map[string]any{"n": 0, "value": 1, "valueZat": 100000000, "scriptPubKey": map[string]any{"type": "pubkeyhash", "addresses": []string{"t1ok"}}},
+				},
+				"orchard":    map[string]any{"actions": []any{map[string]any{}}},
+				"vjoinsplit": []any{map[string]any{}},
+			})
+		},
+		sendRawTransaction: func(context.Context, string) (string, error) {
+			sent++
+			return strings.Repeat("ab", 32), nil
+		},
+	}
+	want := []ExpectedOutput{{Address: "t1ok", Amount: 100000000}}
+	for _, tc := range []struct {
+		opt     Option
+		wantErr error
+		detail  string
+	}{
+		{WithAllowedAddresses([]string{"t1ok"}), ErrAddressNotAllowed, "orchard output 0 (shielded), sprout output 0 (shielded)"},
+		{WithExpectedOutputs(want, true), ErrOutputMismatch, "unexpected orchard output 0 (shielded); unexpected sprout output 0 (shielded)"},
+	} {
+		c, err := New(rpc, tc.opt)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if _, err := c.Submit(context.Background(), "00"); !errors.Is(err, tc.wantErr) || !strings.Contains(err.Error(), tc.detail) || sent != 0 {
+			t.Fatalf("err=%v sent=%d", err, sent)
+		}
+
+		c, _ = New(rpc, tc.opt, WithAllowShieldedOutputs(true))
+		if _, err := c.Submit(context.Background(), "00"); err != nil {
+			t.Fatalf("opted in: %v", err)
+		}
+		sent = 0
+	}
+
+	// Without exact matching, shielded outputs are still allowed alongside
+	// the expected ones.
+	c, _ := New(rpc, WithExpectedOutputs(want, false))
+	if _, err := c.Submit(context.Background(), "00"); err != nil {
+		t.Fatalf("inexact: %v", err)
+	}
+}
+
+func TestNewBatchRPC_SendsOneBatchThroughTheWrappers(t *testing.T) {
+	mined := strings.Repeat("a", 64)
+	unknown := strings.Repeat("c", 64)
+	var posts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		var reqs []map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("batch body: %v", err)
+			return
+		}
+		var replies []map[string]any
+		// Replies come back in reverse order, matched by id.
+		for i := len(reqs) - 1; i >= 0; i-- {
+			req := reqs[i]
+			if req["jsonrpc"] != "2.0" {
+				t.Errorf("jsonrpc=%v", req["jsonrpc"])
+			}
+			reply := map[string]any{"id": req["id"]}
+			switch req["params"].([]any)[0] {
+			case mined:
+				reply["result"] = map[string]any{"blockhash": strings.Repeat("01", 32), "height": 101, "confirmations": 10}
+			default:
+				reply["error"] = map[string]any{"code": -5, "message": "No such mempool or blockchain transaction"}
+			}
+			replies = append(replies, reply)
+		}
+		_ = json.NewEncoder(w).Encode(replies)
+	}))
+	defer srv.Close()
+
+	hc := RPCHTTPClient("", "", 0, WithJSONRPCVersion("2.0"), WithIDGenerator(func(seq uint64) any { return fmt.Sprintf("juno-%d", seq) }))
+	rpc := NewBatchRPC(fakeRPC{call: func(_ context.Context, method string, _ any, out any) error {
+		return setOut(out, 110)
+	}}, srv.URL, "", "", hc)
+
+	var logged []string
+	c, err := New(rpc, WithAttemptLog(func(e AttemptEvent) {
+		logged = append(logged, fmt.Sprintf("%s:%v", e.Method, e.Err != nil))
+	}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if !c.Batching() {
+		t.Fatalf("expected batching")
+	}
+	got, err := c.StatusBulk(context.Background(), []string{mined, unknown})
+	if err != nil {
+		t.Fatalf("StatusBulk: %v", err)
+	}
+	if posts != 1 || got[0].Confirmations != 10 || got[1] != (TxStatus{TxID: unknown}) {
+		t.Fatalf("posts=%d got=%+v", posts, got)
+	}
+	if strings.Join(logged, " ") != "getblockcount:false getrawtransaction:false getrawtransaction:true" {
+		t.Fatalf("logged=%v", logged)
+	}
+
+	// Read-only refuses mutating methods in a batch before sending it.
+	c, _ = New(rpc, WithReadOnly(true))
+	err = c.batch.CallBatch(context.Background(), []BatchCall{{Method: "getblockcount"}, {Method: "sendrawtransaction", Params: []any{"00"}}})
+	if !errors.Is(err, ErrReadOnly) || posts != 1 {
+		t.Fatalf("err=%v posts=%d", err, posts)
+	}
+}
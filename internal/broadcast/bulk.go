@@ -0,0 +1,233 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+)
+
+// BatchCall is one request in a JSON-RPC batch. Out receives the result;
+// Err is set by the BatchCaller when that request alone failed.
+type BatchCall struct {
+	Method string
+	Params any
+	Out    any
+	Err    error
+}
+
+// BatchCaller is implemented by RPCs that can send several requests in one
+// JSON-RPC batch round trip. CallBatch returns an error only when the batch as
+// a whole failed; per-request failures go in each call's Err.
+type BatchCaller interface {
+	CallBatch(ctx context.Context, calls []BatchCall) error
+}
+
+// callBatch sends calls as a batch through next, which wraps a BatchCaller.
+func callBatch(ctx context.Context, next RPC, calls []BatchCall) error {
+	b, ok := next.(BatchCaller)
+	if !ok {
+		return errors.New("broadcast: rpc does not support batches")
+	}
+	return b.CallBatch(ctx, calls)
+}
+
+// NewBatchRPC adds JSON-RPC batching to rpc, the junocashd client for
+// endpoint: single calls still go through rpc, and CallBatch posts its calls
+// as one JSON array of requests with httpClient, authenticating with
+// user/pass as rpc does (use RPCHTTPClient for the same transport, bearer
+// token, and limits). Pass the result to New, and StatusBulk sends its
+// lookups in batches.
+func NewBatchRPC(rpc RPC, endpoint, user, pass string, httpClient *http.Client) RPC {
+	return &batchRPC{RPC: rpc, endpoint: endpoint, user: user, pass: pass, http: httpClient}
+}
+
+type batchRPC struct {
+	RPC
+	endpoint   string
+	user, pass string
+	http       *http.Client
+	nextID     atomic.Uint64
+}
+
+type batchRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type batchReply struct {
+	Result json.RawMessage     `json:"result"`
+	Error  *junocashd.RPCError `json:"error"`
+	ID     json.RawMessage     `json:"id"`
+}
+
+func (b *batchRPC) CallBatch(ctx context.Context, calls []BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	reqs := make([]batchRequest, len(calls))
+	index := make(map[uint64]int, len(calls))
+	for i, call := range calls {
+		params := call.Params
+		if params == nil {
+			params = []any{}
+		}
+		id := b.nextID.Add(1)
+		reqs[i] = batchRequest{JSONRPC: "1.0", ID: id, Method: call.Method, Params: params}
+		index[id] = i
+	}
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return fmt.Errorf("junocashd: marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("junocashd: new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.user != "" || b.pass != "" {
+		req.SetBasicAuth(b.user, b.pass)
+	}
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("junocashd: request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("junocashd: read response: %w", err)
+	}
+
+	// A node that rejects the batch as a whole answers with one error.
+	var single batchReply
+	if json.Unmarshal(respBody, &single) == nil && single.Error != nil {
+		return single.Error
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(respBody))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return fmt.Errorf("junocashd: http %d: %s", resp.StatusCode, msg)
+	}
+	var replies []batchReply
+	if err := json.Unmarshal(respBody, &replies); err != nil {
+		return fmt.Errorf("junocashd: unmarshal batch response: %w", err)
+	}
+
+	answered := make([]bool, len(calls))
+	for _, reply := range replies {
+		var id uint64
+		if json.Unmarshal(reply.ID, &id) != nil {
+			continue
+		}
+		i, ok := index[id]
+		if !ok || answered[i] {
+			continue
+		}
+		answered[i] = true
+		switch {
+		case reply.Error != nil:
+			calls[i].Err = reply.Error
+		case calls[i].Out == nil || len(reply.Result) == 0 || bytes.Equal(reply.Result, []byte("null")):
+		default:
+			if err := json.Unmarshal(reply.Result, calls[i].Out); err != nil {
+				calls[i].Err = fmt.Errorf("junocashd: unmarshal result: %w", err)
+			}
+		}
+	}
+	for i, ok := range answered {
+		if !ok {
+			calls[i].Err = errors.New("junocashd: batch response has no reply for " + calls[i].Method)
+		}
+	}
+	return nil
+}
+
+// bulkBatchSize bounds how many getrawtransaction requests StatusBulk puts in
+// one batch.
+const bulkBatchSize = 500
+
+// Batching reports whether StatusBulk sends JSON-RPC batches.
+func (c *Client) Batching() bool {
+	return c.batch != nil
+}
+
+// StatusBulk looks up many txids against a single chain tip snapshot: it
+// reads getblockcount once and computes every confirmation count from it, as
+// StatusAt does. If the RPC passed to New implements BatchCaller (see
+// NewBatchRPC), the verbose getrawtransaction lookups are sent bulkBatchSize
+// at a time in JSON-RPC batches; otherwise they are made one by one.
+//
+// Statuses are returned in txids order. Txids the node does not know come
+// back with only TxID set. Any other lookup error fails the whole call.
+func (c *Client) StatusBulk(ctx context.Context, txids []string) ([]TxStatus, error) {
+	norm := make([]string, len(txids))
+	for i, txid := range txids {
+		txid, ok := c.checkTxID(txid)
+		if !ok {
+			return nil, fmt.Errorf("broadcast: txid %d must be 32-byte hex", i+1)
+		}
+		norm[i] = txid
+	}
+
+	var tip int64
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockcount", nil, &tip)
+	}); err != nil {
+		return nil, fmt.Errorf("broadcast: getblockcount: %w", err)
+	}
+
+	out := make([]TxStatus, len(norm))
+	if c.batch == nil {
+		for i, txid := range norm {
+			st, found, err := c.StatusAt(ctx, txid, tip)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				st = TxStatus{TxID: txid}
+			}
+			out[i] = st
+		}
+		return out, nil
+	}
+
+	for start := 0; start < len(norm); start += bulkBatchSize {
+		chunk := norm[start:min(start+bulkBatchSize, len(norm))]
+		verbose := make([]verboseTx, len(chunk))
+		calls := make([]BatchCall, len(chunk))
+		if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+			for i, txid := range chunk {
+				verbose[i] = verboseTx{}
+				calls[i] = BatchCall{Method: "getrawtransaction", Params: []any{txid, 1}, Out: &verbose[i]}
+			}
+			return c.batch.CallBatch(ctx, calls)
+		}); err != nil {
+			return nil, fmt.Errorf("broadcast: getrawtransaction batch: %w", err)
+		}
+		for i, call := range calls {
+			txid := chunk[i]
+			switch {
+			case call.Err == nil:
+				out[start+i] = verbose[i].status(txid, tip)
+			case c.requireTxindex && isTxindexRequiredErr(call.Err):
+				return nil, fmt.Errorf("%w: %w", ErrTxindexRequired, call.Err)
+			case isNotFoundErr(call.Err):
+				out[start+i] = TxStatus{TxID: txid}
+			default:
+				return nil, fmt.Errorf("broadcast: getrawtransaction %s: %w", txid, call.Err)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,183 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StatusCache persists deeply confirmed tx locations (block hash and height)
+// on disk so that repeat Status calls can skip the full lookup. A cache hit
+// costs one cheap RPC (getblockcount), or getblockheader when the entry is due
+// for a best-chain re-check; entries whose block left the active chain are
+// dropped.
+type StatusCache struct {
+	dir              string
+	minConfirmations int64
+	recheckAfter     time.Duration
+	now              func() time.Time
+}
+
+const (
+	cacheMagic      = "JBC2"
+	cacheRecordSize = len(cacheMagic) + 32 + 8 + 8 + 8
+)
+
+type cacheEntry struct {
+	BlockHash string
+	Height    int64
+	BlockTime int64
+	CheckedAt time.Time
+}
+
+func NewStatusCache(dir string, minConfirmations int64, recheckAfter time.Duration) (*StatusCache, error) {
+	if dir == "" {
+		return nil, errors.New("broadcast: cache dir is required")
+	}
+	if minConfirmations < 1 {
+		minConfirmations = 1
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("broadcast: cache dir: %w", err)
+	}
+	return &StatusCache{
+		dir:              dir,
+		minConfirmations: minConfirmations,
+		recheckAfter:     recheckAfter,
+		now:              time.Now,
+	}, nil
+}
+
+func WithStatusCache(sc *StatusCache) Option {
+	return func(c *Client) {
+		c.cache = sc
+	}
+}
+
+func (sc *StatusCache) path(txid string) string {
+	return filepath.Join(sc.dir, txid+".bin")
+}
+
+func (sc *StatusCache) load(txid string) (cacheEntry, bool) {
+	b, err := os.ReadFile(sc.path(txid))
+	if err != nil || len(b) != cacheRecordSize || string(b[:len(cacheMagic)]) != cacheMagic {
+		return cacheEntry{}, false
+	}
+	b = b[len(cacheMagic):]
+	return cacheEntry{
+		BlockHash: hex.EncodeToString(b[:32]),
+		Height:    int64(binary.BigEndian.Uint64(b[32:40])),
+		BlockTime: int64(binary.BigEndian.Uint64(b[40:48])),
+		CheckedAt: time.Unix(int64(binary.BigEndian.Uint64(b[48:56])), 0),
+	}, true
+}
+
+func (sc *StatusCache) store(txid string, e cacheEntry) error {
+	hash, err := hex.DecodeString(e.BlockHash)
+	if err != nil || len(hash) != 32 {
+		return errors.New("broadcast: cache: blockhash must be 32-byte hex")
+	}
+	b := make([]byte, 0, cacheRecordSize)
+	b = append(b, cacheMagic...)
+	b = append(b, hash...)
+	b = binary.BigEndian.AppendUint64(b, uint64(e.Height))
+	b = binary.BigEndian.AppendUint64(b, uint64(e.BlockTime))
+	b = binary.BigEndian.AppendUint64(b, uint64(e.CheckedAt.Unix()))
+
+	tmp, err := os.CreateTemp(sc.dir, txid+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), sc.path(txid))
+}
+
+func (sc *StatusCache) remove(txid string) {
+	_ = os.Remove(sc.path(txid))
+}
+
+// CachedBlockTime returns the block time the status cache recorded for txid,
+// without asking the node. It reports false when there is no cache or no
+// entry for txid.
+func (c *Client) CachedBlockTime(txid string) (int64, bool) {
+	if c.cache == nil {
+		return 0, false
+	}
+	txid, ok := normalizeTxID(txid)
+	if !ok {
+		return 0, false
+	}
+	e, ok := c.cache.load(txid)
+	if !ok || e.BlockTime <= 0 {
+		return 0, false
+	}
+	return e.BlockTime, true
+}
+
+func (c *Client) cachedStatus(ctx context.Context, txid string) (TxStatus, bool, error) {
+	e, ok := c.cache.load(txid)
+	if !ok {
+		return TxStatus{}, false, nil
+	}
+
+	now := c.cache.now()
+	if now.Sub(e.CheckedAt) >= c.cache.recheckAfter {
+		confs, onChain, err := c.blockConfirmations(ctx, e.BlockHash)
+		if err != nil {
+			return TxStatus{}, false, err
+		}
+		if !onChain {
+			c.cache.remove(txid)
+			return TxStatus{}, false, nil
+		}
+		e.CheckedAt = now
+		_ = c.cache.store(txid, e)
+		return TxStatus{TxID: txid, Confirmations: confs, BlockHash: e.BlockHash, BlockTime: e.BlockTime}, true, nil
+	}
+
+	var tip int64
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockcount", nil, &tip)
+	}); err != nil {
+		return TxStatus{}, false, err
+	}
+	confs := tip - e.Height + 1
+	if confs <= 0 {
+		c.cache.remove(txid)
+		return TxStatus{}, false, nil
+	}
+	return TxStatus{TxID: txid, Confirmations: confs, BlockHash: e.BlockHash, BlockTime: e.BlockTime}, true, nil
+}
+
+// storeCachedStatus is best-effort: failing to cache never fails Status.
+func (c *Client) storeCachedStatus(ctx context.Context, st TxStatus) {
+	if st.BlockHash == "" || st.Confirmations < c.cache.minConfirmations {
+		return
+	}
+	var hdr struct {
+		Height int64 `json:"height"`
+		Time   int64 `json:"time"`
+	}
+	if err := c.rpc.Call(ctx, "getblockheader", []any{st.BlockHash, true}, &hdr); err != nil {
+		return
+	}
+	_ = c.cache.store(st.TxID, cacheEntry{
+		BlockHash: st.BlockHash,
+		Height:    hdr.Height,
+		BlockTime: hdr.Time,
+		CheckedAt: c.cache.now(),
+	})
+}
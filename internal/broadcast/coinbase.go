@@ -0,0 +1,97 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CoinbaseMaturity is how many confirmations a coinbase output needs before a
+// transaction spending it is accepted.
+const CoinbaseMaturity = 100
+
+var ErrImmatureCoinbase = errors.New("broadcast: transaction spends a coinbase output before it matured")
+
+// ImmatureCoinbaseError is returned by Submit when the node rejects the tx
+// with bad-txns-premature-spend-of-coinbase. It matches both
+// ErrImmatureCoinbase and the node's error.
+//
+// Input is the least-confirmed coinbase output the tx spends, as txid:vout,
+// with its Confirmations. Finding it needs getrawtransaction to resolve the
+// spent txs (so -txindex, unless they are in the mempool); when that fails
+// Input is empty.
+type ImmatureCoinbaseError struct {
+	Err           error
+	Input         string
+	Confirmations int64
+}
+
+func (e *ImmatureCoinbaseError) Error() string {
+	if e.Input == "" {
+		return fmt.Sprintf("%s: %v", ErrImmatureCoinbase, e.Err)
+	}
+	return fmt.Sprintf("%s (%s has %d of %d confirmations): %v",
+		ErrImmatureCoinbase, e.Input, e.Confirmations, CoinbaseMaturity, e.Err)
+}
+
+func (e *ImmatureCoinbaseError) Unwrap() []error { return []error{ErrImmatureCoinbase, e.Err} }
+
+// BlocksRemaining is how many more blocks Input needs before the tx can be
+// accepted, or -1 if Input is unknown.
+func (e *ImmatureCoinbaseError) BlocksRemaining() int64 {
+	if e.Input == "" {
+		return -1
+	}
+	return max(CoinbaseMaturity-e.Confirmations, 0)
+}
+
+func isPrematureCoinbaseErr(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "bad-txns-premature-spend-of-coinbase")
+}
+
+// explainCoinbase turns a premature-spend-of-coinbase rejection into an
+// *ImmatureCoinbaseError, looking up the coinbase input best-effort;
+// other errors are returned unchanged.
+func (c *Client) explainCoinbase(ctx context.Context, rpc RPC, raw string, err error) error {
+	if !isPrematureCoinbaseErr(err) {
+		return err
+	}
+	ce := &ImmatureCoinbaseError{Err: err}
+
+	var decoded struct {
+		Vin []struct {
+			TxID string `json:"txid"`
+			Vout uint32 `json:"vout"`
+		} `json:"vin"`
+	}
+	if doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return rpc.Call(ctx, "decoderawtransaction", []any{raw}, &decoded)
+	}) != nil {
+		return ce
+	}
+	for _, in := range decoded.Vin {
+		if in.TxID == "" {
+			continue
+		}
+		var prev struct {
+			Confirmations int64 `json:"confirmations"`
+			Vin           []struct {
+				Coinbase string `json:"coinbase"`
+			} `json:"vin"`
+		}
+		if doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+			return rpc.Call(ctx, "getrawtransaction", []any{in.TxID, 1}, &prev)
+		}) != nil {
+			continue
+		}
+		if len(prev.Vin) == 0 || prev.Vin[0].Coinbase == "" {
+			continue
+		}
+		if ce.Input == "" || prev.Confirmations < ce.Confirmations {
+			ce.Input = fmt.Sprintf("%s:%d", strings.ToLower(in.TxID), in.Vout)
+			ce.Confirmations = prev.Confirmations
+		}
+	}
+	return ce
+}
@@ -0,0 +1,86 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Conflict is a mempool transaction already spending one of a raw tx's inputs.
+type Conflict struct {
+	TxID         string `json:"txid"`
+	Vout         uint32 `json:"vout"`
+	SpendingTxID string `json:"spending_txid"`
+}
+
+// CheckConflicts decodes rawTxHex and asks the node (gettxspendingprevout)
+// whether any of its inputs are already spent by another mempool tx. It returns
+// ErrMethodUnsupported if the node lacks gettxspendingprevout.
+func (c *Client) CheckConflicts(ctx context.Context, rawTxHex string) ([]Conflict, error) {
+	raw, err := normalizeHex(rawTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded struct {
+		TxID string `json:"txid"`
+		Vin  []struct {
+			TxID string `json:"txid"`
+			Vout uint32 `json:"vout"`
+		} `json:"vin"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "decoderawtransaction", []any{raw}, &decoded)
+	}); err != nil {
+		return nil, fmt.Errorf("broadcast: decoderawtransaction: %w", err)
+	}
+
+	prevouts := make([]map[string]any, 0, len(decoded.Vin))
+	for _, in := range decoded.Vin {
+		if in.TxID == "" {
+			continue // coinbase
+		}
+		prevouts = append(prevouts, map[string]any{"txid": in.TxID, "vout": in.Vout})
+	}
+	if len(prevouts) == 0 {
+		return nil, nil
+	}
+
+	spent, err := c.spendingPrevouts(ctx, prevouts)
+	if err != nil {
+		return nil, err
+	}
+
+	self := strings.ToLower(decoded.TxID)
+	var conflicts []Conflict
+	for _, s := range spent {
+		spender := strings.ToLower(strings.TrimSpace(s.SpendingTxID))
+		if spender == "" || spender == self {
+			continue
+		}
+		conflicts = append(conflicts, Conflict{TxID: strings.ToLower(s.TxID), Vout: s.Vout, SpendingTxID: spender})
+	}
+	return conflicts, nil
+}
+
+type spentPrevout struct {
+	TxID         string `json:"txid"`
+	Vout         uint32 `json:"vout"`
+	SpendingTxID string `json:"spendingtxid"`
+}
+
+func (c *Client) spendingPrevouts(ctx context.Context, prevouts []map[string]any) ([]spentPrevout, error) {
+	var spent []spentPrevout
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "gettxspendingprevout", []any{prevouts}, &spent)
+	})
+	if isMethodNotFoundErr(err) {
+		return nil, fmt.Errorf("%w: gettxspendingprevout", ErrMethodUnsupported)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("broadcast: gettxspendingprevout: %w", err)
+	}
+	return spent, nil
+}
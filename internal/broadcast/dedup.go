@@ -0,0 +1,97 @@
+package broadcast
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dedupMaxEntries bounds the in-process dedup set; the least recently
+// submitted txs are forgotten first.
+const dedupMaxEntries = 4096
+
+// WithInProcessDedup makes Submit and SubmitDetailed remember the txid of
+// every raw tx they broadcast for ttl, and answer a repeat of the same raw
+// hex within that window with the remembered txid instead of sending it to
+// the node again. It is a cheap local guard for hot loops (serve, FIFO and
+// queue workers) that would otherwise resubmit a tx enqueued twice and get
+// "already known" errors back. It does not consult the chain, so a tx
+// evicted from the mempool meanwhile is not rebroadcast until ttl has
+// passed. Only successful submits are remembered, and concurrent submits of
+// the same tx may still both reach the node. At most 4096 txs are
+// remembered. ttl <= 0 disables it.
+func WithInProcessDedup(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.dedup = nil
+		if ttl > 0 {
+			c.dedup = newSubmitDedup(ttl, time.Now)
+		}
+	}
+}
+
+type dedupEntry struct {
+	key  [sha256.Size]byte
+	txid string
+	at   time.Time
+}
+
+// submitDedup is an LRU set of recently submitted raw txs, keyed by the
+// SHA-256 of their lowercase hex.
+type submitDedup struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // of *dedupEntry, most recent first
+}
+
+func newSubmitDedup(ttl time.Duration, now func() time.Time) *submitDedup {
+	return &submitDedup{ttl: ttl, now: now, entries: make(map[[sha256.Size]byte]*list.Element), order: list.New()}
+}
+
+func dedupKey(raw string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(raw))))
+}
+
+// get returns the txid remembered for raw, if it was submitted within ttl.
+func (d *submitDedup) get(raw string) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	key := dedupKey(raw)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	el, ok := d.entries[key]
+	if !ok {
+		return "", false
+	}
+	e := el.Value.(*dedupEntry)
+	if d.now().Sub(e.at) >= d.ttl {
+		d.order.Remove(el)
+		delete(d.entries, key)
+		return "", false
+	}
+	return e.txid, true
+}
+
+// put remembers txid as the result of submitting raw.
+func (d *submitDedup) put(raw, txid string) {
+	if d == nil {
+		return
+	}
+	key := dedupKey(raw)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if el, ok := d.entries[key]; ok {
+		d.order.Remove(el)
+	}
+	d.entries[key] = d.order.PushFront(&dedupEntry{key: key, txid: txid, at: d.now()})
+	for d.order.Len() > dedupMaxEntries {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.entries, oldest.Value.(*dedupEntry).key)
+	}
+}
@@ -0,0 +1,102 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultETASampleBlocks is how many recent block intervals
+// EstimateConfirmationTime averages unless WithETASampleBlocks says otherwise.
+const defaultETASampleBlocks = 20
+
+// WithETASampleBlocks sets how many of the most recent block intervals
+// AverageBlockInterval averages over. Values <= 0 keep the default of 20.
+func WithETASampleBlocks(k int64) Option {
+	return func(c *Client) {
+		if k > 0 {
+			c.etaSampleBlocks = k
+		}
+	}
+}
+
+// AverageBlockInterval estimates the block time from the timestamps of the
+// chain tip and the block K heights below it (see WithETASampleBlocks). A
+// chain shorter than K is sampled from genesis.
+func (c *Client) AverageBlockInterval(ctx context.Context) (time.Duration, error) {
+	k := c.etaSampleBlocks
+	if k <= 0 {
+		k = defaultETASampleBlocks
+	}
+	var tip int64
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockcount", nil, &tip)
+	}); err != nil {
+		return 0, fmt.Errorf("broadcast: getblockcount: %w", err)
+	}
+	k = min(k, tip)
+	if k <= 0 {
+		return 0, errors.New("broadcast: chain too short to estimate block time")
+	}
+
+	newest, err := c.blockTimeAt(ctx, tip)
+	if err != nil {
+		return 0, err
+	}
+	oldest, err := c.blockTimeAt(ctx, tip-k)
+	if err != nil {
+		return 0, err
+	}
+	if newest <= oldest {
+		return 0, errors.New("broadcast: block timestamps do not advance; cannot estimate block time")
+	}
+	return time.Duration(newest-oldest) * time.Second / time.Duration(k), nil
+}
+
+func (c *Client) blockTimeAt(ctx context.Context, height int64) (int64, error) {
+	hash, err := c.callString(ctx, "getblockhash", []any{height})
+	if err != nil {
+		return 0, fmt.Errorf("broadcast: getblockhash %d: %w", height, err)
+	}
+	var hdr struct {
+		Time int64 `json:"time"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockheader", []any{hash, true}, &hdr)
+	}); err != nil {
+		return 0, fmt.Errorf("broadcast: getblockheader: %w", err)
+	}
+	return hdr.Time, nil
+}
+
+// EstimateConfirmationTime estimates how long until txid has target
+// confirmations (counted per WithConfirmationBase): the confirmations still
+// missing times AverageBlockInterval. A mempool tx is assumed to make the
+// next block. It returns 0 once the target is reached.
+func (c *Client) EstimateConfirmationTime(ctx context.Context, txid string, target int64) (time.Duration, error) {
+	if target < 0 {
+		return 0, errors.New("broadcast: target must be >= 0")
+	}
+	st, found, err := c.Status(ctx, txid)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("broadcast: cannot estimate confirmation time for unknown tx %s", txid)
+	}
+
+	required := target
+	if c.confirmationBase == BlockExclusive {
+		required++
+	}
+	remaining := required - st.Confirmations
+	if remaining <= 0 {
+		return 0, nil
+	}
+	interval, err := c.AverageBlockInterval(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(remaining) * interval, nil
+}
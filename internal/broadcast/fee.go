@@ -0,0 +1,217 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrCoinbaseFee = errors.New("broadcast: coinbase transactions pay no fee")
+
+// TxFee is the fee a tx paid, in zatoshis, with the sums it was computed
+// from: Fee = TransparentIn - TransparentOut + ShieldedValueBalance.
+//
+// ShieldedValueBalance is the net value the tx moved out of the shielded
+// pools (sapling and orchard value balances, and sprout vpub_new - vpub_old).
+// It is public, so the fee is exact, but the shielded notes it nets are not:
+// for txs with shielded components Shielded is set and Note says so.
+type TxFee struct {
+	TxID                 string `json:"txid"`
+	Fee                  int64  `json:"fee_zat"`
+	TransparentIn        int64  `json:"transparent_in_zat"`
+	TransparentOut       int64  `json:"transparent_out_zat"`
+	ShieldedValueBalance int64  `json:"shielded_value_balance_zat"`
+	Shielded             bool   `json:"shielded"`
+	Note                 string `json:"note,omitempty"`
+}
+
+// ComputeFee returns the fee txid paid, in zatoshis. See TxFee.
+func (c *Client) ComputeFee(ctx context.Context, txid string) (int64, error) {
+	fee, found, err := c.TxFee(ctx, txid)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, errors.New("broadcast: transaction not found")
+	}
+	return fee.Fee, nil
+}
+
+// feeTx is the part of verbose getrawtransaction TxFee reads.
+type feeTx struct {
+	Vin []struct {
+		Coinbase string `json:"coinbase"`
+		TxID     string `json:"txid"`
+		Vout     uint32 `json:"vout"`
+	} `json:"vin"`
+	Vout            []decodedOutput `json:"vout"`
+	ValueBalance    *json.Number    `json:"valueBalance"`
+	ValueBalanceZat *int64          `json:"valueBalanceZat"`
+	Orchard         *struct {
+		Actions         []json.RawMessage `json:"actions"`
+		ValueBalance    *json.Number      `json:"valueBalance"`
+		ValueBalanceZat *int64            `json:"valueBalanceZat"`
+	} `json:"orchard"`
+	VJoinSplit []struct {
+		VPubOld    json.Number `json:"vpub_old"`
+		VPubNew    json.Number `json:"vpub_new"`
+		VPubOldZat *int64      `json:"vpub_oldZat"`
+		VPubNewZat *int64      `json:"vpub_newZat"`
+	} `json:"vjoinsplit"`
+	VShieldedSpend  []json.RawMessage `json:"vShieldedSpend"`
+	VShieldedOutput []json.RawMessage `json:"vShieldedOutput"`
+}
+
+// TxFee computes the fee txid paid from the tx and the outputs it spends:
+// each input's value comes from getrawtransaction of the tx it spends, or
+// failing that from gettxout, which still finds outputs spent only in the
+// mempool. Resolving the inputs of a confirmed tx therefore needs -txindex;
+// without it TxFee fails with ErrTxindexRequired. Unknown txids report
+// found=false, and coinbase txs fail with ErrCoinbaseFee.
+func (c *Client) TxFee(ctx context.Context, txid string) (TxFee, bool, error) {
+	txid, ok := normalizeTxID(txid)
+	if !ok {
+		return TxFee{}, false, errInvalidTxID
+	}
+	var tx feeTx
+	found, err := c.verboseRawTx(ctx, txid, &tx)
+	if err != nil || !found {
+		return TxFee{}, found, err
+	}
+
+	fee := TxFee{TxID: txid}
+	for _, in := range tx.Vin {
+		if in.Coinbase != "" {
+			return TxFee{}, true, ErrCoinbaseFee
+		}
+		v, err := c.prevoutValue(ctx, strings.ToLower(in.TxID), in.Vout)
+		if err != nil {
+			return TxFee{}, true, err
+		}
+		fee.TransparentIn += v
+	}
+	for _, out := range tx.Vout {
+		v, err := out.zatoshis()
+		if err != nil {
+			return TxFee{}, true, fmt.Errorf("broadcast: output %d: %w", out.N, err)
+		}
+		fee.TransparentOut += v
+	}
+
+	balance, err := tx.shieldedValueBalance()
+	if err != nil {
+		return TxFee{}, true, err
+	}
+	fee.ShieldedValueBalance = balance
+	fee.Shielded = balance != 0 || len(tx.VShieldedSpend) > 0 || len(tx.VShieldedOutput) > 0 || len(tx.VJoinSplit) > 0 ||
+		(tx.Orchard != nil && len(tx.Orchard.Actions) > 0)
+	if fee.Shielded {
+		fee.Note = fmt.Sprintf("tx has shielded components; the fee counts their public net value balance of %s, the individual shielded amounts are not visible", formatSignedAmount(balance))
+	}
+	fee.Fee = fee.TransparentIn - fee.TransparentOut + fee.ShieldedValueBalance
+	return fee, true, nil
+}
+
+// verboseRawTx decodes verbose getrawtransaction of txid into v; unknown
+// txids report found=false.
+func (c *Client) verboseRawTx(ctx context.Context, txid string, v any) (bool, error) {
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getrawtransaction", []any{txid, 1}, v)
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case c.requireTxindex && isTxindexRequiredErr(err):
+		return false, fmt.Errorf("%w: %w", ErrTxindexRequired, err)
+	case isNotFoundErr(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("broadcast: getrawtransaction: %w", err)
+	}
+}
+
+// prevoutValue returns the value of output vout of txid, in zatoshis.
+func (c *Client) prevoutValue(ctx context.Context, txid string, vout uint32) (int64, error) {
+	var prev struct {
+		Vout []decodedOutput `json:"vout"`
+	}
+	found, err := c.verboseRawTx(ctx, txid, &prev)
+	if err != nil {
+		return 0, err
+	}
+	if found {
+		for _, out := range prev.Vout {
+			if out.N == int(vout) {
+				return out.zatoshis()
+			}
+		}
+		return 0, fmt.Errorf("broadcast: prevout %s:%d does not exist", txid, vout)
+	}
+
+	var utxo *decodedOutput
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "gettxout", []any{txid, vout, false}, &utxo)
+	}); err != nil {
+		return 0, fmt.Errorf("broadcast: gettxout: %w", err)
+	}
+	if utxo == nil {
+		return 0, fmt.Errorf("%w: cannot resolve spent prevout %s:%d", ErrTxindexRequired, txid, vout)
+	}
+	return utxo.zatoshis()
+}
+
+// shieldedValueBalance sums the tx's value balances, preferring the node's
+// zatoshi fields and otherwise parsing the coin amounts exactly.
+func (tx feeTx) shieldedValueBalance() (int64, error) {
+	var total int64
+	add := func(zat *int64, coins *json.Number, sign int64) error {
+		switch {
+		case zat != nil:
+			total += sign * *zat
+		case coins != nil && coins.String() != "":
+			v, err := parseSignedAmount(coins.String())
+			if err != nil {
+				return err
+			}
+			total += sign * v
+		}
+		return nil
+	}
+	if err := add(tx.ValueBalanceZat, tx.ValueBalance, 1); err != nil {
+		return 0, err
+	}
+	if tx.Orchard != nil {
+		if err := add(tx.Orchard.ValueBalanceZat, tx.Orchard.ValueBalance, 1); err != nil {
+			return 0, err
+		}
+	}
+	for _, js := range tx.VJoinSplit {
+		if err := add(js.VPubNewZat, &js.VPubNew, 1); err != nil {
+			return 0, err
+		}
+		if err := add(js.VPubOldZat, &js.VPubOld, -1); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+func parseSignedAmount(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		v, err := ParseAmount(rest)
+		return -v, err
+	}
+	return ParseAmount(s)
+}
+
+func formatSignedAmount(zat int64) string {
+	if zat < 0 {
+		return "-" + formatAmount(-zat)
+	}
+	return formatAmount(zat)
+}
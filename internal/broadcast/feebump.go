@@ -0,0 +1,139 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrFeeTooLow   = errors.New("broadcast: transaction fee is too low for the node's mempool")
+	ErrNotWalletTx = errors.New("broadcast: transaction is not in the node's wallet; raise its fee and re-sign it manually")
+)
+
+// feeTooLowReasons are reject reasons (from sendrawtransaction or
+// testmempoolaccept) meaning the tx pays too little fee to be accepted.
+var feeTooLowReasons = []string{
+	"min relay fee not met",
+	"mempool min fee not met",
+	"insufficient priority",
+	"insufficient fee",
+}
+
+func isFeeTooLowErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, r := range feeTooLowReasons {
+		if strings.Contains(msg, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// explainFee marks a fee rejection as ErrFeeTooLow; other errors are
+// returned unchanged.
+func explainFee(err error) error {
+	if !isFeeTooLowErr(err) || errors.Is(err, ErrFeeTooLow) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrFeeTooLow, err)
+}
+
+// FeeBump is one bumpfee step: the wallet replaced OrigTxID, paying OrigFee,
+// with TxID, paying Fee (both in coins).
+type FeeBump struct {
+	OrigTxID string  `json:"orig_txid"`
+	TxID     string  `json:"txid"`
+	OrigFee  float64 `json:"orig_fee"`
+	Fee      float64 `json:"fee"`
+}
+
+// BumpFee asks the node's wallet to replace txid with a higher-fee version
+// (bumpfee). Txs the wallet does not own fail with ErrNotWalletTx; nodes
+// without bumpfee fail with ErrMethodUnsupported.
+func (c *Client) BumpFee(ctx context.Context, txid string) (FeeBump, error) {
+	txid, ok := normalizeTxID(txid)
+	if !ok {
+		return FeeBump{}, errInvalidTxID
+	}
+	var res struct {
+		TxID    string  `json:"txid"`
+		OrigFee float64 `json:"origfee"`
+		Fee     float64 `json:"fee"`
+	}
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "bumpfee", []any{txid}, &res)
+	})
+	switch {
+	case err == nil:
+	case isMethodNotFoundErr(err):
+		return FeeBump{}, fmt.Errorf("%w: bumpfee", ErrMethodUnsupported)
+	case isNotWalletTxErr(err):
+		return FeeBump{}, fmt.Errorf("%w: %w", ErrNotWalletTx, err)
+	default:
+		return FeeBump{}, fmt.Errorf("broadcast: bumpfee: %w", err)
+	}
+	return FeeBump{OrigTxID: txid, TxID: strings.ToLower(strings.TrimSpace(res.TxID)), OrigFee: res.OrigFee, Fee: res.Fee}, nil
+}
+
+// isNotWalletTxErr reports bumpfee's "Invalid or non-wallet transaction id".
+func isNotWalletTxErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "non-wallet") || strings.Contains(msg, "not found in wallet")
+}
+
+// SubmitWithFeeBump is Submit that recovers from fee rejections: while the
+// node rejects the tx with ErrFeeTooLow, it asks the wallet to bumpfee the
+// rejected tx and submits the replacement, at most maxBumps times. It returns
+// the txid finally accepted and every bump made, also on failure. Txs the
+// wallet does not own cannot be bumped; the error then matches both
+// ErrFeeTooLow and ErrNotWalletTx.
+func (c *Client) SubmitWithFeeBump(ctx context.Context, rawTxHex string, maxBumps int) (string, []FeeBump, error) {
+	txid, err := c.Submit(ctx, rawTxHex)
+	var bumps []FeeBump
+	for raw := rawTxHex; errors.Is(err, ErrFeeTooLow) && len(bumps) < maxBumps; {
+		orig, derr := c.decodedTxID(ctx, raw)
+		if derr != nil {
+			return "", bumps, errors.Join(err, derr)
+		}
+		bump, berr := c.BumpFee(ctx, orig)
+		if berr != nil {
+			return "", bumps, fmt.Errorf("%w; cannot bump: %w", err, berr)
+		}
+		bumps = append(bumps, bump)
+
+		if raw, err = c.walletTxHex(ctx, bump.TxID); err != nil {
+			return "", bumps, err
+		}
+		// bumpfee usually broadcasts the replacement itself.
+		if txid, err = c.Submit(ctx, raw); isAlreadyKnownErr(err) {
+			txid, err = bump.TxID, nil
+		}
+	}
+	if err != nil {
+		return "", bumps, err
+	}
+	return txid, bumps, nil
+}
+
+func (c *Client) decodedTxID(ctx context.Context, rawTxHex string) (string, error) {
+	raw, err := normalizeHex(rawTxHex)
+	if err != nil {
+		return "", err
+	}
+	var decoded struct {
+		TxID string `json:"txid"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "decoderawtransaction", []any{raw}, &decoded)
+	}); err != nil {
+		return "", fmt.Errorf("broadcast: decoderawtransaction: %w", err)
+	}
+	return strings.ToLower(strings.TrimSpace(decoded.TxID)), nil
+}
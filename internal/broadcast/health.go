@@ -0,0 +1,178 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NodeHealth summarizes whether a node is fit to broadcast from.
+type NodeHealth struct {
+	Peers                int64    `json:"peers"`
+	Blocks               int64    `json:"blocks"`
+	Headers              int64    `json:"headers"`
+	InitialBlockDownload bool     `json:"initial_block_download"`
+	Warnings             []string `json:"warnings,omitempty"`
+}
+
+// NodeHealth reports the node's peer count (getconnectioncount) and sync state
+// (getblockchaininfo). Warnings flag conditions under which a submitted tx may
+// not propagate or confirmations may be misleading, followed by the node's own
+// warnings (see NodeWarnings), each prefixed with "node reports: ".
+func (c *Client) NodeHealth(ctx context.Context) (NodeHealth, error) {
+	var peers int64
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getconnectioncount", nil, &peers)
+	}); err != nil {
+		return NodeHealth{}, fmt.Errorf("broadcast: getconnectioncount: %w", err)
+	}
+
+	var info struct {
+		Blocks               int64           `json:"blocks"`
+		Headers              int64           `json:"headers"`
+		InitialBlockDownload bool            `json:"initialblockdownload"`
+		Warnings             json.RawMessage `json:"warnings"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockchaininfo", nil, &info)
+	}); err != nil {
+		return NodeHealth{}, fmt.Errorf("broadcast: getblockchaininfo: %w", err)
+	}
+
+	h := NodeHealth{
+		Peers:                peers,
+		Blocks:               info.Blocks,
+		Headers:              info.Headers,
+		InitialBlockDownload: info.InitialBlockDownload,
+	}
+	if peers == 0 {
+		h.Warnings = append(h.Warnings, "node has no peers; a submitted tx may not propagate")
+	}
+	if info.InitialBlockDownload {
+		h.Warnings = append(h.Warnings, fmt.Sprintf("node is in initial block download (blocks=%d headers=%d)", info.Blocks, info.Headers))
+	}
+	network, err := c.networkWarnings(ctx)
+	if err != nil {
+		return NodeHealth{}, err
+	}
+	for _, w := range mergeWarnings(parseWarnings(info.Warnings), network) {
+		h.Warnings = append(h.Warnings, "node reports: "+w)
+	}
+	return h, nil
+}
+
+// NodeWarnings returns the warnings the node itself reports in the warnings
+// field of getblockchaininfo and getnetworkinfo (e.g. unknown block versions
+// being mined, which usually means the node is out of date), without
+// duplicates. Nodes without getnetworkinfo report getblockchaininfo's alone.
+func (c *Client) NodeWarnings(ctx context.Context) ([]string, error) {
+	var info struct {
+		Warnings json.RawMessage `json:"warnings"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockchaininfo", nil, &info)
+	}); err != nil {
+		return nil, fmt.Errorf("broadcast: getblockchaininfo: %w", err)
+	}
+	network, err := c.networkWarnings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mergeWarnings(parseWarnings(info.Warnings), network), nil
+}
+
+func (c *Client) networkWarnings(ctx context.Context) ([]string, error) {
+	var network struct {
+		Warnings json.RawMessage `json:"warnings"`
+	}
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getnetworkinfo", nil, &network)
+	})
+	switch {
+	case err == nil:
+		return parseWarnings(network.Warnings), nil
+	case isMethodNotFoundErr(err):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("broadcast: getnetworkinfo: %w", err)
+	}
+}
+
+// parseWarnings reads a warnings field, which nodes report either as one
+// string (empty when there is nothing to report) or as an array of strings.
+func parseWarnings(raw json.RawMessage) []string {
+	var list []string
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		list = []string{one}
+	} else if json.Unmarshal(raw, &list) != nil {
+		return nil
+	}
+	var out []string
+	for _, w := range list {
+		if w = strings.TrimSpace(w); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func mergeWarnings(lists ...[]string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, list := range lists {
+		for _, w := range list {
+			if !seen[w] {
+				seen[w] = true
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+// TxindexEnabled reports whether the node can look up confirmed transactions
+// with getrawtransaction, by fetching the coinbase of block 1. A node with an
+// empty chain cannot be probed and returns an error.
+func (c *Client) TxindexEnabled(ctx context.Context) (bool, error) {
+	var tip int64
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockcount", nil, &tip)
+	}); err != nil {
+		return false, fmt.Errorf("broadcast: getblockcount: %w", err)
+	}
+	if tip < 1 {
+		return false, errors.New("broadcast: chain has no mined transactions to probe -txindex with")
+	}
+	hash, err := c.callString(ctx, "getblockhash", []any{1})
+	if err != nil {
+		return false, fmt.Errorf("broadcast: getblockhash 1: %w", err)
+	}
+	var block struct {
+		Tx []string `json:"tx"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblock", []any{hash, 1}, &block)
+	}); err != nil {
+		return false, fmt.Errorf("broadcast: getblock: %w", err)
+	}
+	if len(block.Tx) == 0 {
+		return false, errors.New("broadcast: getblock: block 1 lists no transactions")
+	}
+	var raw string
+	err = doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getrawtransaction", []any{block.Tx[0]}, &raw)
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case isTxindexRequiredErr(err) || isNotFoundErr(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("broadcast: getrawtransaction: %w", err)
+	}
+}
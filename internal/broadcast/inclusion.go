@@ -0,0 +1,61 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrTxUnconfirmed = errors.New("broadcast: transaction is not confirmed yet")
+
+// InclusionProof is the outcome of checking a tx's merkle inclusion proof.
+type InclusionProof struct {
+	Verified  bool   `json:"verified"`
+	BlockHash string `json:"blockhash"`
+	Height    int64  `json:"height"`
+}
+
+// VerifyInclusion locates txid's block via Status, fetches its merkle branch
+// with gettxoutproof and checks it with verifytxoutproof. Verified is true
+// when the proof commits to txid under the block's merkle root. Unknown txids
+// report found=false; mempool txs return ErrTxUnconfirmed.
+func (c *Client) VerifyInclusion(ctx context.Context, txid string) (InclusionProof, bool, error) {
+	st, found, err := c.Status(ctx, txid)
+	if err != nil || !found {
+		return InclusionProof{}, found, err
+	}
+	if st.BlockHash == "" {
+		return InclusionProof{}, true, ErrTxUnconfirmed
+	}
+	txid = st.TxID
+
+	proof, err := c.callString(ctx, "gettxoutproof", []any{[]string{txid}, st.BlockHash})
+	if err != nil {
+		return InclusionProof{}, true, fmt.Errorf("broadcast: gettxoutproof: %w", err)
+	}
+
+	var proven []string
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "verifytxoutproof", []any{strings.TrimSpace(proof)}, &proven)
+	}); err != nil {
+		return InclusionProof{}, true, fmt.Errorf("broadcast: verifytxoutproof: %w", err)
+	}
+
+	var hdr struct {
+		Height int64 `json:"height"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getblockheader", []any{st.BlockHash, true}, &hdr)
+	}); err != nil {
+		return InclusionProof{}, true, fmt.Errorf("broadcast: getblockheader: %w", err)
+	}
+
+	res := InclusionProof{BlockHash: st.BlockHash, Height: hdr.Height}
+	for _, id := range proven {
+		if strings.ToLower(strings.TrimSpace(id)) == txid {
+			res.Verified = true
+		}
+	}
+	return res, true, nil
+}
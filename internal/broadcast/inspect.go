@@ -0,0 +1,90 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Output pools reported by InspectOutputs.
+const (
+	PoolTransparent = "transparent"
+	PoolSapling     = "sapling"
+	PoolOrchard     = "orchard"
+	PoolSprout      = "sprout"
+)
+
+// TxOutput is one output of a tx. Transparent outputs carry their script
+// type, the addresses the node derives for it, and their value. Shielded
+// outputs (sapling outputs, orchard actions) are Opaque: their recipient and
+// value are encrypted, and the value commitment hides the amount, so nothing
+// about them can be checked before broadcast. Sprout joinsplits are listed
+// as one opaque sprout output each.
+type TxOutput struct {
+	Pool      string   `json:"pool"`
+	Index     int      `json:"index"`
+	Type      string   `json:"type,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	Value     string   `json:"value,omitempty"`
+	ValueZat  *int64   `json:"value_zat,omitempty"`
+	Opaque    bool     `json:"opaque"`
+}
+
+// InspectOutputs decodes rawTxHex (decoderawtransaction) into its outputs:
+// transparent vouts first, in order, then sapling outputs, orchard actions
+// and sprout joinsplits, each indexed within its pool.
+func (c *Client) InspectOutputs(ctx context.Context, rawTxHex string) ([]TxOutput, error) {
+	raw, err := normalizeHex(rawTxHex)
+	if err != nil {
+		return nil, err
+	}
+	return c.inspectOutputs(ctx, c.rpc, raw)
+}
+
+func (c *Client) inspectOutputs(ctx context.Context, rpc RPC, raw string) ([]TxOutput, error) {
+	var decoded struct {
+		Vout            []decodedOutput   `json:"vout"`
+		VShieldedOutput []json.RawMessage `json:"vShieldedOutput"`
+		Orchard         *struct {
+			Actions []json.RawMessage `json:"actions"`
+		} `json:"orchard"`
+		VJoinSplit []json.RawMessage `json:"vjoinsplit"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return rpc.Call(ctx, "decoderawtransaction", []any{raw}, &decoded)
+	}); err != nil {
+		return nil, fmt.Errorf("broadcast: decoderawtransaction: %w", err)
+	}
+
+	outs := make([]TxOutput, 0, len(decoded.Vout)+len(decoded.VShieldedOutput))
+	for _, o := range decoded.Vout {
+		out := TxOutput{Pool: PoolTransparent, Index: o.N, Type: o.ScriptPubKey.Type, Addresses: o.addresses(), Value: o.Value.String()}
+		if zat, err := o.zatoshis(); err == nil {
+			out.ValueZat = &zat
+		}
+		outs = append(outs, out)
+	}
+	for i := range decoded.VShieldedOutput {
+		outs = append(outs, TxOutput{Pool: PoolSapling, Index: i, Opaque: true})
+	}
+	if decoded.Orchard != nil {
+		for i := range decoded.Orchard.Actions {
+			outs = append(outs, TxOutput{Pool: PoolOrchard, Index: i, Opaque: true})
+		}
+	}
+	for i := range decoded.VJoinSplit {
+		outs = append(outs, TxOutput{Pool: PoolSprout, Index: i, Opaque: true})
+	}
+	return outs, nil
+}
+
+// transparentOutputs filters outs down to the transparent ones.
+func transparentOutputs(outs []TxOutput) []TxOutput {
+	var t []TxOutput
+	for _, o := range outs {
+		if o.Pool == PoolTransparent {
+			t = append(t, o)
+		}
+	}
+	return t
+}
@@ -0,0 +1,97 @@
+package broadcast
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrNotJSONRPC = errors.New("broadcast: rpc endpoint did not answer with JSON-RPC (check the rpc url)")
+
+// NotJSONRPCError is returned when the RPC endpoint answers with something
+// other than JSON, typically an HTML page from a misconfigured proxy. Snippet
+// is the start of the body with whitespace collapsed and control characters
+// removed.
+type NotJSONRPCError struct {
+	StatusCode  int
+	ContentType string
+	Snippet     string
+}
+
+func (e *NotJSONRPCError) Error() string {
+	return fmt.Sprintf("%s: http %d, content-type %q: %s", ErrNotJSONRPC, e.StatusCode, e.ContentType, e.Snippet)
+}
+
+func (e *NotJSONRPCError) Unwrap() error { return ErrNotJSONRPC }
+
+const maxSnippetBytes = 120
+
+// jsonRPCTransport turns non-JSON responses into a NotJSONRPCError. 401/403
+// and 5xx responses are passed through so auth failures and transient
+// gateway errors keep their usual handling, as are responses without a
+// Content-Type and mislabeled ones whose body still starts like JSON.
+type jsonRPCTransport struct {
+	next   http.RoundTripper
+	redact func(string) string
+}
+
+func (t jsonRPCTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	ct := resp.Header.Get("Content-Type")
+	if ct == "" || isJSONContentType(ct) ||
+		resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode >= 500 {
+		return resp, nil
+	}
+
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if trimmed := bytes.TrimSpace(head); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	snippet := sanitizeSnippet(string(head))
+	if t.redact != nil {
+		snippet = t.redact(snippet)
+	}
+	return nil, &NotJSONRPCError{StatusCode: resp.StatusCode, ContentType: ct, Snippet: snippet}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func isJSONContentType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json") || strings.HasSuffix(mt, "/json-rpc")
+}
+
+func sanitizeSnippet(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (unicode.IsControl(r) && !unicode.IsSpace(r)) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= maxSnippetBytes {
+		return s
+	}
+	cut := maxSnippetBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,80 @@
+package broadcast
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+)
+
+var ErrResponseTooLarge = errors.New("broadcast: rpc response exceeds the size limit")
+
+// DefaultMaxResponseBytes is the response size limit WithRPCTransport
+// applies: one byte under the 8 MiB the junocashd client reads, so larger
+// bodies fail with ErrResponseTooLarge instead of being cut short.
+const DefaultMaxResponseBytes = 8<<20 - 1
+
+// WithMaxResponseBytes is a junocashd option that fails any RPC whose response
+// body is larger than n bytes with ErrResponseTooLarge, so a broken or hostile
+// endpoint cannot make the process buffer an unbounded body. n <= 0 means
+// DefaultMaxResponseBytes. Use WithRPCTransportLimit to combine it with a
+// bearer token or SOCKS5 proxy. The junocashd client itself stops reading at
+// 8 MiB, so with larger limits a body without Content-Length that passes
+// 8 MiB fails to decode instead of failing with ErrResponseTooLarge.
+func WithMaxResponseBytes(n int64) junocashd.Option {
+	return WithRPCTransportLimit("", "", n)
+}
+
+// limitTransport enforces maxResponseBytes, up front from Content-Length when
+// the server sends one and otherwise while the body is read.
+type limitTransport struct {
+	next  http.RoundTripper
+	limit int64
+}
+
+func (t limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ContentLength > t.limit {
+		_ = resp.Body.Close()
+		return nil, t.tooLarge()
+	}
+	resp.Body = &limitedBody{r: resp.Body, left: t.limit, err: t.tooLarge()}
+	return resp, nil
+}
+
+func (t limitTransport) tooLarge() error {
+	return fmt.Errorf("%w (%d bytes)", ErrResponseTooLarge, t.limit)
+}
+
+// limitedBody is io.LimitReader that reports reading past the limit as an
+// error instead of a silent EOF.
+type limitedBody struct {
+	r    io.ReadCloser
+	left int64
+	err  error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.left <= 0 {
+		// Probe for one more byte to tell a body of exactly the limit
+		// from a longer one.
+		var one [1]byte
+		if n, _ := b.r.Read(one[:]); n > 0 {
+			return 0, b.err
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := b.r.Read(p)
+	b.left -= int64(n)
+	return n, err
+}
+
+func (b *limitedBody) Close() error { return b.r.Close() }
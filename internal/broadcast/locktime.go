@@ -0,0 +1,77 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// locktimeThreshold separates block-height locktimes (below) from unix-time
+// locktimes (at or above).
+const locktimeThreshold = 500_000_000
+
+const sequenceFinal = 0xffffffff
+
+var ErrLocktimeNotMet = errors.New("broadcast: transaction locktime is not yet satisfied")
+
+// WithRespectLocktime makes every submit decode the tx first and refuse it
+// with ErrLocktimeNotMet, without broadcasting, while it cannot be mined in
+// the next block: a height locktime must be below the next block's height,
+// and a time locktime below the tip's median time past. Txs whose inputs all
+// have a final sequence ignore their locktime, as in consensus.
+func WithRespectLocktime(enabled bool) Option {
+	return func(c *Client) {
+		c.respectLocktime = enabled
+	}
+}
+
+func (c *Client) checkLocktime(ctx context.Context, rpc RPC, raw string) error {
+	if !c.respectLocktime {
+		return nil
+	}
+	var decoded struct {
+		Locktime uint32 `json:"locktime"`
+		Vin      []struct {
+			Sequence uint32 `json:"sequence"`
+		} `json:"vin"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return rpc.Call(ctx, "decoderawtransaction", []any{raw}, &decoded)
+	}); err != nil {
+		return fmt.Errorf("broadcast: decoderawtransaction: %w", err)
+	}
+	if decoded.Locktime == 0 {
+		return nil
+	}
+	final := true
+	for _, in := range decoded.Vin {
+		if in.Sequence != sequenceFinal {
+			final = false
+			break
+		}
+	}
+	if final {
+		return nil
+	}
+
+	var info struct {
+		Blocks     int64 `json:"blocks"`
+		MedianTime int64 `json:"mediantime"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return rpc.Call(ctx, "getblockchaininfo", nil, &info)
+	}); err != nil {
+		return fmt.Errorf("broadcast: getblockchaininfo: %w", err)
+	}
+	lock := int64(decoded.Locktime)
+	if lock < locktimeThreshold {
+		if next := info.Blocks + 1; lock >= next {
+			return fmt.Errorf("%w: locktime is height %d, the next block is %d (%d block(s) to go)", ErrLocktimeNotMet, lock, next, lock-next+1)
+		}
+		return nil
+	}
+	if lock >= info.MedianTime {
+		return fmt.Errorf("%w: locktime is time %d, the tip's median time is %d", ErrLocktimeNotMet, lock, info.MedianTime)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+type MempoolInfo struct {
+	Size  int64  `json:"size"`
+	Bytes *int64 `json:"bytes,omitempty"`
+	Usage *int64 `json:"usage,omitempty"`
+}
+
+func (c *Client) Mempool(ctx context.Context) ([]string, error) {
+	ids, err := c.rawMempool(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		ids[i] = strings.ToLower(strings.TrimSpace(id))
+	}
+	return ids, nil
+}
+
+// MempoolInfo uses getmempoolinfo, falling back to counting getrawmempool on
+// nodes without it (Bytes and Usage are then unknown).
+func (c *Client) MempoolInfo(ctx context.Context) (MempoolInfo, error) {
+	var info struct {
+		Size  int64 `json:"size"`
+		Bytes int64 `json:"bytes"`
+		Usage int64 `json:"usage"`
+	}
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getmempoolinfo", nil, &info)
+	})
+	if err == nil {
+		return MempoolInfo{Size: info.Size, Bytes: &info.Bytes, Usage: &info.Usage}, nil
+	}
+	if !isMethodNotFoundErr(err) {
+		return MempoolInfo{}, fmt.Errorf("broadcast: getmempoolinfo: %w", err)
+	}
+
+	ids, err := c.rawMempool(ctx)
+	if err != nil {
+		return MempoolInfo{}, err
+	}
+	return MempoolInfo{Size: int64(len(ids))}, nil
+}
+
+// MempoolFeeRate returns the fee rate, in zatoshis per virtual byte, at which
+// the node holds txid in its mempool (getmempoolentry). The fee is read from
+// fees.base when reported, else fee; the size from vsize, else size. Txs not
+// in the mempool return a not-found error.
+func (c *Client) MempoolFeeRate(ctx context.Context, txid string) (float64, error) {
+	txid, ok := normalizeTxID(txid)
+	if !ok {
+		return 0, errInvalidTxID
+	}
+
+	var entry struct {
+		Size  int64    `json:"size"`
+		VSize int64    `json:"vsize"`
+		Fee   *float64 `json:"fee"`
+		Fees  *struct {
+			Base float64 `json:"base"`
+		} `json:"fees"`
+	}
+	if err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getmempoolentry", []any{txid}, &entry)
+	}); err != nil {
+		return 0, fmt.Errorf("broadcast: getmempoolentry: %w", err)
+	}
+
+	var fee float64
+	switch {
+	case entry.Fees != nil:
+		fee = entry.Fees.Base
+	case entry.Fee != nil:
+		fee = *entry.Fee
+	default:
+		return 0, errors.New("broadcast: getmempoolentry did not report a fee")
+	}
+	size := entry.VSize
+	if size <= 0 {
+		size = entry.Size
+	}
+	if size <= 0 {
+		return 0, errors.New("broadcast: getmempoolentry did not report a size")
+	}
+	return math.Round(fee*1e8) / float64(size), nil
+}
+
+var ErrNotInMempool = errors.New("broadcast: transaction is not in the mempool")
+
+// MempoolEntry is a mempool tx with its CPFP package: the in-mempool
+// ancestors it depends on and the descendants that depend on it, each count,
+// size, and fee total including the tx itself. Fees are in coins; sizes are
+// virtual sizes where the node reports them. The ancestor fields are nil on
+// nodes that track only descendants; Depends still lists the direct parents.
+type MempoolEntry struct {
+	TxID            string   `json:"txid"`
+	Size            int64    `json:"size"`
+	Fee             float64  `json:"fee"`
+	ModifiedFee     float64  `json:"modified_fee"`
+	Time            int64    `json:"time"`
+	Height          int64    `json:"height"`
+	AncestorCount   *int64   `json:"ancestor_count,omitempty"`
+	AncestorSize    *int64   `json:"ancestor_size,omitempty"`
+	AncestorFees    *float64 `json:"ancestor_fees,omitempty"`
+	DescendantCount int64    `json:"descendant_count"`
+	DescendantSize  int64    `json:"descendant_size"`
+	DescendantFees  float64  `json:"descendant_fees"`
+	Depends         []string `json:"depends"`
+}
+
+// MempoolEntry returns txid's getmempoolentry. Fees come from the fees object
+// when reported, else from fee/modifiedfee (coins) and
+// ancestorfees/descendantfees (zatoshis). Txs not in the mempool fail with
+// ErrNotInMempool.
+func (c *Client) MempoolEntry(ctx context.Context, txid string) (MempoolEntry, error) {
+	txid, ok := normalizeTxID(txid)
+	if !ok {
+		return MempoolEntry{}, errInvalidTxID
+	}
+
+	var entry struct {
+		Size            int64    `json:"size"`
+		VSize           int64    `json:"vsize"`
+		Fee             float64  `json:"fee"`
+		ModifiedFee     *float64 `json:"modifiedfee"`
+		Time            int64    `json:"time"`
+		Height          int64    `json:"height"`
+		AncestorCount   *int64   `json:"ancestorcount"`
+		AncestorSize    *int64   `json:"ancestorsize"`
+		AncestorFees    *float64 `json:"ancestorfees"`
+		DescendantCount int64    `json:"descendantcount"`
+		DescendantSize  int64    `json:"descendantsize"`
+		DescendantFees  float64  `json:"descendantfees"`
+		Depends         []string `json:"depends"`
+		Fees            *struct {
+			Base       float64 `json:"base"`
+			Modified   float64 `json:"modified"`
+			Ancestor   float64 `json:"ancestor"`
+			Descendant float64 `json:"descendant"`
+		} `json:"fees"`
+	}
+	if err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isNotInMempoolErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getmempoolentry", []any{txid}, &entry)
+	}); err != nil {
+		if isNotInMempoolErr(err) {
+			return MempoolEntry{}, fmt.Errorf("%w: %s", ErrNotInMempool, txid)
+		}
+		return MempoolEntry{}, fmt.Errorf("broadcast: getmempoolentry: %w", err)
+	}
+
+	e := MempoolEntry{
+		TxID:            txid,
+		Size:            entry.VSize,
+		Fee:             entry.Fee,
+		ModifiedFee:     entry.Fee,
+		Time:            entry.Time,
+		Height:          entry.Height,
+		AncestorCount:   entry.AncestorCount,
+		AncestorSize:    entry.AncestorSize,
+		DescendantCount: entry.DescendantCount,
+		DescendantSize:  entry.DescendantSize,
+		DescendantFees:  entry.DescendantFees / 1e8,
+		Depends:         make([]string, 0, len(entry.Depends)),
+	}
+	if e.Size <= 0 {
+		e.Size = entry.Size
+	}
+	if entry.ModifiedFee != nil {
+		e.ModifiedFee = *entry.ModifiedFee
+	}
+	if entry.AncestorFees != nil {
+		fees := *entry.AncestorFees / 1e8
+		e.AncestorFees = &fees
+	}
+	if f := entry.Fees; f != nil {
+		e.Fee, e.ModifiedFee, e.DescendantFees = f.Base, f.Modified, f.Descendant
+		if entry.AncestorCount != nil {
+			e.AncestorFees = &f.Ancestor
+		}
+	}
+	for _, d := range entry.Depends {
+		e.Depends = append(e.Depends, strings.ToLower(strings.TrimSpace(d)))
+	}
+	return e, nil
+}
+
+// isNotInMempoolErr reports getmempoolentry's "Transaction not in mempool".
+func isNotInMempoolErr(err error) bool {
+	return isNotFoundErr(err) || strings.Contains(strings.ToLower(err.Error()), "not in mempool")
+}
+
+func (c *Client) rawMempool(ctx context.Context) ([]string, error) {
+	var mempool []string
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getrawmempool", []any{false}, &mempool)
+	}); err != nil {
+		return nil, fmt.Errorf("broadcast: getrawmempool: %w", err)
+	}
+	return mempool, nil
+}
@@ -0,0 +1,193 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type namedRPC struct {
+	RPC
+	name string
+}
+
+func (n namedRPC) Name() string { return n.name }
+
+// NamedRPC attaches a display name (e.g. a redacted endpoint URL) used to key
+// per-endpoint results in the multi-node helpers.
+func NamedRPC(name string, rpc RPC) RPC {
+	return namedRPC{RPC: rpc, name: name}
+}
+
+func rpcName(rpc RPC, i int) string {
+	if n, ok := rpc.(interface{ Name() string }); ok && n.Name() != "" {
+		return n.Name()
+	}
+	return fmt.Sprintf("rpc[%d]", i)
+}
+
+// EndpointError is returned per failing endpoint by the multi-node helpers.
+type EndpointError struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *EndpointError) Error() string { return e.Endpoint + ": " + e.Err.Error() }
+
+func (e *EndpointError) Unwrap() error { return e.Err }
+
+// wrapEndpoint applies the RPC wrappers New installs on the primary RPC,
+// except auto-reconnect, to another endpoint.
+func (c *Client) wrapEndpoint(rpc RPC) RPC {
+	rpc = authRPC{next: rpc}
+	if c.readOnly {
+		rpc = readOnlyRPC{next: rpc}
+	}
+	if c.tracer != nil {
+		rpc = tracingRPC{next: rpc, tracer: c.tracer}
+	}
+	if c.attemptLog != nil {
+		rpc = attemptLogRPC{next: rpc, log: c.attemptLog}
+	}
+	return rpc
+}
+
+// SubmitToAll broadcasts rawTxHex to every rpc concurrently using the same
+// validation and retry logic as Submit. It returns the txid reported by each
+// endpoint that accepted the tx, keyed by endpoint name, plus one error per
+// endpoint that did not; the broadcast succeeded if the map is non-empty.
+func (c *Client) SubmitToAll(ctx context.Context, rawTxHex string, rpcs []RPC) (map[string]string, []error) {
+	if len(rpcs) == 0 {
+		return nil, []error{errors.New("broadcast: no rpc endpoints")}
+	}
+	ctx, end := c.startSpan(ctx, "broadcast.SubmitToAll")
+
+	type result struct {
+		name string
+		txid string
+		err  error
+	}
+	results := make([]result, len(rpcs))
+
+	var wg sync.WaitGroup
+	for i, rpc := range rpcs {
+		results[i].name = rpcName(rpc, i)
+		rpc = c.wrapEndpoint(rpc)
+		wg.Add(1)
+		go func(i int, rpc RPC) {
+			defer wg.Done()
+			results[i].txid, results[i].err = c.submit(ctx, rpc, rawTxHex)
+		}(i, rpc)
+	}
+	wg.Wait()
+
+	accepted := make(map[string]string, len(rpcs))
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, &EndpointError{Endpoint: r.name, Err: r.err})
+			continue
+		}
+		accepted[r.name] = r.txid
+	}
+
+	var endErr error
+	if len(accepted) == 0 {
+		endErr = errors.New("broadcast: no endpoint accepted the transaction")
+	}
+	end(endErr)
+	for _, r := range results {
+		if r.err == nil {
+			c.notify("submitted", TxStatus{TxID: r.txid, InMempool: true})
+			break
+		}
+	}
+	return accepted, errs
+}
+
+// WaitForConfirmationsAcross is WaitForConfirmations run against every rpc
+// concurrently: it returns the first confirmed status any endpoint reports
+// and stops the other waits, so a node that lags on block propagation does
+// not hold up the result. Each endpoint is polled with the client's options
+// except the status cache, ZMQ, and auto-reconnect, which belong to the
+// primary RPC. The confirmed webhook event fires once, for the winner.
+//
+// The wait fails only when every endpoint has failed. If any of them timed
+// out, the *WaitTimeoutError with the most confirmations seen is returned;
+// otherwise the errors are joined, one *EndpointError per endpoint.
+func (c *Client) WaitForConfirmationsAcross(ctx context.Context, txid string, confirmations int64, rpcs []RPC) (TxStatus, error) {
+	if len(rpcs) == 0 {
+		return TxStatus{}, errors.New("broadcast: no rpc endpoints")
+	}
+	ctx, end := c.startSpan(ctx, "broadcast.WaitForConfirmationsAcross")
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		name string
+		st   TxStatus
+		err  error
+	}
+	results := make(chan result, len(rpcs))
+	for i, rpc := range rpcs {
+		e := c.endpointClient(rpc)
+		name := rpcName(rpc, i)
+		go func() {
+			st, err := e.wait(waitCtx, txid, confirmations, nil)
+			results <- result{name: name, st: st, err: err}
+		}()
+	}
+
+	var errs []error
+	var timeout *WaitTimeoutError
+	for range rpcs {
+		r := <-results
+		if r.err == nil {
+			cancel()
+			end(nil)
+			return c.confirmed(r.st), nil
+		}
+		var te *WaitTimeoutError
+		if errors.As(r.err, &te) && (timeout == nil || te.LastStatus.Confirmations > timeout.LastStatus.Confirmations) {
+			timeout = te
+		}
+		errs = append(errs, &EndpointError{Endpoint: r.name, Err: r.err})
+	}
+
+	err := errors.Join(errs...)
+	if timeout != nil {
+		err = timeout
+	}
+	end(err)
+	if c.returnLastOnCancel && timeout != nil {
+		return timeout.LastStatus, err
+	}
+	return TxStatus{}, err
+}
+
+// endpointClient returns a client that waits against rpc with c's polling,
+// retry, and confirmation options.
+func (c *Client) endpointClient(rpc RPC) *Client {
+	return &Client{
+		rpc:                c.wrapEndpoint(rpc),
+		pollInterval:       c.pollInterval,
+		chainLookback:      c.chainLookback,
+		retry:              c.retry,
+		returnLastOnCancel: c.returnLastOnCancel,
+		retryMatchers:      c.retryMatchers,
+		tracer:             c.tracer,
+		requireSynced:      c.requireSynced,
+		requireTxindex:     c.requireTxindex,
+		immediatePoll:      c.immediatePoll,
+		maxPolls:           c.maxPolls,
+		settlePolls:        c.settlePolls,
+		confirmationBase:   c.confirmationBase,
+		maxMempoolScan:     c.maxMempoolScan,
+		verifyBestChain:    c.verifyBestChain,
+		tipGatedPolling:    c.tipGatedPolling,
+		skipTxIDValidation: c.skipTxIDValidation,
+		attemptLog:         c.attemptLog,
+		closed:             make(chan struct{}),
+	}
+}
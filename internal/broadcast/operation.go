@@ -0,0 +1,120 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOperationNotFound = errors.New("broadcast: unknown async operation id")
+	ErrOperationFailed   = errors.New("broadcast: async operation failed")
+)
+
+// OperationResult is the state of a wallet async operation (z_sendmany,
+// z_shieldcoinbase, ...). Status is queued, executing, success, failed, or
+// cancelled; TxID is set on success and Error on failure.
+type OperationResult struct {
+	OpID          string  `json:"opid"`
+	Status        string  `json:"status"`
+	TxID          string  `json:"txid,omitempty"`
+	Error         string  `json:"error,omitempty"`
+	ExecutionSecs float64 `json:"execution_secs,omitempty"`
+}
+
+// Done reports whether the operation has finished, successfully or not.
+func (r OperationResult) Done() bool {
+	switch r.Status {
+	case "success", "failed", "cancelled":
+		return true
+	}
+	return false
+}
+
+// Operation returns the current state of opid from z_getoperationstatus,
+// which, unlike z_getoperationresult, leaves finished operations in the
+// node's list. Unknown ids fail with ErrOperationNotFound.
+func (c *Client) Operation(ctx context.Context, opid string) (OperationResult, error) {
+	return c.operation(ctx, "z_getoperationstatus", opid)
+}
+
+// WaitForOperation polls opid every poll interval until it finishes. A
+// successful operation returns its txid; failed or cancelled ones return the
+// last state with ErrOperationFailed. Once finished, the operation is cleared
+// from the node's list with z_getoperationresult, best-effort, unless the
+// client is read-only.
+func (c *Client) WaitForOperation(ctx context.Context, opid string) (OperationResult, error) {
+	var tick <-chan time.Time
+	if !c.immediatePoll {
+		ticker := time.NewTicker(c.pollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		res, err := c.Operation(ctx, opid)
+		if err != nil {
+			return res, err
+		}
+		if res.Done() {
+			if !c.readOnly {
+				if final, err := c.operation(ctx, "z_getoperationresult", opid); err == nil {
+					res = final
+				}
+			}
+			if res.Status != "success" {
+				return res, fmt.Errorf("%w: %s: %s", ErrOperationFailed, res.Status, res.Error)
+			}
+			return res, nil
+		}
+		if tick == nil {
+			if err := ctx.Err(); err != nil {
+				return res, err
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-tick:
+		}
+	}
+}
+
+func (c *Client) operation(ctx context.Context, method, opid string) (OperationResult, error) {
+	opid = strings.TrimSpace(opid)
+	if opid == "" {
+		return OperationResult{}, errors.New("broadcast: opid is required")
+	}
+	var ops []struct {
+		ID            string  `json:"id"`
+		Status        string  `json:"status"`
+		ExecutionSecs float64 `json:"execution_secs"`
+		Result        *struct {
+			TxID string `json:"txid"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, method, []any{[]string{opid}}, &ops)
+	}); err != nil {
+		return OperationResult{}, fmt.Errorf("broadcast: %s: %w", method, err)
+	}
+	for _, op := range ops {
+		if op.ID != opid {
+			continue
+		}
+		res := OperationResult{OpID: opid, Status: op.Status, ExecutionSecs: op.ExecutionSecs}
+		if op.Result != nil {
+			res.TxID = strings.ToLower(strings.TrimSpace(op.Result.TxID))
+		}
+		if op.Error != nil {
+			res.Error = op.Error.Message
+		}
+		return res, nil
+	}
+	return OperationResult{}, fmt.Errorf("%w: %s", ErrOperationNotFound, opid)
+}
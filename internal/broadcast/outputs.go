@@ -0,0 +1,153 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrOutputMismatch = errors.New("broadcast: transaction outputs do not match the expected outputs")
+
+// ExpectedOutput is an output a tx must contain: Amount zatoshis paid to
+// Address.
+type ExpectedOutput struct {
+	Address string
+	Amount  int64
+}
+
+// OutputMismatchError lists why the tx failed WithExpectedOutputs: expected
+// outputs it lacks, and with exact matching, outputs it has beyond them.
+type OutputMismatchError struct {
+	Problems []string
+}
+
+func (e *OutputMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrOutputMismatch, strings.Join(e.Problems, "; "))
+}
+
+func (e *OutputMismatchError) Unwrap() error { return ErrOutputMismatch }
+
+// WithExpectedOutputs makes every submit decode the tx first and refuse it
+// with ErrOutputMismatch unless, for each expected output, a distinct
+// transparent output pays exactly that amount to that address. With exact,
+// the tx must have no other outputs (change, OP_RETURN), and since shielded
+// outputs are opaque (see TxOutput) and cannot be matched, a tx with any is
+// refused unless WithAllowShieldedOutputs is set. Shielded outputs never
+// satisfy an expectation.
+func WithExpectedOutputs(outs []ExpectedOutput, exact bool) Option {
+	return func(c *Client) {
+		c.expectedOutputs = outs
+		c.exactOutputs = exact
+	}
+}
+
+// ParseAmount parses a decimal coin amount (at most 8 decimal places) into
+// zatoshis without going through floating point.
+func ParseAmount(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || strings.HasPrefix(whole, "-") || strings.HasPrefix(whole, "+") || len(frac) > 8 {
+		return 0, fmt.Errorf("broadcast: invalid amount %q", s)
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w > 21_000_000 {
+		return 0, fmt.Errorf("broadcast: invalid amount %q", s)
+	}
+	var f int64
+	if frac != "" {
+		f, err = strconv.ParseInt(frac+strings.Repeat("0", 8-len(frac)), 10, 64)
+		if err != nil || strings.HasPrefix(frac, "-") || strings.HasPrefix(frac, "+") {
+			return 0, fmt.Errorf("broadcast: invalid amount %q", s)
+		}
+	}
+	return w*100_000_000 + f, nil
+}
+
+func formatAmount(zat int64) string {
+	return fmt.Sprintf("%d.%08d", zat/100_000_000, zat%100_000_000)
+}
+
+// decodedOutput is one transparent output from decoderawtransaction.
+type decodedOutput struct {
+	N            int         `json:"n"`
+	Value        json.Number `json:"value"`
+	ValueZat     *int64      `json:"valueZat"`
+	ScriptPubKey struct {
+		Type      string   `json:"type"`
+		Address   string   `json:"address"`
+		Addresses []string `json:"addresses"`
+	} `json:"scriptPubKey"`
+}
+
+// addresses returns every address the node derived for the output.
+func (o decodedOutput) addresses() []string {
+	addrs := o.ScriptPubKey.Addresses
+	if o.ScriptPubKey.Address != "" {
+		addrs = append(addrs, o.ScriptPubKey.Address)
+	}
+	return addrs
+}
+
+// zatoshis prefers the node's valueZat and otherwise parses value exactly.
+func (o decodedOutput) zatoshis() (int64, error) {
+	if o.ValueZat != nil {
+		return *o.ValueZat, nil
+	}
+	return ParseAmount(o.Value.String())
+}
+
+func (c *Client) checkExpectedOutputs(ctx context.Context, rpc RPC, raw string) error {
+	if len(c.expectedOutputs) == 0 {
+		return nil
+	}
+	all, err := c.inspectOutputs(ctx, rpc, raw)
+	if err != nil {
+		return err
+	}
+	outs := transparentOutputs(all)
+	shielded := len(all) - len(outs)
+
+	used := make([]bool, len(outs))
+	var problems []string
+	for _, want := range c.expectedOutputs {
+		found := false
+		for i, out := range outs {
+			if used[i] {
+				continue
+			}
+			if out.ValueZat == nil || len(out.Addresses) != 1 || out.Addresses[0] != want.Address || *out.ValueZat != want.Amount {
+				continue
+			}
+			used[i], found = true, true
+			break
+		}
+		if !found {
+			p := fmt.Sprintf("missing %s to %s", formatAmount(want.Amount), want.Address)
+			if shielded > 0 {
+				p += fmt.Sprintf(" (the tx's %d shielded output(s) cannot be checked)", shielded)
+			}
+			problems = append(problems, p)
+		}
+	}
+	if c.exactOutputs {
+		for i, out := range outs {
+			if !used[i] {
+				problems = append(problems, fmt.Sprintf("unexpected vout %d (%s %s)", out.Index, out.Value, strings.Join(out.Addresses, ",")))
+			}
+		}
+		if !c.allowShielded {
+			for _, out := range all {
+				if out.Opaque {
+					problems = append(problems, fmt.Sprintf("unexpected %s output %d (shielded)", out.Pool, out.Index))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &OutputMismatchError{Problems: problems}
+	}
+	return nil
+}
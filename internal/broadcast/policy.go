@@ -0,0 +1,58 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+)
+
+// MempoolPolicy holds the node's fee thresholds, in coins per kB as junocashd
+// reports them. Fields the node does not report are nil.
+type MempoolPolicy struct {
+	MempoolMinFee       *float64 `json:"mempoolminfee,omitempty"`
+	MinRelayTxFee       *float64 `json:"minrelaytxfee,omitempty"`
+	IncrementalRelayFee *float64 `json:"incrementalrelayfee,omitempty"`
+}
+
+// MempoolPolicy reads the fee policy from getmempoolinfo, filling in what it
+// lacks from getnetworkinfo (relayfee, incrementalfee). Nodes without
+// getmempoolinfo are queried through getnetworkinfo alone.
+func (c *Client) MempoolPolicy(ctx context.Context) (MempoolPolicy, error) {
+	var pool struct {
+		MempoolMinFee       *float64 `json:"mempoolminfee"`
+		MinRelayTxFee       *float64 `json:"minrelaytxfee"`
+		IncrementalRelayFee *float64 `json:"incrementalrelayfee"`
+	}
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getmempoolinfo", nil, &pool)
+	})
+	if err != nil && !isMethodNotFoundErr(err) {
+		return MempoolPolicy{}, fmt.Errorf("broadcast: getmempoolinfo: %w", err)
+	}
+	p := MempoolPolicy{
+		MempoolMinFee:       pool.MempoolMinFee,
+		MinRelayTxFee:       pool.MinRelayTxFee,
+		IncrementalRelayFee: pool.IncrementalRelayFee,
+	}
+	if p.MinRelayTxFee != nil && p.IncrementalRelayFee != nil {
+		return p, nil
+	}
+
+	var network struct {
+		RelayFee       *float64 `json:"relayfee"`
+		IncrementalFee *float64 `json:"incrementalfee"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getnetworkinfo", nil, &network)
+	}); err != nil {
+		return MempoolPolicy{}, fmt.Errorf("broadcast: getnetworkinfo: %w", err)
+	}
+	if p.MinRelayTxFee == nil {
+		p.MinRelayTxFee = network.RelayFee
+	}
+	if p.IncrementalRelayFee == nil {
+		p.IncrementalRelayFee = network.IncrementalFee
+	}
+	return p, nil
+}
@@ -0,0 +1,35 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Prioritise calls prioritisetransaction to add feeDeltaSat (which may be
+// negative) to the fee the node's block template credits txid with. The delta
+// only affects local mining and accumulates across calls, so the RPC is sent
+// once and never retried.
+func (c *Client) Prioritise(ctx context.Context, txid string, feeDeltaSat int64) error {
+	if c.readOnly {
+		return readOnlyErr("prioritisetransaction")
+	}
+	txid, valid := normalizeTxID(txid)
+	if !valid {
+		return errInvalidTxID
+	}
+
+	// The second parameter is zcashd's priority delta (a dummy on newer
+	// nodes); only the fee delta is used.
+	var ok bool
+	if err := c.rpc.Call(ctx, "prioritisetransaction", []any{txid, 0, feeDeltaSat}, &ok); err != nil {
+		if isMethodNotFoundErr(err) {
+			return fmt.Errorf("%w: prioritisetransaction", ErrMethodUnsupported)
+		}
+		return fmt.Errorf("broadcast: prioritisetransaction: %w", err)
+	}
+	if !ok {
+		return errors.New("broadcast: prioritisetransaction: node returned false")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WaitForPropagation polls every rpc until at least k of them know txid, in
+// the mempool or on chain, so a broadcast is only trusted once the tx is
+// spreading. Each round asks only the nodes that have not seen it yet, using
+// Status with the client's options as in WaitForConfirmationsAcross; a node
+// whose lookup fails counts as not having seen it and is asked again next
+// round. When ctx ends first, the error says how many nodes saw the tx and
+// wraps ctx's error.
+func (c *Client) WaitForPropagation(ctx context.Context, txid string, rpcs []RPC, k int) error {
+	if k < 1 || k > len(rpcs) {
+		return fmt.Errorf("broadcast: propagation needs 1 to %d nodes, got %d", len(rpcs), k)
+	}
+	ctx, end := c.startSpan(ctx, "broadcast.WaitForPropagation")
+
+	clients := make([]*Client, len(rpcs))
+	for i, rpc := range rpcs {
+		clients[i] = c.endpointClient(rpc)
+	}
+	seen := make([]bool, len(rpcs))
+	count := 0
+	for polls := 1; ; polls++ {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for i, e := range clients {
+			if seen[i] {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, found, err := e.Status(ctx, txid); err == nil && found {
+					mu.Lock()
+					seen[i] = true
+					count++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if count >= k {
+			end(nil)
+			return nil
+		}
+
+		if c.maxPolls > 0 && polls >= c.maxPolls {
+			err := fmt.Errorf("broadcast: tx seen by %d of %d required nodes: %w (%d)", count, k, ErrMaxPollsExceeded, c.maxPolls)
+			end(err)
+			return err
+		}
+		wait := c.pollInterval
+		if c.immediatePoll {
+			wait = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err := fmt.Errorf("broadcast: tx seen by %d of %d required nodes: %w", count, k, ctx.Err())
+			end(err)
+			return err
+		case <-timer.C:
+		}
+	}
+}
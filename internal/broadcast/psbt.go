@@ -0,0 +1,132 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var psbtMagic = []byte("psbt\xff")
+
+var (
+	ErrInvalidPSBT    = errors.New("broadcast: invalid psbt")
+	ErrPSBTIncomplete = errors.New("broadcast: psbt is not fully signed")
+)
+
+func normalizePSBT(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidPSBT)
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: not base64", ErrInvalidPSBT)
+	}
+	if !bytes.HasPrefix(b, psbtMagic) {
+		return "", fmt.Errorf("%w: missing psbt magic bytes", ErrInvalidPSBT)
+	}
+	return s, nil
+}
+
+// DecodePSBT returns the node's decodepsbt result for psbtBase64 as-is.
+func (c *Client) DecodePSBT(ctx context.Context, psbtBase64 string) (json.RawMessage, error) {
+	psbt, err := normalizePSBT(psbtBase64)
+	if err != nil {
+		return nil, err
+	}
+	var decoded json.RawMessage
+	err = doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "decodepsbt", []any{psbt}, &decoded)
+	})
+	if isMethodNotFoundErr(err) {
+		return nil, fmt.Errorf("%w: decodepsbt", ErrMethodUnsupported)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("broadcast: decodepsbt: %w", err)
+	}
+	return decoded, nil
+}
+
+// PSBTIncompleteError is returned by SubmitPSBT when finalizepsbt reports the
+// PSBT incomplete. Inputs lists the unfinalized input indexes when known.
+type PSBTIncompleteError struct {
+	Inputs []int
+}
+
+func (e *PSBTIncompleteError) Error() string {
+	if len(e.Inputs) == 0 {
+		return ErrPSBTIncomplete.Error()
+	}
+	idx := make([]string, len(e.Inputs))
+	for i, n := range e.Inputs {
+		idx[i] = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("%s (unfinalized inputs: %s)", ErrPSBTIncomplete, strings.Join(idx, ", "))
+}
+
+func (e *PSBTIncompleteError) Unwrap() error { return ErrPSBTIncomplete }
+
+// SubmitPSBT finalizes psbtBase64 with finalizepsbt and submits the extracted
+// raw tx through Submit.
+func (c *Client) SubmitPSBT(ctx context.Context, psbtBase64 string) (string, error) {
+	if c.readOnly {
+		return "", readOnlyErr("sendrawtransaction")
+	}
+	psbt, err := normalizePSBT(psbtBase64)
+	if err != nil {
+		return "", err
+	}
+
+	var fin struct {
+		PSBT     string `json:"psbt"`
+		Hex      string `json:"hex"`
+		Complete bool   `json:"complete"`
+	}
+	err = doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "finalizepsbt", []any{psbt, true}, &fin)
+	})
+	if isMethodNotFoundErr(err) {
+		return "", fmt.Errorf("%w: finalizepsbt", ErrMethodUnsupported)
+	}
+	if err != nil {
+		return "", fmt.Errorf("broadcast: finalizepsbt: %w", err)
+	}
+	if !fin.Complete || strings.TrimSpace(fin.Hex) == "" {
+		return "", &PSBTIncompleteError{Inputs: c.unfinalizedInputs(ctx, fin.PSBT)}
+	}
+	return c.Submit(ctx, fin.Hex)
+}
+
+func (c *Client) unfinalizedInputs(ctx context.Context, psbt string) []int {
+	if strings.TrimSpace(psbt) == "" {
+		return nil
+	}
+	decoded, err := c.DecodePSBT(ctx, psbt)
+	if err != nil {
+		return nil
+	}
+	var d struct {
+		Inputs []map[string]json.RawMessage `json:"inputs"`
+	}
+	if err := json.Unmarshal(decoded, &d); err != nil {
+		return nil
+	}
+	var out []int
+	for i, in := range d.Inputs {
+		_, sig := in["final_scriptSig"]
+		_, wit := in["final_scriptwitness"]
+		if !sig && !wit {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RawTransaction returns the serialized tx as hex from non-verbose
+// getrawtransaction. Without -txindex the node only finds mempool and
+// wallet txs; unknown txids report found=false. With WithRequireTxindex the
+// node's -txindex hint fails with ErrTxindexRequired instead.
+func (c *Client) RawTransaction(ctx context.Context, txid string) (string, bool, error) {
+	txid, ok := normalizeTxID(txid)
+	if !ok {
+		return "", false, errInvalidTxID
+	}
+
+	var raw string
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getrawtransaction", []any{txid, 0}, &raw)
+	})
+	if err != nil {
+		if c.requireTxindex && isTxindexRequiredErr(err) {
+			return "", false, fmt.Errorf("%w: %w", ErrTxindexRequired, err)
+		}
+		if isNotFoundErr(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("broadcast: getrawtransaction: %w", err)
+	}
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if _, err := hex.DecodeString(raw); err != nil || raw == "" {
+		return "", false, errors.New("broadcast: node returned invalid raw tx hex")
+	}
+	return raw, true, nil
+}
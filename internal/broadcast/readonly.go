@@ -0,0 +1,60 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrReadOnly = errors.New("broadcast: client is read-only")
+
+// mutatingMethods are the RPCs WithReadOnly refuses to send.
+var mutatingMethods = map[string]bool{
+	"sendrawtransaction":    true,
+	"prioritisetransaction": true,
+	"bumpfee":               true,
+	"abandontransaction":    true,
+	// Removes the finished operation from the node's list.
+	"z_getoperationresult": true,
+}
+
+// WithReadOnly makes the client refuse every RPC that changes node or network
+// state (sendrawtransaction, prioritisetransaction, bumpfee,
+// abandontransaction, z_getoperationresult) with ErrReadOnly before anything
+// is sent. Submits fail before any of their preparatory RPCs; status lookups
+// and waits are unaffected, and WaitForOperation leaves finished operations
+// in the node's list.
+func WithReadOnly(enabled bool) Option {
+	return func(c *Client) {
+		c.readOnly = enabled
+	}
+}
+
+func readOnlyErr(method string) error {
+	return fmt.Errorf("%w: refusing to call %s", ErrReadOnly, method)
+}
+
+// readOnlyRPC refuses mutating methods without passing them on.
+type readOnlyRPC struct {
+	next RPC
+}
+
+func (r readOnlyRPC) Call(ctx context.Context, method string, params any, out any) error {
+	if mutatingMethods[method] {
+		return readOnlyErr(method)
+	}
+	return r.next.Call(ctx, method, params, out)
+}
+
+func (r readOnlyRPC) CallBatch(ctx context.Context, calls []BatchCall) error {
+	for _, call := range calls {
+		if mutatingMethods[call.Method] {
+			return readOnlyErr(call.Method)
+		}
+	}
+	return callBatch(ctx, r.next, calls)
+}
+
+func (r readOnlyRPC) SendRawTransaction(ctx context.Context, txHex string) (string, error) {
+	return "", readOnlyErr("sendrawtransaction")
+}
@@ -0,0 +1,148 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+)
+
+var ErrRejected = errors.New("broadcast: node would reject the transaction")
+
+// WithPrecheck makes every submit run testmempoolaccept first and fail with
+// ErrRejected, without calling sendrawtransaction, unless the node reports
+// the tx as allowed. Nodes without testmempoolaccept fail the submit with
+// ErrMethodUnsupported rather than skipping the check.
+func WithPrecheck(enabled bool) Option {
+	return func(c *Client) {
+		c.precheck = enabled
+	}
+}
+
+// AcceptResult is the node's testmempoolaccept verdict for a single tx.
+type AcceptResult struct {
+	TxID         string      `json:"txid"`
+	Allowed      bool        `json:"allowed"`
+	RejectReason string      `json:"reject-reason,omitempty"`
+	Fees         *AcceptFees `json:"fees,omitempty"`
+}
+
+// AcceptFees is the fee testmempoolaccept reports for an allowed tx, in coins.
+// Older nodes omit it.
+type AcceptFees struct {
+	Base float64 `json:"base"`
+}
+
+// TestAccept asks the node whether it would accept rawTxHex into its mempool,
+// without broadcasting it. Nodes without testmempoolaccept fail with
+// ErrMethodUnsupported.
+func (c *Client) TestAccept(ctx context.Context, rawTxHex string) (AcceptResult, error) {
+	raw, err := normalizeHex(rawTxHex)
+	if err != nil {
+		return AcceptResult{}, err
+	}
+	return c.testAccept(ctx, c.rpc, raw)
+}
+
+// TestAcceptBatch is TestAccept for several txs in one testmempoolaccept
+// call. The node evaluates them together, so a tx spending another tx in the
+// batch can be accepted as part of the package. Results are in input order.
+// Nodes may cap how many txs one call accepts.
+func (c *Client) TestAcceptBatch(ctx context.Context, rawTxHexes []string) ([]AcceptResult, error) {
+	if len(rawTxHexes) == 0 {
+		return nil, errors.New("broadcast: no raw txs to test")
+	}
+	raws := make([]string, len(rawTxHexes))
+	for i, h := range rawTxHexes {
+		raw, err := normalizeHex(h)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %w", i+1, err)
+		}
+		raws[i] = raw
+	}
+	return c.testAcceptAll(ctx, c.rpc, raws)
+}
+
+func (c *Client) testAccept(ctx context.Context, rpc RPC, raw string) (AcceptResult, error) {
+	results, err := c.testAcceptAll(ctx, rpc, []string{raw})
+	if err != nil {
+		return AcceptResult{}, err
+	}
+	return results[0], nil
+}
+
+func (c *Client) testAcceptAll(ctx context.Context, rpc RPC, raws []string) ([]AcceptResult, error) {
+	var results []AcceptResult
+	err := doWithRetry(ctx, c.retry, func(err error) bool {
+		return c.isRetryable(err) && !isMethodNotFoundErr(err)
+	}, func(ctx context.Context) error {
+		return rpc.Call(ctx, "testmempoolaccept", []any{raws}, &results)
+	})
+	if isMethodNotFoundErr(err) {
+		return nil, fmt.Errorf("%w: testmempoolaccept", ErrMethodUnsupported)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("broadcast: testmempoolaccept: %w", err)
+	}
+	if len(results) != len(raws) {
+		return nil, fmt.Errorf("broadcast: testmempoolaccept returned %d results for %d txs", len(results), len(raws))
+	}
+	return results, nil
+}
+
+// RejectedError annotates a rejection with the reject-reason testmempoolaccept
+// reports for the tx: either ErrRejected from WithPrecheck, or the generic
+// "rejected by AcceptToMemoryPool" error some junocashd builds return from
+// sendrawtransaction. It unwraps to that error.
+type RejectedError struct {
+	Err    error
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s (reject-reason: %s)", e.Err, e.Reason)
+}
+
+func (e *RejectedError) Unwrap() error { return e.Err }
+
+func (c *Client) checkAccepted(ctx context.Context, rpc RPC, raw string) error {
+	if !c.precheck {
+		return nil
+	}
+	res, err := c.testAccept(ctx, rpc, raw)
+	if err != nil {
+		return err
+	}
+	if res.Allowed {
+		return nil
+	}
+	reason := strings.TrimSpace(res.RejectReason)
+	if reason == "" {
+		reason = "unknown"
+	}
+	return &RejectedError{Err: ErrRejected, Reason: reason}
+}
+
+func isGenericRejectErr(err error) bool {
+	var rpcErr *junocashd.RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(rpcErr.Message), "rejected by accepttomemorypool")
+}
+
+// explainReject turns a generic AcceptToMemoryPool rejection into a
+// RejectedError when testmempoolaccept can name the reason; otherwise it
+// returns err unchanged.
+func (c *Client) explainReject(ctx context.Context, rpc RPC, raw string, err error) error {
+	if !isGenericRejectErr(err) {
+		return err
+	}
+	res, testErr := c.testAccept(ctx, rpc, raw)
+	if testErr != nil || res.Allowed || strings.TrimSpace(res.RejectReason) == "" {
+		return err
+	}
+	return &RejectedError{Err: err, Reason: strings.TrimSpace(res.RejectReason)}
+}
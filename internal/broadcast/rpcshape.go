@@ -0,0 +1,177 @@
+package broadcast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TransportOption adjusts the HTTP transport WithRPCTransportLimit builds.
+type TransportOption func(*shapeTransport)
+
+// WithJSONRPCVersion makes every request carry "jsonrpc": version instead of
+// the junocashd client's "1.0", for proxies that only accept "2.0". An empty
+// version, or "1.0", leaves requests as the client sends them.
+func WithJSONRPCVersion(version string) TransportOption {
+	return func(t *shapeTransport) {
+		t.version = version
+	}
+}
+
+// WithIDGenerator replaces each request's id with nextID(seq), where seq is
+// the junocashd client's own incrementing request number; return
+// fmt.Sprintf("juno-%d", seq), say, for gateways that want string ids. The
+// id in each response is mapped back to seq, since the junocashd client only
+// decodes numeric ids.
+func WithIDGenerator(nextID func(seq uint64) any) TransportOption {
+	return func(t *shapeTransport) {
+		t.nextID = nextID
+	}
+}
+
+// shapeTransport rewrites the jsonrpc and id fields of outgoing requests,
+// single or batched. Bodies that are not JSON-RPC requests are sent
+// unchanged.
+type shapeTransport struct {
+	next    http.RoundTripper
+	version string
+	nextID  func(seq uint64) any
+}
+
+func (t shapeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.next.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		return t.roundTripBatch(req, body)
+	}
+	var msg map[string]json.RawMessage
+	var seq uint64
+	if json.Unmarshal(body, &msg) != nil || (t.nextID != nil && json.Unmarshal(msg["id"], &seq) != nil) {
+		return t.next.RoundTrip(withBody(req, body))
+	}
+	if _, err := t.shape(msg, seq); err != nil {
+		return nil, err
+	}
+	if body, err = json.Marshal(msg); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(withBody(req, body))
+	if err != nil || t.nextID == nil {
+		return resp, err
+	}
+	return rewriteBody(resp, func(respBody []byte) []byte {
+		var reply map[string]json.RawMessage
+		if json.Unmarshal(respBody, &reply) == nil && reply["id"] != nil {
+			reply["id"], _ = json.Marshal(seq)
+			if b, err := json.Marshal(reply); err == nil {
+				return b
+			}
+		}
+		return respBody
+	})
+}
+
+// roundTripBatch is RoundTrip for a JSON-RPC batch: every request in it is
+// shaped, and the ids of the replies are mapped back.
+func (t shapeTransport) roundTripBatch(req *http.Request, body []byte) (*http.Response, error) {
+	var msgs []map[string]json.RawMessage
+	if json.Unmarshal(body, &msgs) != nil {
+		return t.next.RoundTrip(withBody(req, body))
+	}
+	seqs := make(map[string]uint64, len(msgs))
+	for _, msg := range msgs {
+		var seq uint64
+		if t.nextID != nil && json.Unmarshal(msg["id"], &seq) != nil {
+			return t.next.RoundTrip(withBody(req, body))
+		}
+		id, err := t.shape(msg, seq)
+		if err != nil {
+			return nil, err
+		}
+		seqs[canonicalJSON(id)] = seq
+	}
+	body, err := json.Marshal(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(withBody(req, body))
+	if err != nil || t.nextID == nil {
+		return resp, err
+	}
+	return rewriteBody(resp, func(respBody []byte) []byte {
+		var replies []map[string]json.RawMessage
+		if json.Unmarshal(respBody, &replies) != nil {
+			return respBody
+		}
+		for _, reply := range replies {
+			if seq, ok := seqs[canonicalJSON(reply["id"])]; ok {
+				reply["id"], _ = json.Marshal(seq)
+			}
+		}
+		if b, err := json.Marshal(replies); err == nil {
+			return b
+		}
+		return respBody
+	})
+}
+
+// shape sets msg's jsonrpc version and replaces its id, the client's request
+// number seq, returning the id sent.
+func (t shapeTransport) shape(msg map[string]json.RawMessage, seq uint64) (json.RawMessage, error) {
+	if t.version != "" && t.version != "1.0" {
+		msg["jsonrpc"], _ = json.Marshal(t.version)
+	}
+	if t.nextID != nil {
+		id, err := json.Marshal(t.nextID(seq))
+		if err != nil {
+			return nil, fmt.Errorf("broadcast: rpc id: %w", err)
+		}
+		msg["id"] = id
+	}
+	return msg["id"], nil
+}
+
+// rewriteBody replaces resp's body with rewrite applied to it.
+func rewriteBody(resp *http.Response, rewrite func([]byte) []byte) (*http.Response, error) {
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	respBody = rewrite(respBody)
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+// canonicalJSON re-encodes raw so equal ids compare equal however the
+// server formatted them.
+func canonicalJSON(raw json.RawMessage) string {
+	var v any
+	if json.Unmarshal(raw, &v) != nil {
+		return string(raw)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func withBody(req *http.Request, body []byte) *http.Request {
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return req
+}
@@ -0,0 +1,34 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// WithSendRawParams appends params, as raw JSON values, after the tx hex in
+// every sendrawtransaction call. It is an escape hatch for node versions
+// whose sendrawtransaction takes positional arguments this package has no
+// typed option for yet; the values are passed through unchecked, so a
+// mismatch with the node's signature surfaces as the node's RPC error.
+func WithSendRawParams(params []json.RawMessage) Option {
+	return func(c *Client) {
+		c.sendRawParams = append([]json.RawMessage(nil), params...)
+	}
+}
+
+// sendRaw broadcasts raw on rpc, through the typed SendRawTransaction unless
+// WithSendRawParams is set.
+func (c *Client) sendRaw(ctx context.Context, rpc RPC, raw string) (string, error) {
+	if len(c.sendRawParams) == 0 {
+		return rpc.SendRawTransaction(ctx, raw)
+	}
+	params := []any{raw}
+	for _, p := range c.sendRawParams {
+		params = append(params, p)
+	}
+	var txid string
+	if err := rpc.Call(ctx, "sendrawtransaction", params, &txid); err != nil {
+		return "", err
+	}
+	return txid, nil
+}
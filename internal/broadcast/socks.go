@@ -0,0 +1,32 @@
+package broadcast
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+	"golang.org/x/net/proxy"
+)
+
+// WithSOCKS5 is a junocashd option that dials the RPC endpoint through the
+// SOCKS5 proxy at addr (host:port, e.g. Tor's 127.0.0.1:9050). Hostnames are
+// resolved by the proxy, so .onion endpoints work. Use WithRPCTransport to
+// combine it with a bearer token.
+func WithSOCKS5(addr string) junocashd.Option {
+	return WithRPCTransport("", addr)
+}
+
+func socks5Transport(addr string) http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	d, err := proxy.SOCKS5("tcp", strings.TrimSpace(addr), nil, proxy.Direct)
+	t.DialContext = func(ctx context.Context, network, target string) (net.Conn, error) {
+		if err != nil {
+			return nil, err
+		}
+		return d.(proxy.ContextDialer).DialContext(ctx, network, target)
+	}
+	return t
+}
@@ -0,0 +1,38 @@
+package broadcast
+
+import (
+	"context"
+	"strings"
+)
+
+// WithTipGatedPolling makes WaitForConfirmations read getbestblockhash on each
+// poll and skip the full status lookup while the tip is the one seen on the
+// previous poll: without a new block the tx cannot gain confirmations. The
+// first poll, and every poll until the tx has been found, still does the full
+// lookup, so a tx that reaches the mempool between blocks is not missed.
+// Long waits then cost one cheap call per poll between blocks.
+func WithTipGatedPolling(enabled bool) Option {
+	return func(c *Client) {
+		c.tipGatedPolling = enabled
+	}
+}
+
+// tipGate tracks the tip across the polls of one wait.
+type tipGate struct {
+	tip string
+}
+
+// unchanged reports whether the tip is still the one recorded by the previous
+// call, recording the current tip either way. The first call reports false.
+func (g *tipGate) unchanged(ctx context.Context, c *Client) (bool, error) {
+	var tip string
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "getbestblockhash", nil, &tip)
+	}); err != nil {
+		return false, err
+	}
+	tip = strings.ToLower(strings.TrimSpace(tip))
+	same := g.tip != "" && g.tip == tip
+	g.tip = tip
+	return same, nil
+}
@@ -0,0 +1,79 @@
+package broadcast
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracer records Submit, Status and every RPC call as spans. A nil tracer
+// (the default) disables tracing entirely.
+func WithTracer(t trace.Tracer) Option {
+	return func(c *Client) {
+		c.tracer = t
+	}
+}
+
+type endSpanFunc func(err error, attrs ...attribute.KeyValue)
+
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, endSpanFunc) {
+	return startSpan(ctx, c.tracer, name, attrs...)
+}
+
+func startSpan(ctx context.Context, tracer trace.Tracer, name string, attrs ...attribute.KeyValue) (context.Context, endSpanFunc) {
+	if tracer == nil {
+		return ctx, func(error, ...attribute.KeyValue) {}
+	}
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, func(err error, attrs ...attribute.KeyValue) {
+		span.SetAttributes(attrs...)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func statusAttrs(st TxStatus, found bool) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.Bool("juno.found", found)}
+	if !found {
+		return attrs
+	}
+	attrs = append(attrs,
+		attribute.Bool("juno.in_mempool", st.InMempool),
+		attribute.Int64("juno.confirmations", st.Confirmations),
+	)
+	if st.BlockHash != "" {
+		attrs = append(attrs, attribute.String("juno.blockhash", st.BlockHash))
+	}
+	return attrs
+}
+
+type tracingRPC struct {
+	next   RPC
+	tracer trace.Tracer
+}
+
+func (t tracingRPC) Call(ctx context.Context, method string, params any, out any) error {
+	ctx, end := startSpan(ctx, t.tracer, "junocashd."+method, attribute.String("rpc.method", method))
+	err := t.next.Call(ctx, method, params, out)
+	end(err)
+	return err
+}
+
+func (t tracingRPC) CallBatch(ctx context.Context, calls []BatchCall) error {
+	ctx, end := startSpan(ctx, t.tracer, "junocashd.batch", attribute.Int("rpc.batch_size", len(calls)))
+	err := callBatch(ctx, t.next, calls)
+	end(err)
+	return err
+}
+
+func (t tracingRPC) SendRawTransaction(ctx context.Context, txHex string) (string, error) {
+	ctx, end := startSpan(ctx, t.tracer, "junocashd.sendrawtransaction", attribute.String("rpc.method", "sendrawtransaction"))
+	txid, err := t.next.SendRawTransaction(ctx, txHex)
+	end(err)
+	return txid, err
+}
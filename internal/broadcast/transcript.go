@@ -0,0 +1,169 @@
+package broadcast
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+)
+
+// transcriptEntry is one line of an NDJSON RPC transcript.
+type transcriptEntry struct {
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+	Result json.RawMessage  `json:"result,omitempty"`
+	Error  *transcriptError `json:"error,omitempty"`
+}
+
+type transcriptError struct {
+	Code    *int   `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// TranscriptRecorder is an RPC that forwards to next and appends every call
+// (method, params, and result or error) to w as NDJSON.
+type TranscriptRecorder struct {
+	next RPC
+	mu   sync.Mutex
+	enc  *json.Encoder
+}
+
+func NewTranscriptRecorder(next RPC, w io.Writer) *TranscriptRecorder {
+	return &TranscriptRecorder{next: next, enc: json.NewEncoder(w)}
+}
+
+func (r *TranscriptRecorder) Call(ctx context.Context, method string, params any, out any) error {
+	err := r.next.Call(ctx, method, params, out)
+	r.record(method, params, out, err)
+	return err
+}
+
+func (r *TranscriptRecorder) SendRawTransaction(ctx context.Context, txHex string) (string, error) {
+	txid, err := r.next.SendRawTransaction(ctx, txHex)
+	r.record("sendrawtransaction", []any{txHex}, txid, err)
+	return txid, err
+}
+
+func (r *TranscriptRecorder) record(method string, params, result any, err error) {
+	e := transcriptEntry{Method: method}
+	e.Params, _ = json.Marshal(params)
+	if err != nil {
+		e.Error = &transcriptError{Message: err.Error()}
+		var rpcErr *junocashd.RPCError
+		if errors.As(err, &rpcErr) {
+			code := rpcErr.Code
+			e.Error.Code = &code
+			e.Error.Message = rpcErr.Message
+		}
+	} else if result != nil {
+		e.Result, _ = json.Marshal(result)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(e)
+}
+
+// TranscriptReplay is an RPC that serves responses from a transcript written
+// by TranscriptRecorder. Calls are matched by method and params; repeated
+// calls replay the recorded responses in order and then keep returning the
+// last one, so polling loops terminate the same way the recorded session did.
+type TranscriptReplay struct {
+	mu      sync.Mutex
+	entries map[string][]transcriptEntry
+}
+
+func NewTranscriptReplay(r io.Reader) (*TranscriptReplay, error) {
+	t := &TranscriptReplay{entries: make(map[string][]transcriptEntry)}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e transcriptEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("broadcast: transcript line %d: %w", line, err)
+		}
+		k, err := transcriptKey(e.Method, e.Params)
+		if err != nil {
+			return nil, fmt.Errorf("broadcast: transcript line %d: %w", line, err)
+		}
+		t.entries[k] = append(t.entries[k], e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("broadcast: read transcript: %w", err)
+	}
+	return t, nil
+}
+
+func (t *TranscriptReplay) Call(ctx context.Context, method string, params any, out any) error {
+	e, err := t.next(method, params)
+	if err != nil {
+		return err
+	}
+	if e.Error != nil {
+		return e.Error.err()
+	}
+	if out == nil || len(e.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Result, out)
+}
+
+func (t *TranscriptReplay) SendRawTransaction(ctx context.Context, txHex string) (string, error) {
+	var txid string
+	err := t.Call(ctx, "sendrawtransaction", []any{txHex}, &txid)
+	return txid, err
+}
+
+func (t *TranscriptReplay) next(method string, params any) (transcriptEntry, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return transcriptEntry{}, err
+	}
+	k, err := transcriptKey(method, b)
+	if err != nil {
+		return transcriptEntry{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q := t.entries[k]
+	if len(q) == 0 {
+		return transcriptEntry{}, fmt.Errorf("broadcast: replay: no recorded response for %s %s", method, b)
+	}
+	e := q[0]
+	if len(q) > 1 {
+		t.entries[k] = q[1:]
+	}
+	return e, nil
+}
+
+func (e *transcriptError) err() error {
+	if e.Code != nil {
+		return &junocashd.RPCError{Code: *e.Code, Message: e.Message}
+	}
+	return errors.New(e.Message)
+}
+
+// transcriptKey canonicalizes params so recorded and live calls compare equal
+// regardless of how the caller built them.
+func transcriptKey(method string, params json.RawMessage) (string, error) {
+	var v any
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &v); err != nil {
+			return "", err
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return method + " " + string(b), nil
+}
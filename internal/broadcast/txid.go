@@ -0,0 +1,131 @@
+package broadcast
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLocalTxIDUnsupported is returned by TxIDFromHex for txs whose txid it
+// cannot compute (v5+); Client.TxID asks the node for those instead.
+var ErrLocalTxIDUnsupported = errors.New("broadcast: cannot compute txid locally")
+
+// WithSkipTxIDValidation makes Status, StatusAt and StatusBulk use txids
+// exactly as given, without trimming, lowercasing, or checking that they are
+// 64 hex characters. It saves a hex decode per lookup in tight monitoring
+// loops, but only for callers that guarantee well-formed, lowercase txids:
+// a malformed one is sent to the node as is and comes back as not found, or
+// as an RPC error, instead of failing fast. Status still validates when a
+// StatusCache is set, since cache file names are built from the txid.
+func WithSkipTxIDValidation(enabled bool) Option {
+	return func(c *Client) {
+		c.skipTxIDValidation = enabled
+	}
+}
+
+// WithEmptyTxIDFallback makes submits that sendrawtransaction answers with
+// success but an empty txid compute the txid with TxID instead of failing
+// with ErrEmptyTxID. An empty result is not something junocashd sends, so it
+// trusts that whatever answered did forward the tx to the node; confirm with
+// Status before relying on it. If the txid cannot be computed the submit
+// still fails with ErrEmptyTxID.
+func WithEmptyTxIDFallback(enabled bool) Option {
+	return func(c *Client) {
+		c.emptyTxIDFallback = enabled
+	}
+}
+
+// emptyTxID handles a successful sendrawtransaction of raw whose txid came
+// back empty: ErrEmptyTxID, or the computed txid with WithEmptyTxIDFallback.
+func (c *Client) emptyTxID(ctx context.Context, rpc RPC, raw string) (string, error) {
+	if !c.emptyTxIDFallback {
+		return "", ErrEmptyTxID
+	}
+	txid, err := c.txid(ctx, rpc, raw)
+	if err != nil {
+		return "", fmt.Errorf("%w; cannot compute it: %w", ErrEmptyTxID, err)
+	}
+	return txid, nil
+}
+
+// TxID returns the txid of rawTxHex. v1-v4 txids are computed locally with
+// TxIDFromHex; v5+ txids, which commit to the ZIP-244 digest tree, are read
+// from decoderawtransaction.
+func (c *Client) TxID(ctx context.Context, rawTxHex string) (string, error) {
+	return c.txid(ctx, c.rpc, rawTxHex)
+}
+
+func (c *Client) txid(ctx context.Context, rpc RPC, rawTxHex string) (string, error) {
+	txid, err := TxIDFromHex(rawTxHex)
+	if !errors.Is(err, ErrLocalTxIDUnsupported) {
+		return txid, err
+	}
+	raw, _ := normalizeHex(rawTxHex)
+	var decoded struct {
+		TxID string `json:"txid"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return rpc.Call(ctx, "decoderawtransaction", []any{raw}, &decoded)
+	}); err != nil {
+		return "", fmt.Errorf("broadcast: decoderawtransaction: %w", err)
+	}
+	txid, ok := normalizeTxID(decoded.TxID)
+	if !ok {
+		return "", errors.New("broadcast: decoderawtransaction returned an invalid txid")
+	}
+	return txid, nil
+}
+
+// checkTxID is normalizeTxID unless WithSkipTxIDValidation is set, in which
+// case txid is returned unchanged.
+func (c *Client) checkTxID(txid string) (string, bool) {
+	if c.skipTxIDValidation {
+		return txid, true
+	}
+	return normalizeTxID(txid)
+}
+
+var errInvalidTxID = errors.New("broadcast: txid must be 32-byte hex")
+
+// normalizeTxID lowercases and trims txid and reports whether it is 32-byte
+// hex.
+func normalizeTxID(txid string) (string, bool) {
+	txid = strings.ToLower(strings.TrimSpace(txid))
+	if _, err := hex.DecodeString(txid); err != nil || len(txid) != 64 {
+		return "", false
+	}
+	return txid, true
+}
+
+// TxIDFromHex computes the txid of a raw transaction locally, without asking
+// the node. For v1-v4 transactions the txid is the byte-reversed double
+// SHA-256 of the serialization. v5+ transactions use the ZIP-244 digest tree,
+// which is not implemented here; those fail with ErrLocalTxIDUnsupported (see
+// Client.TxID).
+func TxIDFromHex(rawTxHex string) (string, error) {
+	raw, err := normalizeHex(rawTxHex)
+	if err != nil {
+		return "", err
+	}
+	b, _ := hex.DecodeString(raw)
+	if len(b) < 4 {
+		return "", errors.New("broadcast: raw tx is too short")
+	}
+
+	header := binary.LittleEndian.Uint32(b[:4])
+	overwintered := header&(1<<31) != 0
+	if version := header &^ (1 << 31); overwintered && version >= 5 {
+		return "", fmt.Errorf("%w for v%d transactions", ErrLocalTxIDUnsupported, version)
+	}
+
+	first := sha256.Sum256(b)
+	sum := sha256.Sum256(first[:])
+	for i, j := 0, len(sum)-1; i < j; i, j = i+1, j-1 {
+		sum[i], sum[j] = sum[j], sum[i]
+	}
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,57 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type OutpointStatus struct {
+	Spent         bool   `json:"spent"`
+	SpendingTxID  string `json:"by,omitempty"`
+	Confirmations int64  `json:"confirmations"`
+}
+
+// IsSpent reports whether txid:vout is spent. The spending txid is only known
+// for mempool spends; spent-in-chain (or unknown) outpoints return "".
+func (c *Client) IsSpent(ctx context.Context, txid string, vout uint32) (bool, string, error) {
+	st, err := c.Outpoint(ctx, txid, vout)
+	return st.Spent, st.SpendingTxID, err
+}
+
+// Outpoint looks txid:vout up with gettxout (mempool-aware), and for spent
+// outpoints asks gettxspendingprevout for the mempool spender when available.
+func (c *Client) Outpoint(ctx context.Context, txid string, vout uint32) (OutpointStatus, error) {
+	txid, ok := normalizeTxID(txid)
+	if !ok {
+		return OutpointStatus{}, errInvalidTxID
+	}
+
+	var out *struct {
+		Confirmations int64 `json:"confirmations"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "gettxout", []any{txid, vout, true}, &out)
+	}); err != nil {
+		return OutpointStatus{}, fmt.Errorf("broadcast: gettxout: %w", err)
+	}
+	if out != nil {
+		return OutpointStatus{Confirmations: out.Confirmations}, nil
+	}
+
+	st := OutpointStatus{Spent: true}
+	spent, err := c.spendingPrevouts(ctx, []map[string]any{{"txid": txid, "vout": vout}})
+	if errors.Is(err, ErrMethodUnsupported) {
+		return st, nil
+	}
+	if err != nil {
+		return OutpointStatus{}, err
+	}
+	for _, s := range spent {
+		if id := strings.ToLower(strings.TrimSpace(s.SpendingTxID)); id != "" {
+			st.SpendingTxID = id
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,125 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// WaitAllResult is one txid's outcome from WaitForAll: its last seen status,
+// the confirmations it had to reach, and whether it did.
+type WaitAllResult struct {
+	TxStatus
+	RequiredConfs int64 `json:"required_confs"`
+	Met           bool  `json:"met"`
+}
+
+// WaitForAll waits until at least minSuccess of txids have the given number
+// of confirmations (counted per WithConfirmationBase; 0 means known to the
+// node), or all of them when minSuccess <= 0. Each poll looks up the txids
+// still pending with StatusBulk, so they are compared against one tip.
+//
+// Results are in txids order. If ctx ends first, the results so far are
+// returned with an error matching ErrWaitTimeout (and ctx's error) that says
+// how many txids met the target. If a lookup fails, the results so far are
+// returned with its error.
+func (c *Client) WaitForAll(ctx context.Context, txids []string, confirmations int64, minSuccess int) ([]WaitAllResult, error) {
+	targets := make([]int64, len(txids))
+	for i := range targets {
+		targets[i] = confirmations
+	}
+	return c.waitForAll(ctx, txids, targets, minSuccess)
+}
+
+// WaitForAllTargets is WaitForAll with a confirmation target per txid, e.g.
+// more confirmations for larger payments. Results are in txid order.
+func (c *Client) WaitForAllTargets(ctx context.Context, targets map[string]int64, minSuccess int) ([]WaitAllResult, error) {
+	txids := make([]string, 0, len(targets))
+	for txid := range targets {
+		txids = append(txids, txid)
+	}
+	sort.Strings(txids)
+	confs := make([]int64, len(txids))
+	for i, txid := range txids {
+		confs[i] = targets[txid]
+	}
+	return c.waitForAll(ctx, txids, confs, minSuccess)
+}
+
+func (c *Client) waitForAll(ctx context.Context, txids []string, targets []int64, minSuccess int) ([]WaitAllResult, error) {
+	if len(txids) == 0 {
+		return nil, errors.New("broadcast: no txids to wait for")
+	}
+	results := make([]WaitAllResult, len(txids))
+	uniform := true
+	for i, confs := range targets {
+		if confs < 0 {
+			return nil, errors.New("broadcast: confirmations must be >= 0")
+		}
+		results[i].RequiredConfs = confs
+		uniform = uniform && confs == targets[0]
+	}
+	if minSuccess <= 0 || minSuccess > len(txids) {
+		minSuccess = len(txids)
+	}
+	if err := c.checkSynced(ctx, c.rpc); err != nil {
+		return nil, err
+	}
+
+	var met int
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		var pending []int
+		var lookup []string
+		for i, r := range results {
+			if !r.Met {
+				pending = append(pending, i)
+				lookup = append(lookup, txids[i])
+			}
+		}
+		statuses, err := c.StatusBulk(ctx, lookup)
+		if err != nil && ctx.Err() == nil {
+			return fillTxIDs(results, txids), err
+		}
+		for j, st := range statuses {
+			r := &results[pending[j]]
+			r.TxStatus = st
+			required := r.RequiredConfs
+			if c.confirmationBase == BlockExclusive {
+				required++
+			}
+			found := st.InMempool || st.BlockHash != ""
+			if found && (r.RequiredConfs == 0 || st.Confirmations >= required) {
+				r.Met = true
+				met++
+			}
+		}
+		if met >= minSuccess {
+			return results, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fillTxIDs(results, txids)
+			target := "their confirmation targets"
+			if uniform {
+				target = fmt.Sprintf("%d confirmations", targets[0])
+			}
+			return results, fmt.Errorf("%w: %d of %d txids reached %s (need %d): %w", ErrWaitTimeout, met, len(txids), target, minSuccess, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// fillTxIDs names the results of txids no lookup has answered yet.
+func fillTxIDs(results []WaitAllResult, txids []string) []WaitAllResult {
+	for i := range results {
+		if results[i].TxID == "" {
+			results[i].TxID = txids[i]
+		}
+	}
+	return results
+}
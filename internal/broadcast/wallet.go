@@ -0,0 +1,111 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+)
+
+// walletScanDepth is how many recent wallet transactions ResubmitWallet asks
+// listtransactions for. Unconfirmed txs are the newest, so they fall well
+// inside it unless the wallet has been extremely busy.
+const walletScanDepth = 10_000
+
+// ResubmitWallet re-broadcasts every wallet transaction with zero
+// confirmations, e.g. after a node restart dropped them from the mempool. It
+// lists them with listtransactions, fetches each raw hex with gettransaction,
+// and sends it through the same path as Submit. A tx the node reports it
+// already has counts as handled. It returns the txids handled; failures for
+// individual txs do not stop the rest and are returned joined.
+//
+// Conflicted txs (negative confirmations) and coinbase outputs are skipped.
+// Nodes without a wallet fail with ErrMethodUnsupported.
+func (c *Client) ResubmitWallet(ctx context.Context) ([]string, error) {
+	if c.readOnly {
+		return nil, readOnlyErr("sendrawtransaction")
+	}
+	txids, err := c.unconfirmedWalletTxids(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var handled []string
+	var errs []error
+	for _, txid := range txids {
+		raw, err := c.walletTxHex(ctx, txid)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := c.submit(ctx, c.rpc, raw); err != nil && !isAlreadyKnownErr(err) {
+			errs = append(errs, fmt.Errorf("broadcast: resubmit %s: %w", txid, err))
+			continue
+		}
+		handled = append(handled, txid)
+	}
+	return handled, errors.Join(errs...)
+}
+
+// walletTxHex fetches a wallet transaction's raw hex with gettransaction.
+func (c *Client) walletTxHex(ctx context.Context, txid string) (string, error) {
+	var tx struct {
+		Hex string `json:"hex"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "gettransaction", []any{txid}, &tx)
+	}); err != nil {
+		return "", fmt.Errorf("broadcast: gettransaction %s: %w", txid, err)
+	}
+	return tx.Hex, nil
+}
+
+func (c *Client) unconfirmedWalletTxids(ctx context.Context) ([]string, error) {
+	var entries []struct {
+		TxID          string `json:"txid"`
+		Confirmations int64  `json:"confirmations"`
+		Generated     bool   `json:"generated"`
+	}
+	if err := doWithRetry(ctx, c.retry, c.isRetryable, func(ctx context.Context) error {
+		return c.rpc.Call(ctx, "listtransactions", []any{"*", walletScanDepth}, &entries)
+	}); err != nil {
+		if isMethodNotFoundErr(err) {
+			return nil, fmt.Errorf("%w: listtransactions", ErrMethodUnsupported)
+		}
+		return nil, fmt.Errorf("broadcast: listtransactions: %w", err)
+	}
+
+	// listtransactions has one entry per wallet-relevant output, so a tx
+	// can appear several times.
+	seen := make(map[string]bool)
+	var txids []string
+	for _, e := range entries {
+		txid := strings.ToLower(strings.TrimSpace(e.TxID))
+		if e.Confirmations != 0 || e.Generated || txid == "" || seen[txid] {
+			continue
+		}
+		seen[txid] = true
+		txids = append(txids, txid)
+	}
+	return txids, nil
+}
+
+// IsAlreadyKnown reports whether err is a submit refused because the node
+// already has the tx, in its mempool or in a block. Resubmitting such a tx
+// is harmless, so callers that retry submits can treat it as success.
+func IsAlreadyKnown(err error) bool { return isAlreadyKnownErr(err) }
+
+// isAlreadyKnownErr reports sendrawtransaction refusing a tx because the node
+// already has it, in its mempool or in a block.
+func isAlreadyKnownErr(err error) bool {
+	var rpcErr *junocashd.RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	return strings.Contains(msg, "txn-already-in-mempool") ||
+		strings.Contains(msg, "txn-already-known") ||
+		strings.Contains(msg, "already in block chain")
+}
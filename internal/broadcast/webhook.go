@@ -0,0 +1,165 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebhookEvent is the JSON body POSTed by WithWebhook. Type is "submitted"
+// or "confirmed"; Confirmations and BlockHash are set for confirmed events.
+type WebhookEvent struct {
+	Type          string    `json:"type"`
+	TxID          string    `json:"txid"`
+	Confirmations int64     `json:"confirmations"`
+	BlockHash     string    `json:"blockhash,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+const (
+	webhookAttempts     = 4
+	webhookDrainTimeout = 5 * time.Second
+)
+
+// WithWebhook POSTs a WebhookEvent to endpoint after each successful submit
+// and when WaitForConfirmations reaches its target with the tx in a block.
+// Delivery runs in the background and is retried with backoff on network
+// errors, 408, 429 and 5xx responses; it never fails the submit or wait.
+// Close waits up to 5s for pending deliveries, retries included, then cancels
+// them. A nil client uses a 10s timeout. Pass WithWebhookErrorHandler to
+// observe deliveries that gave up.
+func WithWebhook(endpoint string, client *http.Client) Option {
+	return func(c *Client) {
+		c.webhookURL = strings.TrimSpace(endpoint)
+		c.webhookClient = client
+		if c.webhookClient == nil {
+			c.webhookClient = &http.Client{Timeout: 10 * time.Second}
+		}
+		c.webhookDrain = webhookDrainTimeout
+		c.webhookCtx, c.webhookCancel = context.WithCancel(context.Background())
+	}
+}
+
+// WithWebhookErrorHandler sets fn to receive WithWebhook deliveries that
+// failed after every retry. Errors name the webhook without credentials or
+// query string.
+func WithWebhookErrorHandler(fn func(error)) Option {
+	return func(c *Client) {
+		c.webhookErr = fn
+	}
+}
+
+// confirmed reports a successful wait to the webhook; waits that end with the
+// tx still in the mempool (a target of 0) are not confirmations.
+func (c *Client) confirmed(st TxStatus) TxStatus {
+	if st.BlockHash != "" {
+		c.notify("confirmed", st)
+	}
+	return st
+}
+
+func (c *Client) notify(typ string, st TxStatus) {
+	if c.webhookURL == "" {
+		return
+	}
+	b, err := json.Marshal(WebhookEvent{
+		Type:          typ,
+		TxID:          st.TxID,
+		Confirmations: st.Confirmations,
+		BlockHash:     st.BlockHash,
+		Timestamp:     time.Now().UTC(),
+	})
+	if err != nil {
+		return
+	}
+	c.webhookWG.Add(1)
+	go func() {
+		defer c.webhookWG.Done()
+		if err := c.deliverWebhook(b); err != nil && c.webhookErr != nil {
+			c.webhookErr(fmt.Errorf("broadcast: webhook %s %s event: %w", redactWebhookURL(c.webhookURL), typ, err))
+		}
+	}()
+}
+
+func (c *Client) deliverWebhook(body []byte) error {
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			t := time.NewTimer(backoff(500*time.Millisecond, 5*time.Second, attempt-1))
+			select {
+			case <-t.C:
+			case <-c.webhookCtx.Done():
+				t.Stop()
+				return err
+			}
+		}
+		var retry bool
+		retry, err = c.postWebhook(c.webhookCtx, body)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *Client) postWebhook(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.webhookClient.Do(req)
+	if err != nil {
+		// *url.Error repeats the full URL; keep only the cause.
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("http %d", resp.StatusCode)
+}
+
+// drainWebhooks waits for deliveries still running when the client is
+// closed, cancelling their POSTs and retry backoffs once webhookDrain has
+// passed.
+func (c *Client) drainWebhooks() {
+	if c.webhookCancel == nil {
+		c.webhookWG.Wait()
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		c.webhookWG.Wait()
+		close(done)
+	}()
+	t := time.NewTimer(c.webhookDrain)
+	defer t.Stop()
+	select {
+	case <-done:
+	case <-t.C:
+		c.webhookCancel()
+		<-done
+	}
+	c.webhookCancel()
+}
+
+func redactWebhookURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(invalid url)"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
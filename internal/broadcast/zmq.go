@@ -0,0 +1,201 @@
+package broadcast
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithZMQ makes WaitForConfirmations re-check status whenever junocashd
+// publishes a hashblock notification on endpoint (its -zmqpubhashblock
+// address, e.g. tcp://127.0.0.1:28332), polling only every 4 poll intervals
+// in case a notification is lost. If the subscription cannot be set up, or
+// drops mid-wait, the wait falls back to polling every interval.
+func WithZMQ(endpoint string) Option {
+	return func(c *Client) {
+		c.zmqEndpoint = strings.TrimSpace(endpoint)
+	}
+}
+
+// zmqSafetyPollFactor stretches the poll interval while hashblock
+// notifications drive the wait.
+const zmqSafetyPollFactor = 4
+
+const (
+	zmqFlagMore    = 0x01
+	zmqFlagLong    = 0x02
+	zmqFlagCommand = 0x04
+
+	zmqMaxFrame = 16 << 20
+)
+
+// zmqSub is a minimal ZMTP 3.0 SUB socket (NULL security, tcp only) that
+// signals C once per message received on the subscribed topic. C is closed
+// when the connection fails or Close is called.
+type zmqSub struct {
+	C <-chan struct{}
+
+	conn      net.Conn
+	closeOnce sync.Once
+}
+
+func subscribeZMQ(ctx context.Context, endpoint, topic string) (*zmqSub, error) {
+	addr, ok := strings.CutPrefix(endpoint, "tcp://")
+	if !ok {
+		return nil, fmt.Errorf("broadcast: zmq endpoint %q must be tcp://host:port", endpoint)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("broadcast: zmq dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	r := bufio.NewReader(conn)
+	if err := zmqHandshake(conn, r, topic); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("broadcast: zmq handshake: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	ch := make(chan struct{}, 1)
+	s := &zmqSub{C: ch, conn: conn}
+	go s.readLoop(r, topic, ch)
+	return s, nil
+}
+
+func (s *zmqSub) Close() error {
+	var err error
+	s.closeOnce.Do(func() { err = s.conn.Close() })
+	return err
+}
+
+func (s *zmqSub) readLoop(r *bufio.Reader, topic string, ch chan<- struct{}) {
+	defer close(ch)
+	defer s.Close()
+
+	var first []byte
+	var inMessage bool
+	for {
+		flags, body, err := zmqReadFrame(r)
+		if err != nil {
+			return
+		}
+		if flags&zmqFlagCommand != 0 {
+			continue
+		}
+		if !inMessage {
+			first = body
+		}
+		inMessage = flags&zmqFlagMore != 0
+		if inMessage || string(first) != topic {
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func zmqHandshake(w io.Writer, r *bufio.Reader, topic string) error {
+	greeting := make([]byte, 64)
+	greeting[0] = 0xff
+	greeting[9] = 0x7f
+	greeting[10] = 3
+	copy(greeting[12:32], "NULL")
+	if _, err := w.Write(greeting); err != nil {
+		return err
+	}
+
+	peer := make([]byte, 64)
+	if _, err := io.ReadFull(r, peer); err != nil {
+		return err
+	}
+	if peer[0] != 0xff || peer[9] != 0x7f {
+		return errors.New("peer is not a zmtp endpoint")
+	}
+	if peer[10] < 3 {
+		return fmt.Errorf("unsupported zmtp version %d", peer[10])
+	}
+	if mech := strings.TrimRight(string(peer[12:32]), "\x00"); mech != "NULL" {
+		return fmt.Errorf("unsupported zmtp mechanism %q", mech)
+	}
+
+	ready := []byte{5}
+	ready = append(ready, "READY"...)
+	ready = append(ready, byte(len("Socket-Type")))
+	ready = append(ready, "Socket-Type"...)
+	ready = binary.BigEndian.AppendUint32(ready, uint32(len("SUB")))
+	ready = append(ready, "SUB"...)
+	if err := zmqWriteFrame(w, zmqFlagCommand, ready); err != nil {
+		return err
+	}
+
+	for {
+		flags, body, err := zmqReadFrame(r)
+		if err != nil {
+			return err
+		}
+		if flags&zmqFlagCommand == 0 {
+			return errors.New("expected READY command")
+		}
+		if len(body) > 0 && int(body[0]) < len(body) && string(body[1:1+int(body[0])]) == "READY" {
+			break
+		}
+	}
+
+	return zmqWriteFrame(w, 0, append([]byte{1}, topic...))
+}
+
+func zmqWriteFrame(w io.Writer, flags byte, body []byte) error {
+	var hdr []byte
+	if len(body) > 255 {
+		hdr = binary.BigEndian.AppendUint64([]byte{flags | zmqFlagLong}, uint64(len(body)))
+	} else {
+		hdr = []byte{flags, byte(len(body))}
+	}
+	_, err := w.Write(append(hdr, body...))
+	return err
+}
+
+func zmqReadFrame(r *bufio.Reader) (byte, []byte, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&zmqFlagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		n, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(n)
+	}
+	if size > zmqMaxFrame {
+		return 0, nil, fmt.Errorf("zmq frame too large (%d bytes)", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
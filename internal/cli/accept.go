@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type testAcceptRunner interface {
+	TestAcceptBatch(ctx context.Context, rawTxHexes []string) ([]broadcast.AcceptResult, error)
+}
+
+// testAcceptResult is one tx's verdict. Fees is the base fee in zatoshis,
+// present when the node reports it.
+type testAcceptResult struct {
+	TxID         string `json:"txid"`
+	Allowed      bool   `json:"allowed"`
+	RejectReason string `json:"reject_reason,omitempty"`
+	Fees         *int64 `json:"fees,omitempty"`
+}
+
+type testAcceptData struct {
+	Results []testAcceptResult `json:"results"`
+}
+
+func runTestAccept(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("test-accept", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var rawTxHexes stringList
+	var rawTxFile string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.Var(&rawTxHexes, "raw-tx-hex", "signed raw tx hex (repeatable)")
+	fs.StringVar(&rawTxFile, "raw-tx-file", "", "path to a file with one signed raw tx hex per line (- for stdin)")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	raws := []string(rawTxHexes)
+	if strings.TrimSpace(rawTxFile) != "" {
+		if len(raws) > 0 {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "input source conflict (use only one of --raw-tx-hex, --raw-tx-file)")
+		}
+		raws, err = readRawTxLines(rawTxFile)
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
+	}
+	if len(raws) == 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "raw-tx-hex or raw-tx-file is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	ar, ok := r.(testAcceptRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "testmempoolaccept is not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	accepted, err := ar.TestAcceptBatch(ctx, raws)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+
+	data := testAcceptData{Results: make([]testAcceptResult, 0, len(accepted))}
+	var allowed int
+	for _, a := range accepted {
+		res := testAcceptResult{TxID: a.TxID, Allowed: a.Allowed, RejectReason: a.RejectReason}
+		if a.Fees != nil {
+			zat := int64(math.Round(a.Fees.Base * 1e8))
+			res.Fees = &zat
+		}
+		if a.Allowed {
+			allowed++
+		}
+		data.Results = append(data.Results, res)
+	}
+
+	if !jsonOut {
+		for _, res := range data.Results {
+			switch {
+			case !res.Allowed:
+				fmt.Fprintf(stdout, "%s rejected: %s\n", res.TxID, res.RejectReason)
+			case res.Fees != nil:
+				fmt.Fprintf(stdout, "%s allowed fees=%d\n", res.TxID, *res.Fees)
+			default:
+				fmt.Fprintf(stdout, "%s allowed\n", res.TxID)
+			}
+		}
+	}
+	// Only a batch the node would reject outright is a failure; the
+	// per-tx detail is reported either way.
+	if allowed == 0 {
+		if jsonOut {
+			return writeErrData(errOut, stderr, jsonOut, "rejected", "node would reject every tx in the batch", map[string]any{"results": data.Results})
+		}
+		return writeErr(errOut, stderr, jsonOut, "rejected", "node would reject every tx in the batch")
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, data)
+	}
+	return 0
+}
+
+// readRawTxLines reads one raw tx hex per line from path ("-" for stdin),
+// skipping blank lines.
+func readRawTxLines(path string) ([]string, error) {
+	in, closeIn, err := openLineInput(path, "raw-tx-file")
+	if err != nil {
+		return nil, err
+	}
+	defer closeIn()
+
+	var raws []string
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 0, 64<<10), 20<<20)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			raws = append(raws, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read raw-tx-file: %w", err)
+	}
+	return raws, nil
+}
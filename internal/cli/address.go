@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type addressRunner interface {
+	ListTransactionsForAddress(ctx context.Context, address string) ([]string, error)
+}
+
+func runAddressTxids(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("address-txids", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var address string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&address, "address", "", "address to list transactions for (requires the node's address index)")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "address is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	ar, ok := r.(addressRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "address queries are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	txids, err := ar.ListTransactionsForAddress(ctx, address)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		if txids == nil {
+			txids = []string{}
+		}
+		return writeOK(stdout, jsonOut, map[string]any{"address": address, "txids": txids})
+	}
+	for _, txid := range txids {
+		fmt.Fprintln(stdout, txid)
+	}
+	return 0
+}
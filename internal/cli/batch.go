@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type streamError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// streamResult is one NDJSON record emitted by the streaming/batch modes.
+type streamResult struct {
+	Version  string              `json:"version"`
+	Status   string              `json:"status"`
+	Line     int                 `json:"line"`
+	TxID     string              `json:"txid,omitempty"`
+	TxStatus *broadcast.TxStatus `json:"tx_status,omitempty"`
+	Error    *streamError        `json:"error,omitempty"`
+}
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// warmup checks connectivity and auth with one cheap RPC before a batch starts,
+// so a broken setup fails the whole run instead of every line. Runners without
+// Ping are not checked.
+func warmup(ctx context.Context, r Runner) error {
+	p, ok := r.(pinger)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := p.Ping(ctx); err != nil {
+		return fmt.Errorf("warmup failed: %w", err)
+	}
+	return nil
+}
+
+func runStatusBatch(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("status-batch", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var txidFile string
+	var newerThan time.Duration
+	var cacheDir string
+	var cacheMinConfs int64
+	var cacheRecheck time.Duration
+	var statsMode statsFlag
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&txidFile, "txid-file", "", "path to a file with one txid per line (- for stdin)")
+	fs.DurationVar(&newerThan, "newer-than", 0, "skip confirmed txs whose block time is older than this (e.g. 72h; 0 = report all)")
+	fs.StringVar(&cacheDir, "cache-dir", "", "directory for an on-disk cache of deeply confirmed tx status")
+	fs.Int64Var(&cacheMinConfs, "cache-min-confirmations", 6, "only cache txs with at least N confirmations")
+	fs.DurationVar(&cacheRecheck, "cache-recheck", 10*time.Minute, "re-verify a cached block is still on the best chain after this long")
+	fs.Var(&statsMode, "stats", "write a summary to stderr when done (--stats for JSON, --stats=text for one line)")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "write setup errors to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, true, "invalid_request", err.Error())
+	}
+	if newerThan < 0 {
+		return writeErr(errOut, stderr, true, "invalid_request", "newer-than must be >= 0")
+	}
+	cfg.CacheDir = strings.TrimSpace(cacheDir)
+	cfg.CacheMinConfirmations = cacheMinConfs
+	cfg.CacheRecheck = cacheRecheck
+
+	in, closeIn, err := openLineInput(txidFile, "txid-file")
+	if err != nil {
+		return writeErr(errOut, stderr, true, "invalid_request", err.Error())
+	}
+	defer closeIn()
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, true, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := warmup(ctx, r); err != nil {
+		return writeErr(errOut, stderr, true, errCode(err), err.Error())
+	}
+
+	var cutoff time.Time
+	if newerThan > 0 {
+		cutoff = time.Now().Add(-newerThan)
+	}
+
+	stats := newBatchStats()
+	defer stats.write(stderr, statsMode)
+	return statusBatch(ctx, r, in, strings.TrimSpace(txidFile) != "-", cutoff, stats, stdout)
+}
+
+// bulkStatusRunner is implemented by runners that can look up many txids in
+// JSON-RPC batches.
+type bulkStatusRunner interface {
+	Batching() bool
+	StatusBulk(ctx context.Context, txids []string) ([]broadcast.TxStatus, error)
+}
+
+// cachedBlockTimer is implemented by runners with a status cache, which
+// records the block time of deeply confirmed txs.
+type cachedBlockTimer interface {
+	CachedBlockTime(txid string) (int64, bool)
+}
+
+// statusBatchChunk is how many txid lines status-batch looks up per batch
+// when the runner supports batching.
+const statusBatchChunk = 100
+
+// txStatusLookup is the outcome of one status-batch lookup.
+type txStatusLookup struct {
+	skipped bool
+	st      broadcast.TxStatus
+	found   bool
+	err     error
+}
+
+// statusBatch looks up each txid line of in and writes one NDJSON result per
+// line. If ctx is cancelled mid-batch, the lookups in flight are dropped and
+// a trailing cancelled record reports how many lines were processed and, when
+// countRest is set (in is a file rather than a pipe that may never end), how
+// many remain; the exit status is then 130, as for other cancelled commands.
+//
+// Files are looked up statusBatchChunk lines at a time in JSON-RPC batches
+// when r supports them; a pipe is looked up line by line so each result is
+// written as soon as its line arrives.
+func statusBatch(ctx context.Context, r Runner, in io.Reader, countRest bool, cutoff time.Time, stats *batchStats, stdout io.Writer) int {
+	enc := json.NewEncoder(stdout)
+	sc := bufio.NewScanner(in)
+	chunkSize := 1
+	if br, ok := r.(bulkStatusRunner); ok && countRest && br.Batching() {
+		chunkSize = statusBatchChunk
+	}
+	var lineNo, processed int
+	var failed bool
+	for {
+		chunk, lines := scanTxids(sc, chunkSize, &lineNo)
+		if len(chunk) == 0 {
+			break
+		}
+		cancelled := func() int {
+			rest := remainingLines(sc, countRest)
+			if rest != nil {
+				*rest += len(chunk) - 1
+			}
+			writeCancelled(enc, processed, rest)
+			return errExitStatus("cancelled")
+		}
+		if ctx.Err() != nil {
+			return cancelled()
+		}
+		results := lookupStatuses(ctx, r, chunk, cutoff)
+		if ctx.Err() != nil {
+			for _, l := range results {
+				if l.err != nil {
+					return cancelled()
+				}
+			}
+		}
+
+		for i, txid := range chunk {
+			processed++
+			res := streamResult{Version: jsonVersionV1, Line: lines[i], TxID: txid}
+			switch l := results[i]; {
+			case l.skipped:
+				res.Status = "skipped"
+			case l.err != nil:
+				failed = true
+				res.Status = "err"
+				res.Error = &streamError{Code: errCode(l.err), Message: l.err.Error()}
+			case !l.found:
+				failed = true
+				res.Status = "err"
+				res.Error = &streamError{Code: "not_found", Message: "unknown txid"}
+			case !cutoff.IsZero() && l.st.BlockTime > 0 && time.Unix(l.st.BlockTime, 0).Before(cutoff):
+				res.Status = "skipped"
+			default:
+				res.Status = "ok"
+				res.TxStatus = &l.st
+			}
+			stats.record(res)
+			_ = enc.Encode(res)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		_ = enc.Encode(streamResult{
+			Version: jsonVersionV1,
+			Status:  "err",
+			Line:    lineNo,
+			Error:   &streamError{Code: "invalid_request", Message: err.Error()},
+		})
+		return 1
+	}
+	return exitCode(failed)
+}
+
+// scanTxids reads up to n non-empty lines from sc, lowercased and trimmed,
+// with their line numbers in the input. lineNo counts every line read,
+// blank ones included, across calls.
+func scanTxids(sc *bufio.Scanner, n int, lineNo *int) (txids []string, lines []int) {
+	for len(txids) < n && sc.Scan() {
+		*lineNo++
+		if txid := strings.ToLower(strings.TrimSpace(sc.Text())); txid != "" {
+			txids = append(txids, txid)
+			lines = append(lines, *lineNo)
+		}
+	}
+	return txids, lines
+}
+
+// lookupStatuses looks up txids, in one StatusBulk call when there are
+// several. Txids the batch does not find are retried with Status, which also
+// searches the mempool and recent blocks on nodes without -txindex, and a
+// failed batch falls back to Status for every txid so one bad line does not
+// fail its neighbours. With a non-zero cutoff, txids the status cache
+// already places in a block older than it are skipped without a lookup.
+func lookupStatuses(ctx context.Context, r Runner, txids []string, cutoff time.Time) []txStatusLookup {
+	results := make([]txStatusLookup, len(txids))
+	pending := make([]int, 0, len(txids))
+	for i, txid := range txids {
+		if ct, ok := r.(cachedBlockTimer); ok && !cutoff.IsZero() {
+			if bt, ok := ct.CachedBlockTime(txid); ok && time.Unix(bt, 0).Before(cutoff) {
+				results[i] = txStatusLookup{skipped: true}
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+
+	var sts []broadcast.TxStatus
+	if br, ok := r.(bulkStatusRunner); ok && len(pending) > 1 {
+		bulk := make([]string, len(pending))
+		for j, i := range pending {
+			bulk[j] = txids[i]
+		}
+		bulkCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		var err error
+		sts, err = br.StatusBulk(bulkCtx, bulk)
+		cancel()
+		if err != nil {
+			sts = nil
+		}
+	}
+	for j, i := range pending {
+		txid := txids[i]
+		if sts != nil && (sts[j].InMempool || sts[j].BlockHash != "" || sts[j].Confirmations != 0) {
+			results[i] = txStatusLookup{st: sts[j], found: true}
+			continue
+		}
+		statusCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		st, found, err := r.Status(statusCtx, txid)
+		cancel()
+		results[i] = txStatusLookup{st: st, found: found, err: err}
+	}
+	return results
+}
+
+// cancelledResult is the trailing NDJSON record of a batch or stream cut short
+// by cancellation (Ctrl-C or SIGTERM). Every result above it is complete.
+// Remaining is omitted when it cannot be known.
+type cancelledResult struct {
+	Version   string `json:"version"`
+	Status    string `json:"status"`
+	Processed int    `json:"processed"`
+	Remaining *int   `json:"remaining,omitempty"`
+}
+
+func writeCancelled(enc *json.Encoder, processed int, remaining *int) {
+	_ = enc.Encode(cancelledResult{Version: jsonVersionV1, Status: "cancelled", Processed: processed, Remaining: remaining})
+}
+
+// remainingLines counts the current line of sc and every non-empty one after
+// it, or returns nil without reading when count is false.
+func remainingLines(sc *bufio.Scanner, count bool) *int {
+	if !count {
+		return nil
+	}
+	n := 1
+	for sc.Scan() {
+		if strings.TrimSpace(sc.Text()) != "" {
+			n++
+		}
+	}
+	return &n
+}
+
+// openLineInput opens path for line-oriented reading; "-" reads stdin.
+func openLineInput(path, flagName string) (io.Reader, func(), error) {
+	path = strings.TrimSpace(path)
+	switch path {
+	case "":
+		return nil, nil, fmt.Errorf("%s is required", flagName)
+	case "-":
+		return os.Stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	return f, func() { _ = f.Close() }, nil
+}
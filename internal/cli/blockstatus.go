@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type blockStatusRunner interface {
+	BlockStatus(ctx context.Context, blockHash string) (broadcast.BlockStatus, bool, error)
+}
+
+func runBlockStatus(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("block-status", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var hash string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&hash, "hash", "", "block hash")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	hash = strings.TrimSpace(hash)
+	if hash == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "hash is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	br, ok := r.(blockStatusRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "block status lookups are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	st, found, err := br.BlockStatus(ctx, hash)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if !found {
+		return writeErr(errOut, stderr, jsonOut, "not_found", "unknown block hash")
+	}
+
+	// An orphaned block is still reported as data, but fails the command so
+	// audit scripts can rely on the exit code.
+	writeOK(stdout, jsonOut, st)
+	return exitCode(!st.ActiveChain)
+}
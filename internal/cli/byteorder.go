@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+// txidByteOrder is --txid-byte-order: display (the node's big-endian form,
+// the default) or internal (the little-endian bytes as serialized in txs).
+type txidByteOrder string
+
+const txidOrderInternal txidByteOrder = "internal"
+
+func (o *txidByteOrder) String() string {
+	if *o == "" {
+		return "display"
+	}
+	return string(*o)
+}
+
+func (o *txidByteOrder) Set(v string) error {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "display":
+		*o = ""
+	case "internal":
+		*o = txidOrderInternal
+	default:
+		return errors.New("txid-byte-order must be display or internal")
+	}
+	return nil
+}
+
+// format renders a display-order txid in o's byte order.
+func (o txidByteOrder) format(txid string) string {
+	if o != txidOrderInternal {
+		return txid
+	}
+	return reverseHexBytes(txid)
+}
+
+func (o txidByteOrder) status(st broadcast.TxStatus) broadcast.TxStatus {
+	st.TxID = o.format(st.TxID)
+	return st
+}
+
+// bumps applies o to both txids of every fee bump step.
+func (o txidByteOrder) bumps(bs []broadcast.FeeBump) []broadcast.FeeBump {
+	if bs == nil {
+		return nil
+	}
+	out := make([]broadcast.FeeBump, len(bs))
+	for i, b := range bs {
+		b.OrigTxID, b.TxID = o.format(b.OrigTxID), o.format(b.TxID)
+		out[i] = b
+	}
+	return out
+}
+
+// result applies o to every txid in a stream record.
+func (o txidByteOrder) result(res streamResult) streamResult {
+	res.TxID = o.format(res.TxID)
+	if res.TxStatus != nil {
+		st := o.status(*res.TxStatus)
+		res.TxStatus = &st
+	}
+	return res
+}
+
+// reverseHexBytes reverses the byte order of a hex string, two digits at a
+// time. Odd-length input is returned unchanged.
+func reverseHexBytes(s string) string {
+	if len(s)%2 != 0 {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := len(s); i > 0; i -= 2 {
+		b = append(b, s[i-2:i]...)
+	}
+	return string(b)
+}
@@ -2,12 +2,15 @@ package cli
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -28,7 +31,108 @@ type Runner interface {
 	WaitForConfirmations(ctx context.Context, txid string, confirmations int64) (broadcast.TxStatus, error)
 }
 
-type Factory func(rpcURL, rpcUser, rpcPass string, pollInterval time.Duration) (Runner, error)
+type Config struct {
+	RPCURL       string
+	RPCUser      string
+	RPCPass      string
+	RPCBearer    string
+	RPCSOCKS5    string
+	PollInterval time.Duration
+
+	// ETASampleBlocks is how many recent block intervals ETA estimates
+	// average (0 = the broadcast package default).
+	ETASampleBlocks int64
+
+	// MaxResponseBytes caps RPC response bodies (0 = the broadcast
+	// package default).
+	MaxResponseBytes int64
+
+	// JSONRPCVersion is the "jsonrpc" value sent with each request ("" =
+	// the junocashd client's "1.0").
+	JSONRPCVersion string
+
+	// RecordPath appends every RPC call and response to an NDJSON transcript;
+	// ReplayPath serves RPC responses from such a transcript instead of a node.
+	RecordPath string
+	ReplayPath string
+
+	IncludeWTxID     bool
+	ZMQBlock         string
+	VerifyBestChain  bool
+	TipGatedPolling  bool
+	SettlePolls      int
+	Precheck         bool
+	RespectLocktime  bool
+	ConfirmationBase broadcast.ConfirmationBase
+	AllowedAddresses []string
+	ExpectedOutputs  []broadcast.ExpectedOutput
+	ExactOutputs     bool
+
+	// AllowShieldedOutputs lets txs with shielded outputs pass the
+	// AllowedAddresses and ExactOutputs checks, which refuse them otherwise.
+	AllowShieldedOutputs bool
+
+	// SendRawParams are raw JSON values appended to the sendrawtransaction
+	// params after the tx hex.
+	SendRawParams []json.RawMessage
+
+	// RPCURLs lists every --rpc-url given (RPCURL is the first). With more
+	// than one, submit broadcasts to all of them.
+	RPCURLs []string
+
+	// AutoReconnect is set for long-running modes (serve) so the client
+	// tracks node connectivity and reconnects in the background.
+	AutoReconnect bool
+
+	// DedupeWindow, when > 0, answers a repeat submit of the same raw tx
+	// within this long from memory instead of sending it again.
+	DedupeWindow time.Duration
+
+	// RetryOn lists extra error substrings treated as retryable.
+	RetryOn []string
+
+	// RetryBudget caps the time one RPC spends retrying (0: no cap);
+	// RetryJitter randomizes the backoff between retries.
+	RetryBudget time.Duration
+	RetryJitter bool
+
+	// RequireSynced refuses submits/waits while the node is in IBD.
+	RequireSynced bool
+
+	// RequireTxindex fails status lookups with txindex_required instead of
+	// falling back to the mempool/recent-block scan on nodes without -txindex.
+	RequireTxindex bool
+
+	// TrustTxID skips txid validation in status lookups; the caller
+	// guarantees well-formed lowercase txids.
+	TrustTxID bool
+
+	// EmptyTxIDFallback computes the txid from the raw tx when sendrawtransaction
+	// succeeds with an empty result, instead of failing with empty_txid.
+	EmptyTxIDFallback bool
+
+	// ReadOnly refuses every mutating RPC (submit, prioritise) with code
+	// read_only before anything is sent.
+	ReadOnly bool
+
+	// OTelEndpoint is the OTLP/HTTP traces endpoint; empty disables tracing.
+	OTelEndpoint string
+
+	// Webhook receives submitted/confirmed events; deliveries that fail
+	// after retries are reported as warnings on WebhookLog.
+	Webhook    string
+	WebhookLog io.Writer
+
+	// AttemptLog, when set, is called after every RPC call (--verbose).
+	AttemptLog func(broadcast.AttemptEvent)
+
+	// CacheDir enables the on-disk status cache for confirmed txs.
+	CacheDir              string
+	CacheMinConfirmations int64
+	CacheRecheck          time.Duration
+}
+
+type Factory func(cfg Config) (Runner, error)
 
 func Run(args []string) int {
 	return RunWithIO(args, defaultFactory, os.Stdout, os.Stderr)
@@ -48,8 +152,54 @@ func RunWithIO(args []string, factory Factory, stdout, stderr io.Writer) int {
 		return runSubmit(args[1:], factory, stdout, stderr)
 	case "status":
 		return runStatus(args[1:], factory, stdout, stderr)
+	case "status-batch":
+		return runStatusBatch(args[1:], factory, stdout, stderr)
+	case "drain":
+		return runDrain(args[1:], factory, stdout, stderr)
+	case "wait-all":
+		return runWaitAll(args[1:], factory, stdout, stderr)
+	case "mempool":
+		return runMempool(args[1:], factory, stdout, stderr)
+	case "mempool-entry":
+		return runMempoolEntry(args[1:], factory, stdout, stderr)
+	case "check-conflicts":
+		return runCheckConflicts(args[1:], factory, stdout, stderr)
+	case "test-accept":
+		return runTestAccept(args[1:], factory, stdout, stderr)
+	case "address-txids":
+		return runAddressTxids(args[1:], factory, stdout, stderr)
+	case "utxo":
+		return runUTXO(args[1:], factory, stdout, stderr)
+	case "block-status":
+		return runBlockStatus(args[1:], factory, stdout, stderr)
+	case "fee":
+		return runFee(args[1:], factory, stdout, stderr)
+	case "verify-inclusion":
+		return runVerifyInclusion(args[1:], factory, stdout, stderr)
+	case "outputs":
+		return runOutputs(args[1:], factory, stdout, stderr)
+	case "dump":
+		return runDump(args[1:], factory, stdout, stderr)
+	case "node-health":
+		return runNodeHealth(args[1:], factory, stdout, stderr)
+	case "doctor":
+		return runDoctor(args[1:], factory, stdout, stderr)
+	case "policy":
+		return runPolicy(args[1:], factory, stdout, stderr)
+	case "prioritise":
+		return runPrioritise(args[1:], factory, stdout, stderr)
+	case "resubmit-wallet":
+		return runResubmitWallet(args[1:], factory, stdout, stderr)
+	case "op-result":
+		return runOpResult(args[1:], factory, stdout, stderr)
+	case "psbt-decode":
+		return runPSBTDecode(args[1:], factory, stdout, stderr)
+	case "psbt-broadcast":
+		return runPSBTBroadcast(args[1:], factory, stdout, stderr)
 	case "serve":
 		return runServe(args[1:], factory, stdout, stderr)
+	case "schema":
+		return runSchema(args[1:], stdout, stderr)
 	default:
 		fmt.Fprintf(stderr, "unknown command: %s\n\n", args[0])
 		writeUsage(stderr)
@@ -63,189 +213,914 @@ func writeUsage(w io.Writer) {
 	fmt.Fprintln(w, "Submit signed raw transactions to junocashd and report status.")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Usage:")
-	fmt.Fprintln(w, "  juno-broadcast submit --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --raw-tx-hex <hex> [--confirmations <n>] [--poll <duration>] [--json]")
-	fmt.Fprintln(w, "  juno-broadcast status --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --txid <txid> [--json]")
-	fmt.Fprintln(w, "  juno-broadcast serve --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --listen <addr> [--poll <duration>]")
+	fmt.Fprintln(w, "  juno-broadcast submit --rpc-url <url> --rpc-user <user> --rpc-pass <pass> (--raw-tx-hex <hex> | --raw-tx-file <path> [--raw-tx-gzip]) [--confirmations <n> | --min-blocks-on-top <k>] [--poll <duration>] [--zmq-block <endpoint>] [--verify-best-chain] [--tip-gated] [--settle <duration>] [--assert-min-feerate <sat/vb>] [--on-confirmed <cmd>] [--webhook <url>] [--allow-address-file <path>] [--expect-output <address>:<amount> ... [--exact-outputs]] [--allow-shielded-outputs] [--precheck] [--respect-locktime] [--node-warnings] [--with-entry] [--timings] [--require-nodes <k>] [--compare-txid <txid>] [--auto-bump [--max-bumps <n>]] [--rpc-param <json> ...] [--include-wtxid] [--txid-byte-order display|internal] [--verbose] [--output-file <path>[,compact|pretty|yaml] ...] [--json [--fields <name,...>] [--json-errors-stderr] [--output-format compact|pretty|yaml]]")
+	fmt.Fprintln(w, "  juno-broadcast submit --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --raw-tx-url <url> [--confirmations <n>] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast submit --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --raw-tx-clipboard [--confirmations <n>] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast submit --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --raw-tx-fifo <path> [--stop-on-error] [--dedupe] [--dedupe-window <duration>] [--stats[=text]]")
+	fmt.Fprintln(w, "  juno-broadcast status --rpc-url <url> --rpc-user <user> --rpc-pass <pass> (--txid <txid> | --raw-tx-hex <hex> | --raw-tx-file <path>) [--timeout <duration>] [--cache-dir <dir>] [--state-file <path> --confirmations <n>] [--eta --confirmations <n> [--eta-sample-blocks <k>]] [--summary-only [--found-required]] [--found-grace <duration> [--poll <duration>]] [--trust-txid] [--txid-byte-order display|internal] [--plus-blocks <k> [--confirmations <n>]] [--raw] [--verbose] [--json [--fields <name,...>] [--pretty] [--json-errors-stderr]]")
+	fmt.Fprintln(w, "  juno-broadcast status-batch --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --txid-file <path|-> [--newer-than <duration>] [--cache-dir <dir>] [--stats[=text]]")
+	fmt.Fprintln(w, "  juno-broadcast drain --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --queue-dir <path> [--poll <duration>] [--retry-delay <duration>] [--dedupe-window <duration>] [--once]")
+	fmt.Fprintln(w, "  juno-broadcast wait-all --rpc-url <url> --rpc-user <user> --rpc-pass <pass> (--txid <txid> ... | --txid-file <path|->, one txid[,confirmations] per line) [--confirmations <n>] [--min-success <n> | --quorum <fraction>] [--timeout <duration>] [--poll <duration>] [--trust-txid] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast mempool --rpc-url <url> --rpc-user <user> --rpc-pass <pass> [--count] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast mempool-entry --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --txid <txid> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast check-conflicts --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --raw-tx-hex <hex> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast test-accept --rpc-url <url> --rpc-user <user> --rpc-pass <pass> (--raw-tx-hex <hex> ... | --raw-tx-file <path|->) [--json]")
+	fmt.Fprintln(w, "  juno-broadcast address-txids --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --address <addr> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast utxo --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --outpoint <txid:vout> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast verify-inclusion --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --txid <txid> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast block-status --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --hash <blockhash> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast fee --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --txid <txid> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast outputs --rpc-url <url> --rpc-user <user> --rpc-pass <pass> (--raw-tx-hex <hex> | --raw-tx-file <path>) [--json]")
+	fmt.Fprintln(w, "  juno-broadcast dump --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --txid <txid> --out <path> [--hex] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast node-health --rpc-url <url> --rpc-user <user> --rpc-pass <pass> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast doctor --rpc-url <url> --rpc-user <user> --rpc-pass <pass> [--timeout <duration>] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast policy --rpc-url <url> --rpc-user <user> --rpc-pass <pass> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast prioritise --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --txid <txid> --fee-delta <zat> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast resubmit-wallet --rpc-url <url> --rpc-user <user> --rpc-pass <pass> [--timeout <duration>] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast op-result --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --opid <opid> [--wait [--poll <duration>] [--timeout <duration>]] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast psbt-decode --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --psbt <base64> [--pretty] [--json]")
+	fmt.Fprintln(w, "  juno-broadcast psbt-broadcast --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --psbt <base64> [--json]")
+	fmt.Fprintln(w, "  juno-broadcast serve --rpc-url <url> --rpc-user <user> --rpc-pass <pass> --listen <addr> [--poll <duration>] [--webhook <url>] [--dedupe-window <duration>]")
+	fmt.Fprintln(w, "  juno-broadcast schema")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Run 'juno-broadcast <command> --help' for a command's flags.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "RPC flags (all commands):")
+	fmt.Fprintln(w, "  --rpc-url <url>          node RPC URL; repeat to submit to several nodes")
+	fmt.Fprintln(w, "  --rpc-bearer <token>     send Authorization: Bearer <token> instead of basic auth")
+	fmt.Fprintln(w, "  --rpc-user-command <cmd>, --rpc-pass-command <cmd>  read the RPC username/password from a helper's stdout")
+	fmt.Fprintln(w, "  --rpc-socks5 <host:port> reach the node through a SOCKS5 proxy (e.g. Tor for .onion URLs)")
+	fmt.Fprintln(w, "  --rpc-max-response-bytes <n> fail RPCs whose response body exceeds n bytes (default 8 MiB - 1)")
+	fmt.Fprintln(w, "  --rpc-jsonrpc-version <v> jsonrpc field sent with each request: 1.0 (default) or 2.0")
+	fmt.Fprintln(w, "  --record <path>          write an NDJSON transcript of the RPC traffic")
+	fmt.Fprintln(w, "  --replay <path>          answer RPCs offline from a --record transcript")
+	fmt.Fprintln(w, "  --retry-on <substr,...>  extra error substrings to retry on (adds to the built-in transient errors)")
+	fmt.Fprintln(w, "  --retry-budget <d>       stop retrying an RPC after this much total time (default 0: no cap)")
+	fmt.Fprintln(w, "  --retry-jitter           randomize the backoff between retries (full jitter)")
+	fmt.Fprintln(w, "  --confirmation-base <m>  block-inclusive (default, node count) or block-exclusive (exclude the containing block)")
+	fmt.Fprintln(w, "  --require-synced         refuse to submit/wait while the node is in initial block download")
+	fmt.Fprintln(w, "  --require-txindex        fail status lookups with txindex_required on nodes without -txindex")
+	fmt.Fprintln(w, "  --read-only              refuse sendrawtransaction, prioritisetransaction, bumpfee, abandontransaction, z_getoperationresult (code read_only); lookups still work")
+	fmt.Fprintln(w, "  --empty-txid-fallback    compute the txid from the raw tx when sendrawtransaction returns an empty one")
+	fmt.Fprintln(w, "  --otel-endpoint <url>    export spans over OTLP/HTTP (build with -tags otel)")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Env:")
-	fmt.Fprintln(w, "  JUNO_RPC_URL, JUNO_RPC_USER, JUNO_RPC_PASS")
+	fmt.Fprintln(w, "  JUNO_RPC_URL, JUNO_RPC_USER, JUNO_RPC_PASS, JUNO_RPC_BEARER")
 }
 
 func runSubmit(args []string, factory Factory, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("submit", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 
-	var rpcURL string
-	var rpcUser string
-	var rpcPass string
+	var rf rpcFlags
 	var rawTxHex string
 	var rawTxFile string
+	var rawTxURL string
+	var rawTxClipboard bool
+	var rawTxFifo string
+	var rawTxGzip bool
+	var stopOnError bool
+	var dedupe bool
+	var dedupeWindow time.Duration
+	var txidOrder txidByteOrder
 	var confirmations int64
+	var minBlocksOnTop int64
 	var pollStr string
+	var minPoll time.Duration
+	var assertMinFeerate float64
+	var includeWTxID bool
+	var zmqBlock string
+	var verifyBestChain bool
+	var tipGated bool
+	var settle time.Duration
+	var precheck bool
+	var webhook string
+	var onConfirmed string
+	var allowAddressFile string
+	var expectOutputs stringList
+	var exactOutputs bool
+	var allowShielded bool
+	var statsMode statsFlag
+	var verbose bool
 	var jsonOut bool
+	var jsonErrorsStderr bool
+	var outputFiles stringList
+	var outputFormat string
+	var autoBump bool
+	var maxBumps int
+	var rpcParams stringList
+	var respectLocktime bool
+	var compareTxID string
+	var nodeWarnings bool
+	var withEntry bool
+	var fieldsStr string
+	var requireNodes int
+	var timings bool
 
-	fs.StringVar(&rpcURL, "rpc-url", "", "junocashd RPC URL")
-	fs.StringVar(&rpcUser, "rpc-user", "", "junocashd RPC username")
-	fs.StringVar(&rpcPass, "rpc-pass", "", "junocashd RPC password")
+	rf.register(fs)
 	fs.StringVar(&rawTxHex, "raw-tx-hex", "", "signed raw tx hex")
 	fs.StringVar(&rawTxFile, "raw-tx-file", "", "path to file containing signed raw tx hex")
+	fs.BoolVar(&rawTxGzip, "raw-tx-gzip", false, "with --raw-tx-file, gunzip the file (raw or base64-wrapped gzip, detected by its magic bytes) before decoding the tx from hex or base64")
+	fs.StringVar(&rawTxURL, "raw-tx-url", "", "http(s) URL to fetch signed raw tx hex from")
+	fs.BoolVar(&rawTxClipboard, "raw-tx-clipboard", false, "read signed raw tx hex from the system clipboard (build with -tags clipboard)")
+	fs.StringVar(&rawTxFifo, "raw-tx-fifo", "", "path to a FIFO to stream signed raw tx hex lines from (NDJSON output)")
+	fs.BoolVar(&stopOnError, "stop-on-error", false, "stop streaming on the first failed submit")
+	fs.BoolVar(&dedupe, "dedupe", false, "with --raw-tx-fifo, skip txs the node already has (in mempool or on chain) and report them as already_present")
+	fs.DurationVar(&dedupeWindow, "dedupe-window", 0, "with --raw-tx-fifo, answer a line repeating a tx submitted within this long with its txid, without sending it again (0 = off)")
+	fs.Var(&statsMode, "stats", "with --raw-tx-fifo, write a summary to stderr when done (--stats for JSON, --stats=text for one line)")
 	fs.Int64Var(&confirmations, "confirmations", 0, "wait for N confirmations (0 = don't wait)")
+	fs.Int64Var(&minBlocksOnTop, "min-blocks-on-top", -1, "wait until at least K blocks are mined on top of the tx's block, i.e. K+1 node confirmations (-1 = off; replaces --confirmations)")
 	fs.StringVar(&pollStr, "poll", "500ms", "poll interval (e.g. 500ms, 2s)")
+	fs.DurationVar(&minPoll, "min-poll", defaultMinPoll, "smallest accepted --poll value")
+	fs.StringVar(&zmqBlock, "zmq-block", "", "junocashd hashblock ZMQ endpoint (tcp://host:port); with --confirmations, re-check on each new block and poll only every 4x --poll")
+	fs.BoolVar(&verifyBestChain, "verify-best-chain", false, "with --confirmations, re-check that the confirming block is still on the best chain before succeeding")
+	fs.BoolVar(&tipGated, "tip-gated", false, "with --confirmations, re-read the tx's status only when getbestblockhash reports a new block (one cheap call per poll between blocks)")
+	fs.DurationVar(&settle, "settle", 0, "with --confirmations, keep polling this long after the target is reached and only succeed if the tx stays at or above it (0 = off)")
+	fs.StringVar(&webhook, "webhook", "", "http(s) URL to POST submitted/confirmed events to (failures are warned about, never fatal)")
+	fs.StringVar(&onConfirmed, "on-confirmed", "", "command to run once --confirmations is reached (gets JUNO_TXID, JUNO_CONFIRMATIONS, JUNO_BLOCKHASH)")
+	fs.Float64Var(&assertMinFeerate, "assert-min-feerate", 0, "after submit, fail with feerate_below_assertion if the node's mempool fee rate is below this (sat/vB; 0 = off)")
+	fs.StringVar(&allowAddressFile, "allow-address-file", "", "refuse txs paying any address not listed in this file (one per line)")
+	fs.Var(&expectOutputs, "expect-output", "refuse the tx with output_mismatch unless it pays exactly <amount> to <address> (<address>:<amount>, repeatable)")
+	fs.BoolVar(&exactOutputs, "exact-outputs", false, "with --expect-output, also refuse txs with any other output, including any shielded one")
+	fs.BoolVar(&allowShielded, "allow-shielded-outputs", false, "let txs with shielded outputs, whose recipients cannot be checked, pass --allow-address-file and --exact-outputs")
+	fs.BoolVar(&autoBump, "auto-bump", false, "if the node rejects the tx with fee_too_low, have its wallet bumpfee the tx and submit the replacement")
+	fs.IntVar(&maxBumps, "max-bumps", 3, "with --auto-bump, give up after this many fee bumps")
+	fs.Var(&rpcParams, "rpc-param", "append this raw JSON value to the sendrawtransaction params, after the tx hex (repeatable, in order)")
+	fs.IntVar(&requireNodes, "require-nodes", 0, "with several --rpc-url, only succeed once at least this many of the nodes see the tx in their mempool or on chain (0 = off)")
+	fs.StringVar(&compareTxID, "compare-txid", "", "fail with txid_mismatch unless the node reports this txid for the submitted tx (in the --txid-byte-order byte order, case-insensitive)")
+	fs.BoolVar(&timings, "timings", false, "report how long the send and the --confirmations wait took under timings")
+	fs.BoolVar(&withEntry, "with-entry", false, "after submitting, embed the tx's getmempoolentry under entry (omitted if the tx already left the mempool)")
+	fs.BoolVar(&nodeWarnings, "node-warnings", false, "before submitting, print the node's own warnings (getblockchaininfo/getnetworkinfo) on one stderr line, if it reports any")
+	fs.BoolVar(&respectLocktime, "respect-locktime", false, "refuse with locktime_not_met, without broadcasting, while the tx's locktime keeps it out of the next block")
+	fs.BoolVar(&precheck, "precheck", false, "run testmempoolaccept first and fail with code rejected, without broadcasting, unless the node would accept the tx")
+	fs.Var(&txidOrder, "txid-byte-order", "byte order of reported txids: display (node form, default) or internal (reversed, as serialized)")
+	fs.BoolVar(&includeWTxID, "include-wtxid", false, "also report the witness txid (wtxid) in JSON output")
+	fs.BoolVar(&verbose, "verbose", false, "log every RPC call on stderr with a run id, elapsed time, poll iteration, and retry attempt; on failure, also list every retry attempt's error")
 	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+	fs.Var(&outputFiles, "output-file", "also append the result envelope to this file, as <path>[,compact|pretty|yaml] (repeatable)")
+	fs.StringVar(&outputFormat, "output-format", "compact", "encoding of the --json envelope on stdout: compact, pretty, or yaml")
+	fs.StringVar(&fieldsStr, "fields", "", "with --json, keep only these comma-separated fields of the result data (e.g. txid,confirmations)")
 
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintln(stderr, err.Error())
-		return 2
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
 	}
+	if len(outputFiles) > 0 || outputFormat != "compact" {
+		sinks, err := openOutputSinks(stdout, outputFormat, outputFiles)
+		if err != nil {
+			return writeErr(jsonErrWriter(stdout, stderr, jsonErrorsStderr), stderr, jsonOut, "invalid_request", err.Error())
+		}
+		defer sinks.Close()
+		stdout = sinks
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
 
-	rpcURL, rpcUser, rpcPass, err := rpcConfigFromFlags(rpcURL, rpcUser, rpcPass)
+	cfg, err := rf.config()
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "invalid_request", err.Error())
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	var fields []string
+	if fieldsStr != "" {
+		if !jsonOut || strings.TrimSpace(rawTxFifo) != "" {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "fields requires --json and is not supported with --raw-tx-fifo")
+		}
+		if fields, err = parseFields(fieldsStr, "submitData", "submitWaitData"); err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
+	}
+	if minBlocksOnTop >= 0 {
+		if confirmations != 0 {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "use only one of --confirmations and --min-blocks-on-top")
+		}
+		// The target is a node count, whatever --confirmation-base says.
+		confirmations = confirmationsForBlocksOnTop(minBlocksOnTop)
+		cfg.ConfirmationBase = broadcast.BlockInclusive
+	} else if minBlocksOnTop != -1 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "min-blocks-on-top must be >= 0")
+	}
+	if err := setWebhook(&cfg, webhook, stderr); err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	if path := strings.TrimSpace(allowAddressFile); path != "" {
+		cfg.AllowedAddresses, err = loadAddressList(path)
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
+	}
+	if exactOutputs && len(expectOutputs) == 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "exact-outputs requires --expect-output")
+	}
+	for _, v := range expectOutputs {
+		out, err := parseExpectedOutput(v)
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
+		cfg.ExpectedOutputs = append(cfg.ExpectedOutputs, out)
+	}
+	cfg.ExactOutputs = exactOutputs
+	if allowShielded && !exactOutputs && len(cfg.AllowedAddresses) == 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "allow-shielded-outputs requires --allow-address-file or --exact-outputs")
+	}
+	cfg.AllowShieldedOutputs = allowShielded
+	for _, v := range rpcParams {
+		if !json.Valid([]byte(v)) {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", fmt.Sprintf("rpc-param %q is not valid JSON", v))
+		}
+		cfg.SendRawParams = append(cfg.SendRawParams, json.RawMessage(v))
+	}
+
+	if strings.TrimSpace(rawTxFifo) != "" {
+		if strings.TrimSpace(rawTxHex) != "" || strings.TrimSpace(rawTxFile) != "" || strings.TrimSpace(rawTxURL) != "" || rawTxClipboard {
+			return writeErr(errOut, stderr, true, "invalid_request", "input source conflict (use only one of --raw-tx-hex, --raw-tx-file, --raw-tx-url, --raw-tx-clipboard, --raw-tx-fifo)")
+		}
+		if confirmations > 0 {
+			return writeErr(errOut, stderr, true, "invalid_request", "confirmations is not supported with --raw-tx-fifo")
+		}
+		if assertMinFeerate != 0 {
+			return writeErr(errOut, stderr, true, "invalid_request", "assert-min-feerate is not supported with --raw-tx-fifo")
+		}
+		if autoBump {
+			return writeErr(errOut, stderr, true, "invalid_request", "auto-bump is not supported with --raw-tx-fifo")
+		}
+		if strings.TrimSpace(compareTxID) != "" {
+			return writeErr(errOut, stderr, true, "invalid_request", "compare-txid is not supported with --raw-tx-fifo")
+		}
+		if withEntry {
+			return writeErr(errOut, stderr, true, "invalid_request", "with-entry is not supported with --raw-tx-fifo")
+		}
+		if requireNodes != 0 {
+			return writeErr(errOut, stderr, true, "invalid_request", "require-nodes is not supported with --raw-tx-fifo")
+		}
+		if timings {
+			return writeErr(errOut, stderr, true, "invalid_request", "timings is not supported with --raw-tx-fifo")
+		}
+		if dedupeWindow < 0 {
+			return writeErr(errOut, stderr, true, "invalid_request", "dedupe-window must be >= 0")
+		}
+		poll, err := parsePoll(pollStr, minPoll)
+		if err != nil {
+			return writeErr(errOut, stderr, true, "invalid_request", err.Error())
+		}
+		cfg.PollInterval = poll
+		cfg.DedupeWindow = dedupeWindow
+		r, err := factory(cfg)
+		if err != nil {
+			return writeErr(errOut, stderr, true, "internal", err.Error())
+		}
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := warmup(ctx, r); err != nil {
+			return writeErr(errOut, stderr, true, errCode(err), err.Error())
+		}
+		if nodeWarnings {
+			warnNodeWarnings(ctx, r, stderr)
+		}
+		stats := newBatchStats()
+		defer stats.write(stderr, statsMode)
+		return runSubmitFIFO(ctx, r, strings.TrimSpace(rawTxFifo), stopOnError, dedupe, txidOrder, stats, stdout)
+	}
+	if dedupeWindow != 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "dedupe-window requires --raw-tx-fifo")
 	}
 
-	raw, err := loadHexInput(rawTxHex, rawTxFile, "raw-tx-hex", "raw-tx-file")
+	var raw string
+	if rawTxGzip {
+		err = checkRawTxSources(rawTxHex, rawTxFile, rawTxURL, rawTxClipboard)
+		if err == nil && strings.TrimSpace(rawTxFile) == "" {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "raw-tx-gzip requires --raw-tx-file")
+		}
+		if err == nil {
+			raw, err = loadGzipRawTx(rawTxFile)
+		}
+	} else {
+		raw, err = loadRawTxInput(rawTxHex, rawTxFile, rawTxURL, rawTxClipboard)
+	}
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "invalid_request", err.Error())
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	onConfirmed = strings.TrimSpace(onConfirmed)
+	if onConfirmed != "" && confirmations <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "on-confirmed requires --confirmations")
+	}
+	if assertMinFeerate < 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "assert-min-feerate must be >= 0")
+	}
+	if autoBump && (len(cfg.RPCURLs) > 1 || includeWTxID) {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "auto-bump cannot be combined with several --rpc-url or --include-wtxid")
+	}
+	if autoBump && maxBumps < 1 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "max-bumps must be > 0")
+	}
+	if requireNodes < 0 || requireNodes > len(cfg.RPCURLs) || (requireNodes > 0 && len(cfg.RPCURLs) < 2) {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", fmt.Sprintf("require-nodes needs several --rpc-url and must be between 1 and their number (%d)", len(cfg.RPCURLs)))
+	}
+	if compareTxID = strings.ToLower(strings.TrimSpace(compareTxID)); compareTxID != "" {
+		if _, err := hex.DecodeString(compareTxID); err != nil || len(compareTxID) != 64 {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "compare-txid must be 32-byte hex")
+		}
+		if autoBump {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "compare-txid cannot be combined with --auto-bump, which replaces the tx")
+		}
+	}
+	zmqBlock = strings.TrimSpace(zmqBlock)
+	if zmqBlock != "" && !strings.HasPrefix(zmqBlock, "tcp://") {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "zmq-block must be a tcp://host:port endpoint")
+	}
+
+	if settle < 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "settle must be >= 0")
+	}
+	if settle > 0 && (confirmations <= 0 || zmqBlock != "") {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "settle requires --confirmations and cannot be combined with --zmq-block")
 	}
 
-	poll, err := time.ParseDuration(pollStr)
+	poll, err := parsePoll(pollStr, minPoll)
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "invalid_request", "poll must be a duration")
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
 	}
 
-	r, err := factory(rpcURL, rpcUser, rpcPass, poll)
+	cfg.PollInterval = poll
+	cfg.IncludeWTxID = includeWTxID
+	cfg.ZMQBlock = zmqBlock
+	cfg.VerifyBestChain = verifyBestChain
+	cfg.TipGatedPolling = tipGated
+	cfg.SettlePolls = settlePolls(settle, poll)
+	cfg.Precheck = precheck
+	cfg.RespectLocktime = respectLocktime
+	if verbose {
+		cfg.AttemptLog = newVerboseLog(stderr).event
+	}
+	r, err := factory(cfg)
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "internal", err.Error())
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	entryRunner, ok := r.(mempoolEntryRunner)
+	if withEntry && !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "mempool entry lookups are not supported")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	// Ctrl-C during a long --confirmations wait reports code cancelled
+	// rather than killing the process mid-output.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
-	txid, err := r.Submit(ctx, raw)
+	if nodeWarnings {
+		warnNodeWarnings(ctx, r, stderr)
+	}
+
+	var txid, wtxid string
+	var endpoints []endpointResult
+	var bumps []broadcast.FeeBump
+	start := time.Now()
+	if autoBump {
+		fb, ok := r.(feeBumpSubmitter)
+		if !ok {
+			return writeErr(errOut, stderr, jsonOut, "internal", "auto-bump is not supported")
+		}
+		txid, bumps, err = fb.SubmitWithFeeBump(ctx, raw, maxBumps)
+		bumps = txidOrder.bumps(bumps)
+		if !jsonOut {
+			for _, b := range bumps {
+				fmt.Fprintf(stderr, "bumped fee: %s -> %s (fee %g -> %g)\n", b.OrigTxID, b.TxID, b.OrigFee, b.Fee)
+			}
+		}
+	} else if ms, ok := r.(multiSubmitter); ok && len(cfg.RPCURLs) > 1 {
+		txid, endpoints, err = submitAll(ctx, ms, raw, stderr, jsonOut)
+	} else if ds, ok := r.(detailedSubmitter); ok && includeWTxID {
+		var res broadcast.SubmitResult
+		res, err = ds.SubmitDetailed(ctx, raw)
+		txid, wtxid = res.TxID, res.WTxID
+		if wtxid != "" {
+			wtxid = txidOrder.format(wtxid)
+		}
+	} else {
+		txid, err = r.Submit(ctx, raw)
+	}
+	sent := time.Now()
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "node_rpc_error", err.Error())
+		if verbose {
+			writeAttempts(stderr, err)
+		}
+		if bumps != nil {
+			return writeErrData(errOut, stderr, jsonOut, errCode(err), err.Error(), map[string]any{"bumps": bumps})
+		}
+		return writeSubmitErr(errOut, stderr, jsonOut, err)
+	}
+	// --compare-txid is given in the --txid-byte-order the txid is reported in.
+	if reported := txidOrder.format(txid); compareTxID != "" && !strings.EqualFold(reported, compareTxID) {
+		// The tx is already broadcast; this only reports that it is not the
+		// one the caller expected.
+		msg := fmt.Sprintf("node reported txid %s, expected %s (the tx was broadcast)", reported, compareTxID)
+		return writeErrData(errOut, stderr, jsonOut, "txid_mismatch", msg, map[string]any{
+			"expected": compareTxID,
+			"txid":     reported,
+		})
+	}
+	if requireNodes > 0 {
+		pw, ok := r.(propagationWaiter)
+		if !ok {
+			return writeErr(errOut, stderr, jsonOut, "internal", "propagation checks are not supported")
+		}
+		if err := pw.WaitPropagation(ctx, txid, requireNodes); err != nil {
+			// The tx is broadcast; it has just not reached enough nodes yet.
+			return writeErrData(errOut, stderr, jsonOut, errCode(err), err.Error(), map[string]any{
+				"txid":          txidOrder.format(txid),
+				"require_nodes": requireNodes,
+			})
+		}
+	}
+	for i := range endpoints {
+		endpoints[i].TxID = txidOrder.format(endpoints[i].TxID)
+	}
+
+	var feeRate *float64
+	if assertMinFeerate > 0 {
+		rate, code, err := checkMinFeeRate(ctx, r, txid, assertMinFeerate)
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, code, err.Error())
+		}
+		feeRate = &rate
+	}
+	// Read the entry before any wait, while the tx is still in the mempool.
+	var entry *broadcast.MempoolEntry
+	if withEntry {
+		entry = submittedEntry(ctx, entryRunner, txid, stderr)
 	}
 
 	if confirmations > 0 {
-		st, err := r.WaitForConfirmations(ctx, txid, confirmations)
+		wait := r.WaitForConfirmations
+		if mw, ok := r.(multiWaiter); ok && len(cfg.RPCURLs) > 1 {
+			wait = mw.WaitAcross
+		}
+		waitStart := time.Now()
+		st, err := wait(ctx, txid, confirmations)
+		confirmed := time.Now()
 		if err != nil {
-			return writeErr(stdout, stderr, jsonOut, "node_rpc_error", err.Error())
+			if verbose {
+				writeAttempts(stderr, err)
+			}
+			var timeoutErr *broadcast.WaitTimeoutError
+			if errors.As(err, &timeoutErr) {
+				data := map[string]any{
+					"txid":               txidOrder.format(txid),
+					"elapsed":            timeoutErr.Elapsed.Round(time.Millisecond).String(),
+					"last_confirmations": timeoutErr.LastStatus.Confirmations,
+					"required_confs":     timeoutErr.Target,
+				}
+				// The wait's context is spent; give the estimate a moment of
+				// its own.
+				etaCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if eta := estimateETA(etaCtx, r, txid, confirmations, io.Discard); eta != "" {
+					data["eta"] = eta
+				}
+				cancel()
+				return writeErrData(errOut, stderr, jsonOut, "timeout", err.Error(), data)
+			}
+			return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
 		}
-		return writeOK(stdout, jsonOut, map[string]any{
-			"txid":           txid,
+		payload := map[string]any{
+			"txid":           txidOrder.format(txid),
 			"in_mempool":     st.InMempool,
 			"confirmations":  st.Confirmations,
 			"blockhash":      st.BlockHash,
 			"required_confs": confirmations,
-		})
+		}
+		if wtxid != "" {
+			payload["wtxid"] = wtxid
+		}
+		if feeRate != nil {
+			payload["feerate"] = *feeRate
+		}
+		if endpoints != nil {
+			payload["endpoints"] = endpoints
+		}
+		if bumps != nil {
+			payload["bumps"] = bumps
+		}
+		if entry != nil {
+			payload["entry"] = entry
+		}
+		if timings {
+			payload["timings"] = submitTimings{
+				Send:    formatTiming(sent.Sub(start)),
+				Confirm: formatTiming(confirmed.Sub(waitStart)),
+				Total:   formatTiming(confirmed.Sub(start)),
+			}
+		}
+		if onConfirmed != "" {
+			hook := runOnConfirmed(onConfirmed, txidOrder.status(st), stderr, onConfirmedTimeout)
+			warnHook(stderr, hook)
+			payload["hook"] = hook
+		}
+		return writeOK(stdout, jsonOut, projectFields(payload, fields))
 	}
 
+	payload := map[string]any{"txid": txidOrder.format(txid)}
+	if wtxid != "" {
+		payload["wtxid"] = wtxid
+	}
+	if feeRate != nil {
+		payload["feerate"] = *feeRate
+	}
+	if endpoints != nil {
+		payload["endpoints"] = endpoints
+	}
+	if bumps != nil {
+		payload["bumps"] = bumps
+	}
+	if entry != nil {
+		payload["entry"] = entry
+	}
+	if timings {
+		payload["timings"] = submitTimings{Send: formatTiming(sent.Sub(start)), Total: formatTiming(time.Since(start))}
+	}
 	if jsonOut {
-		return writeOK(stdout, jsonOut, map[string]any{"txid": txid})
+		return writeOK(stdout, jsonOut, projectFields(payload, fields))
 	}
-	fmt.Fprintln(stdout, txid)
+	fmt.Fprintln(stdout, txidOrder.format(txid))
+	writeEnvelope(stdout, okEnvelope(payload), true)
 	return 0
 }
 
+// settlePolls converts --settle into whole polls of the wait, rounding up.
+func settlePolls(settle, poll time.Duration) int {
+	return int((settle + poll - 1) / poll)
+}
+
+// submitTimings is submit --timings: how long each phase took. The clock
+// readings are monotonic, so wall-clock changes do not skew them.
+type submitTimings struct {
+	Send    string `json:"send"`
+	Confirm string `json:"confirm,omitempty"`
+	Total   string `json:"total"`
+}
+
+func formatTiming(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
+// confirmationsForBlocksOnTop translates --min-blocks-on-top k into the
+// block-inclusive --confirmations target: the tx's own block plus k on top.
+func confirmationsForBlocksOnTop(k int64) int64 {
+	return k + 1
+}
+
 func runStatus(args []string, factory Factory, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("status", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 
-	var rpcURL string
-	var rpcUser string
-	var rpcPass string
+	var rf rpcFlags
 	var txid string
+	var rawTxHex string
+	var rawTxFile string
 	var jsonOut bool
+	var jsonErrorsStderr bool
 	var pollStr string
+	var minPoll time.Duration
+	var cacheDir string
+	var cacheMinConfs int64
+	var cacheRecheck time.Duration
+	var timeout time.Duration
+	var verbose bool
+	var txidOrder txidByteOrder
+	var stateFile string
+	var confirmations int64
+	var eta bool
+	var etaSampleBlocks int64
+	var trustTxID bool
+	var summaryOnly bool
+	var foundRequired bool
+	var foundGrace time.Duration
+	var fieldsStr string
+	var rawOut bool
+	var pretty bool
+	var plusBlocks int64
 
-	fs.StringVar(&rpcURL, "rpc-url", "", "junocashd RPC URL")
-	fs.StringVar(&rpcUser, "rpc-user", "", "junocashd RPC username")
-	fs.StringVar(&rpcPass, "rpc-pass", "", "junocashd RPC password")
+	rf.register(fs)
 	fs.StringVar(&txid, "txid", "", "transaction id")
-	fs.StringVar(&pollStr, "poll", "500ms", "poll interval (unused)")
+	fs.StringVar(&rawTxHex, "raw-tx-hex", "", "raw tx hex to derive the txid from (instead of --txid)")
+	fs.StringVar(&rawTxFile, "raw-tx-file", "", "path to file containing raw tx hex to derive the txid from (instead of --txid)")
+	fs.StringVar(&pollStr, "poll", "500ms", "with --found-grace, interval between not-found retries")
+	fs.DurationVar(&minPoll, "min-poll", defaultMinPoll, "smallest accepted --poll value")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "overall deadline for the status lookup")
+	fs.DurationVar(&foundGrace, "found-grace", 0, "if the txid is not found, keep retrying every --poll for up to this long before reporting not_found (0 = no grace)")
+	fs.StringVar(&cacheDir, "cache-dir", "", "directory for an on-disk cache of deeply confirmed tx status")
+	fs.Int64Var(&cacheMinConfs, "cache-min-confirmations", 6, "only cache txs with at least N confirmations")
+	fs.DurationVar(&cacheRecheck, "cache-recheck", 10*time.Minute, "re-verify a cached block is still on the best chain after this long")
+	fs.BoolVar(&verbose, "verbose", false, "log every RPC call on stderr with a run id, elapsed time, poll iteration, and retry attempt; on failure, also list every retry attempt's error")
+	fs.Var(&txidOrder, "txid-byte-order", "byte order of the reported txid: display (node form, default) or internal (reversed, as serialized)")
+	fs.StringVar(&stateFile, "state-file", "", "JSON file remembering each txid's last confirmation count across runs (requires --confirmations)")
+	fs.Int64Var(&confirmations, "confirmations", 0, "with --state-file, report crossed=true on the first run that sees at least N confirmations; with --eta, the target to estimate for; with --plus-blocks, the target to check")
+	fs.BoolVar(&eta, "eta", false, "estimate the time until --confirmations is reached from recent block intervals")
+	fs.Int64Var(&etaSampleBlocks, "eta-sample-blocks", 20, "with --eta, how many recent block intervals to average")
+	fs.BoolVar(&trustTxID, "trust-txid", false, "skip txid hex/length validation; a malformed --txid reaches the node as is")
+	fs.BoolVar(&summaryOnly, "summary-only", false, "print only the tx's state: unknown, mempool, or confirmed (an unknown txid is not an error)")
+	fs.BoolVar(&foundRequired, "found-required", false, "with --summary-only, fail with not_found instead of printing unknown")
+	fs.StringVar(&fieldsStr, "fields", "", "with --json, keep only these comma-separated fields of the result data (e.g. txid,confirmations)")
+	fs.Int64Var(&plusBlocks, "plus-blocks", 0, "also report the confirmations the tx will have once k more blocks are mined (and, with --confirmations, whether that meets the target)")
+	fs.BoolVar(&rawOut, "raw", false, "include the node's complete verbose getrawtransaction response under raw")
+	fs.BoolVar(&pretty, "pretty", false, "with --json, indent the envelope")
 	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
 
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintln(stderr, err.Error())
-		return 2
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
 	}
+	if pretty {
+		sinks, err := openOutputSinks(stdout, string(formatPretty), nil)
+		if err != nil {
+			return writeErr(jsonErrWriter(stdout, stderr, jsonErrorsStderr), stderr, jsonOut, "internal", err.Error())
+		}
+		stdout = sinks
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
 
-	rpcURL, rpcUser, rpcPass, err := rpcConfigFromFlags(rpcURL, rpcUser, rpcPass)
+	cfg, err := rf.config()
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "invalid_request", err.Error())
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
 	}
 
 	txid = strings.TrimSpace(txid)
-	if txid == "" {
-		return writeErr(stdout, stderr, jsonOut, "invalid_request", "txid is required")
+	hasRaw := strings.TrimSpace(rawTxHex) != "" || strings.TrimSpace(rawTxFile) != ""
+	// rawTx is set for v5+ txs, whose txid the node has to compute.
+	var rawTx string
+	switch {
+	case txid != "" && hasRaw:
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "input source conflict (use only one of --txid, --raw-tx-hex, --raw-tx-file)")
+	case hasRaw:
+		raw, err := loadHexInput(rawTxHex, rawTxFile, "raw-tx-hex", "raw-tx-file")
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
+		txid, err = broadcast.TxIDFromHex(raw)
+		if errors.Is(err, broadcast.ErrLocalTxIDUnsupported) {
+			rawTx = raw
+		} else if err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
+	case txid == "":
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "txid is required (or use --raw-tx-hex, --raw-tx-file)")
 	}
 
-	poll, err := time.ParseDuration(pollStr)
+	poll, err := parsePoll(pollStr, minPoll)
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "invalid_request", "poll must be a duration")
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	if timeout <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "timeout must be > 0")
+	}
+	if foundGrace < 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "found-grace must be >= 0")
+	}
+	stateFile = strings.TrimSpace(stateFile)
+	if plusBlocks < 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "plus-blocks must be >= 0")
+	}
+	if plusBlocks > 0 && (summaryOnly || stateFile != "" || eta || rawOut) {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "plus-blocks cannot be combined with --summary-only, --state-file, --eta, or --raw")
+	}
+	if (stateFile != "" || eta) && confirmations <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "state-file and eta require --confirmations (> 0)")
+	}
+	if confirmations > 0 && stateFile == "" && !eta && plusBlocks == 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "confirmations requires --state-file, --eta, or --plus-blocks")
+	}
+	if etaSampleBlocks <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "eta-sample-blocks must be > 0")
+	}
+	if summaryOnly && (jsonOut || stateFile != "" || eta) {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "summary-only cannot be combined with --json, --state-file, or --eta")
+	}
+	if foundRequired && !summaryOnly {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "found-required requires --summary-only")
+	}
+	if rawOut && (summaryOnly || stateFile != "" || eta) {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "raw cannot be combined with --summary-only, --state-file, or --eta")
+	}
+	if pretty && !jsonOut {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "pretty requires --json")
+	}
+	var fields []string
+	if fieldsStr != "" {
+		if !jsonOut {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "fields requires --json")
+		}
+		if fields, err = parseFields(fieldsStr, "txStatus", "statusStateData", "statusETAData", "statusRawData", "statusPlusBlocksData"); err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
 	}
 
-	r, err := factory(rpcURL, rpcUser, rpcPass, poll)
+	cfg.PollInterval = poll
+	cfg.CacheDir = strings.TrimSpace(cacheDir)
+	cfg.CacheMinConfirmations = cacheMinConfs
+	cfg.CacheRecheck = cacheRecheck
+	cfg.ETASampleBlocks = etaSampleBlocks
+	cfg.TrustTxID = trustTxID
+	if verbose {
+		cfg.AttemptLog = newVerboseLog(stderr).event
+	}
+	r, err := factory(cfg)
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "internal", err.Error())
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	st, found, err := r.Status(ctx, txid)
+	if rawTx != "" {
+		if txid, err = txidOf(ctx, r, rawTx); err != nil {
+			return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+		}
+	}
+
+	lookup := r.Status
+	var raw json.RawMessage
+	if rawOut {
+		rr, ok := r.(rawStatusRunner)
+		if !ok {
+			return writeErr(errOut, stderr, jsonOut, "internal", "raw status lookups are not supported")
+		}
+		lookup = func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+			st, found, resp, err := rr.StatusRaw(ctx, txid)
+			raw = resp
+			return st, found, err
+		}
+	}
+	st, found, err := statusWithGrace(ctx, lookup, txid, foundGrace, poll)
 	if err != nil {
-		return writeErr(stdout, stderr, jsonOut, "node_rpc_error", err.Error())
+		if verbose {
+			writeAttempts(stderr, err)
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return writeErr(errOut, stderr, jsonOut, "timeout", fmt.Sprintf("status lookup timed out after %s: %v", timeout, err))
+		}
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if summaryOnly && (found || !foundRequired) {
+		fmt.Fprintln(stdout, st.State())
+		return 0
 	}
 	if !found {
-		return writeErr(stdout, stderr, jsonOut, "not_found", "unknown txid")
+		return writeErr(errOut, stderr, jsonOut, "not_found", "unknown txid")
 	}
 
-	return writeOK(stdout, jsonOut, st)
+	var etaStr string
+	if eta {
+		etaStr = estimateETA(ctx, r, txid, confirmations, stderr)
+	}
+	if stateFile != "" {
+		res, err := recordConfirmations(stateFile, st, confirmations)
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+		}
+		res.TxStatus = txidOrder.status(res.TxStatus)
+		res.ETA = etaStr
+		return writeOK(stdout, jsonOut, projectFields(res, fields))
+	}
+	if eta {
+		return writeOK(stdout, jsonOut, projectFields(statusETAResult{TxStatus: txidOrder.status(st), RequiredConfs: confirmations, ETA: etaStr}, fields))
+	}
+	if plusBlocks > 0 {
+		return writeOK(stdout, jsonOut, projectFields(projectConfirmations(txidOrder.status(st), plusBlocks, confirmations, cfg.ConfirmationBase), fields))
+	}
+	if rawOut {
+		if raw == nil {
+			fmt.Fprintln(stderr, "note: no verbose getrawtransaction response; the tx was found through the mempool or recent-block fallback")
+		}
+		return writeOK(stdout, jsonOut, projectFields(statusRawResult{TxStatus: txidOrder.status(st), Raw: raw}, fields))
+	}
+	return writeOK(stdout, jsonOut, projectFields(txidOrder.status(st), fields))
+}
+
+// statusWithGrace is lookup, retried every poll while the txid is not found
+// until grace has passed. It smooths over the race between a submit and the
+// node indexing the tx. The grace ends early, reporting not found, when ctx
+// has less time left than one more poll.
+func statusWithGrace(ctx context.Context, lookup func(context.Context, string) (broadcast.TxStatus, bool, error), txid string, grace, poll time.Duration) (broadcast.TxStatus, bool, error) {
+	deadline := time.Now().Add(grace)
+	for {
+		st, found, err := lookup(ctx, txid)
+		if err != nil || found || time.Now().Add(poll).After(deadline) {
+			return st, found, err
+		}
+		if d, ok := ctx.Deadline(); ok && time.Now().Add(poll).After(d) {
+			return st, found, nil
+		}
+		timer := time.NewTimer(poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return st, found, nil
+		case <-timer.C:
+		}
+	}
+}
+
+// statusPlusBlocksResult is status output with --plus-blocks.
+type statusPlusBlocksResult struct {
+	broadcast.TxStatus
+	PlusBlocks             int64 `json:"plus_blocks"`
+	ProjectedConfirmations int64 `json:"projected_confirmations"`
+	RequiredConfs          int64 `json:"required_confs,omitempty"`
+	MeetsTarget            *bool `json:"meets_target,omitempty"`
+}
+
+// projectConfirmations reports the node count st will have after k more
+// blocks: current + k, where a mempool tx is assumed to be mined in the next
+// block. With a target (> 0, counted per base) it also reports whether the
+// projection reaches it.
+func projectConfirmations(st broadcast.TxStatus, k, target int64, base broadcast.ConfirmationBase) statusPlusBlocksResult {
+	res := statusPlusBlocksResult{TxStatus: st, PlusBlocks: k, ProjectedConfirmations: st.Confirmations + k}
+	if target > 0 {
+		need := target
+		if base == broadcast.BlockExclusive {
+			need++
+		}
+		meets := res.ProjectedConfirmations >= need
+		res.RequiredConfs, res.MeetsTarget = target, &meets
+	}
+	return res
+}
+
+// rawStatusRunner is implemented by runners that can return the node's
+// verbose getrawtransaction response along with the status.
+type rawStatusRunner interface {
+	StatusRaw(ctx context.Context, txid string) (broadcast.TxStatus, bool, json.RawMessage, error)
+}
+
+// statusRawResult is status output with --raw.
+type statusRawResult struct {
+	broadcast.TxStatus
+	Raw json.RawMessage `json:"raw,omitempty"`
 }
 
 func runServe(args []string, factory Factory, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 
-	var rpcURL string
-	var rpcUser string
-	var rpcPass string
+	var rf rpcFlags
 	var listen string
 	var pollStr string
+	var minPoll time.Duration
 	var maxBodyBytes int64
+	var webhook string
+	var dedupeWindow time.Duration
 
-	fs.StringVar(&rpcURL, "rpc-url", "", "junocashd RPC URL")
-	fs.StringVar(&rpcUser, "rpc-user", "", "junocashd RPC username")
-	fs.StringVar(&rpcPass, "rpc-pass", "", "junocashd RPC password")
+	rf.register(fs)
 	fs.StringVar(&listen, "listen", "127.0.0.1:8080", "listen address (host:port)")
 	fs.StringVar(&pollStr, "poll", "500ms", "poll interval (e.g. 500ms, 2s)")
+	fs.DurationVar(&minPoll, "min-poll", defaultMinPoll, "smallest accepted --poll value")
 	fs.Int64Var(&maxBodyBytes, "max-body-bytes", 20<<20, "max request body bytes")
+	fs.StringVar(&webhook, "webhook", "", "http(s) URL to POST submitted/confirmed events to (failures are warned about, never fatal)")
+	fs.DurationVar(&dedupeWindow, "dedupe-window", 0, "answer a submit repeating a tx submitted within this long with its txid, without sending it again (0 = off)")
 
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintln(stderr, err.Error())
-		return 2
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
 	}
 
-	rpcURL, rpcUser, rpcPass, err := rpcConfigFromFlags(rpcURL, rpcUser, rpcPass)
+	cfg, err := rf.config()
 	if err != nil {
 		return writeErr(stdout, stderr, false, "invalid_request", err.Error())
 	}
+	if dedupeWindow < 0 {
+		return writeErr(stdout, stderr, false, "invalid_request", "dedupe-window must be >= 0")
+	}
+	cfg.DedupeWindow = dedupeWindow
 
 	listen = strings.TrimSpace(listen)
 	if listen == "" {
 		return writeErr(stdout, stderr, false, "invalid_request", "listen is required")
 	}
+	if err := setWebhook(&cfg, webhook, stderr); err != nil {
+		return writeErr(stdout, stderr, false, "invalid_request", err.Error())
+	}
 
-	poll, err := time.ParseDuration(pollStr)
+	poll, err := parsePoll(pollStr, minPoll)
 	if err != nil {
-		return writeErr(stdout, stderr, false, "invalid_request", "poll must be a duration")
+		return writeErr(stdout, stderr, false, "invalid_request", err.Error())
 	}
 
-	r, err := factory(rpcURL, rpcUser, rpcPass, poll)
+	cfg.PollInterval = poll
+	cfg.AutoReconnect = true
+	r, err := factory(cfg)
 	if err != nil {
 		return writeErr(stdout, stderr, false, "internal", err.Error())
 	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
 
 	api, err := httpapi.New(r, httpapi.WithMaxBodyBytes(maxBodyBytes))
 	if err != nil {
@@ -284,9 +1159,386 @@ func runServe(args []string, factory Factory, stdout, stderr io.Writer) int {
 	}
 }
 
-func defaultFactory(rpcURL, rpcUser, rpcPass string, pollInterval time.Duration) (Runner, error) {
-	rpc := junocashd.New(rpcURL, rpcUser, rpcPass)
-	return broadcast.New(rpc, broadcast.WithPollInterval(pollInterval))
+func defaultFactory(cfg Config) (Runner, error) {
+	user, pass := cfg.RPCUser, cfg.RPCPass
+	var rpcOpts []junocashd.Option
+	if cfg.RPCBearer != "" {
+		user, pass = "", ""
+	}
+	hc := broadcast.RPCHTTPClient(cfg.RPCBearer, cfg.RPCSOCKS5, cfg.MaxResponseBytes, broadcast.WithJSONRPCVersion(cfg.JSONRPCVersion))
+	rpcOpts = append(rpcOpts, junocashd.WithHTTPClient(hc))
+	cr := &clientRunner{}
+	// Transcripts record single calls only, so with --record or --replay
+	// bulk lookups fall back to one call per tx.
+	rpc := broadcast.NewBatchRPC(junocashd.New(cfg.RPCURL, user, pass, rpcOpts...), cfg.RPCURL, user, pass, hc)
+	switch {
+	case cfg.ReplayPath != "":
+		f, err := os.Open(cfg.ReplayPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		replay, err := broadcast.NewTranscriptReplay(f)
+		if err != nil {
+			return nil, err
+		}
+		rpc = replay
+	case cfg.RecordPath != "":
+		f, err := os.OpenFile(cfg.RecordPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		rpc = broadcast.NewTranscriptRecorder(rpc, f)
+		cr.transcript = f
+	}
+
+	opts := []broadcast.Option{
+		broadcast.WithPollInterval(cfg.PollInterval),
+		broadcast.WithRetryableMatchers(cfg.RetryOn),
+		broadcast.WithRetryBudget(cfg.RetryBudget),
+		broadcast.WithInProcessDedup(cfg.DedupeWindow),
+		broadcast.WithRetryJitter(cfg.RetryJitter),
+		broadcast.WithRequireSynced(cfg.RequireSynced),
+		broadcast.WithRequireTxindex(cfg.RequireTxindex),
+		broadcast.WithSkipTxIDValidation(cfg.TrustTxID),
+		broadcast.WithReadOnly(cfg.ReadOnly),
+		broadcast.WithEmptyTxIDFallback(cfg.EmptyTxIDFallback),
+		broadcast.WithIncludeWTxID(cfg.IncludeWTxID),
+		broadcast.WithConfirmationBase(cfg.ConfirmationBase),
+		broadcast.WithAllowedAddresses(cfg.AllowedAddresses),
+		broadcast.WithExpectedOutputs(cfg.ExpectedOutputs, cfg.ExactOutputs),
+		broadcast.WithAllowShieldedOutputs(cfg.AllowShieldedOutputs),
+		broadcast.WithETASampleBlocks(cfg.ETASampleBlocks),
+		broadcast.WithZMQ(cfg.ZMQBlock),
+		broadcast.WithVerifyBestChain(cfg.VerifyBestChain),
+		broadcast.WithTipGatedPolling(cfg.TipGatedPolling),
+		broadcast.WithSettlePolls(cfg.SettlePolls),
+		broadcast.WithPrecheck(cfg.Precheck),
+		broadcast.WithSendRawParams(cfg.SendRawParams),
+		broadcast.WithRespectLocktime(cfg.RespectLocktime),
+		broadcast.WithAttemptLog(cfg.AttemptLog),
+	}
+	if cfg.Webhook != "" {
+		log := cfg.WebhookLog
+		opts = append(opts, broadcast.WithWebhook(cfg.Webhook, nil), broadcast.WithWebhookErrorHandler(func(err error) {
+			if log != nil {
+				fmt.Fprintf(log, "warning: %v\n", err)
+			}
+		}))
+	}
+	if cfg.AutoReconnect {
+		opts = append(opts, broadcast.WithAutoReconnect(500*time.Millisecond, 30*time.Second))
+	}
+
+	if cfg.CacheDir != "" {
+		cache, err := broadcast.NewStatusCache(cfg.CacheDir, cfg.CacheMinConfirmations, cfg.CacheRecheck)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, broadcast.WithStatusCache(cache))
+	}
+
+	if cfg.OTelEndpoint != "" {
+		tracer, shutdown, err := newTracer(context.Background(), cfg.OTelEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, broadcast.WithTracer(tracer))
+		cr.shutdown = shutdown
+	}
+
+	c, err := broadcast.New(rpc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cr.Client = c
+
+	if len(cfg.RPCURLs) > 1 {
+		for _, u := range cfg.RPCURLs {
+			name := redactURL(u)
+			cr.names = append(cr.names, name)
+			cr.endpoints = append(cr.endpoints, broadcast.NamedRPC(name, junocashd.New(u, user, pass, rpcOpts...)))
+		}
+	}
+	return cr, nil
+}
+
+// detailedSubmitter is implemented by runners that can also report the
+// wtxid of a submitted tx.
+type detailedSubmitter interface {
+	SubmitDetailed(ctx context.Context, rawTxHex string) (broadcast.SubmitResult, error)
+}
+
+// feeBumpSubmitter is implemented by runners that can bumpfee a tx the node
+// rejected for its fee and submit the replacement.
+type feeBumpSubmitter interface {
+	SubmitWithFeeBump(ctx context.Context, rawTxHex string, maxBumps int) (string, []broadcast.FeeBump, error)
+}
+
+// txidRunner is implemented by runners that can ask the node for the txids
+// broadcast.TxIDFromHex cannot compute (v5+).
+type txidRunner interface {
+	TxID(ctx context.Context, rawTxHex string) (string, error)
+}
+
+// txidOf computes raw's txid locally, asking the node through r for v5+ txs.
+func txidOf(ctx context.Context, r Runner, raw string) (string, error) {
+	txid, err := broadcast.TxIDFromHex(raw)
+	if tr, ok := r.(txidRunner); ok && errors.Is(err, broadcast.ErrLocalTxIDUnsupported) {
+		return tr.TxID(ctx, raw)
+	}
+	return txid, err
+}
+
+// clientRunner is the Runner built by defaultFactory: the broadcast client for
+// the primary node plus CLI-level resources tied to the command's lifetime.
+type clientRunner struct {
+	*broadcast.Client
+	endpoints  []broadcast.RPC
+	names      []string
+	transcript io.Closer
+	shutdown   func(context.Context) error
+}
+
+func (r *clientRunner) Endpoints() []string { return r.names }
+
+func (r *clientRunner) SubmitAll(ctx context.Context, rawTxHex string) (map[string]string, []error) {
+	return r.Client.SubmitToAll(ctx, rawTxHex, r.endpoints)
+}
+
+func (r *clientRunner) WaitAcross(ctx context.Context, txid string, confirmations int64) (broadcast.TxStatus, error) {
+	return r.Client.WaitForConfirmationsAcross(ctx, txid, confirmations, r.endpoints)
+}
+
+func (r *clientRunner) WaitPropagation(ctx context.Context, txid string, k int) error {
+	return r.Client.WaitForPropagation(ctx, txid, r.endpoints, k)
+}
+
+func (r *clientRunner) Close() error {
+	_ = r.Client.Close()
+	if r.transcript != nil {
+		_ = r.transcript.Close()
+	}
+	if r.shutdown == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return r.shutdown(ctx)
+}
+
+// defaultMinPoll is the smallest --poll accepted unless --min-poll lowers it;
+// shorter intervals just spin the wait loop.
+const defaultMinPoll = 10 * time.Millisecond
+
+func parsePoll(s string, min time.Duration) (time.Duration, error) {
+	poll, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, errors.New("poll must be a duration")
+	}
+	if poll <= 0 {
+		return 0, errors.New("poll must be > 0")
+	}
+	if poll < min {
+		return 0, fmt.Errorf("poll must be >= %s (lower --min-poll to allow shorter intervals)", min)
+	}
+	return poll, nil
+}
+
+// setWebhook validates --webhook and stores it in cfg, with warnings going
+// to stderr.
+func setWebhook(cfg *Config, raw string, stderr io.Writer) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook must be an http(s) URL")
+	}
+	cfg.Webhook = raw
+	cfg.WebhookLog = stderr
+	return nil
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.User = nil
+	return u.String()
+}
+
+// parseFlags parses args into fs. On -h/--help it prints the subcommand's
+// flags to stdout and reports exit code 0; on a parse error it reports 2.
+func parseFlags(fs *flag.FlagSet, args []string, stdout, stderr io.Writer) (int, bool) {
+	err := fs.Parse(args)
+	if err == nil {
+		return 0, true
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		writeFlagUsage(stdout, fs)
+		return 0, false
+	}
+	fmt.Fprintln(stderr, err.Error())
+	return 2, false
+}
+
+func writeFlagUsage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: juno-broadcast %s [flags]\n", fs.Name())
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Flags:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+}
+
+type rpcFlags struct {
+	urls           stringList
+	user           string
+	pass           string
+	userCommand    string
+	passCommand    string
+	bearer         string
+	socks5         string
+	maxResponse    int64
+	jsonrpcVersion string
+	record         string
+	replay         string
+	retryOn        string
+	retryBudget    time.Duration
+	retryJitter    bool
+	otelEndpoint   string
+	requireSynced  bool
+	requireTxindex bool
+	readOnly       bool
+	emptyTxIDLocal bool
+	confBase       string
+}
+
+func (f *rpcFlags) register(fs *flag.FlagSet) {
+	fs.Var(&f.urls, "rpc-url", "junocashd RPC URL (repeatable; submit broadcasts to every URL)")
+	fs.StringVar(&f.user, "rpc-user", "", "junocashd RPC username")
+	fs.StringVar(&f.pass, "rpc-pass", "", "junocashd RPC password")
+	fs.StringVar(&f.userCommand, "rpc-user-command", "", "command whose stdout (trimmed) is the RPC username, e.g. a secrets manager helper (split on whitespace, no shell)")
+	fs.StringVar(&f.passCommand, "rpc-pass-command", "", "command whose stdout (trimmed) is the RPC password, e.g. a secrets manager helper (split on whitespace, no shell)")
+	fs.StringVar(&f.bearer, "rpc-bearer", "", "bearer token for the RPC endpoint (replaces basic auth; or set JUNO_RPC_BEARER)")
+	fs.StringVar(&f.socks5, "rpc-socks5", "", "dial the RPC endpoint through this SOCKS5 proxy (host:port, e.g. Tor at 127.0.0.1:9050; allows .onion URLs)")
+	fs.Int64Var(&f.maxResponse, "rpc-max-response-bytes", broadcast.DefaultMaxResponseBytes, "fail RPCs whose response body is larger than this many bytes")
+	fs.StringVar(&f.jsonrpcVersion, "rpc-jsonrpc-version", "1.0", "jsonrpc version sent with each RPC request: 1.0 or 2.0 (for proxies that validate it)")
+	fs.StringVar(&f.record, "record", "", "write an NDJSON transcript of every RPC call to this path")
+	fs.StringVar(&f.replay, "replay", "", "serve RPC responses from a transcript written by --record instead of a node")
+	fs.StringVar(&f.retryOn, "retry-on", "", "comma-separated error substrings to also treat as retryable (case-insensitive)")
+	fs.DurationVar(&f.retryBudget, "retry-budget", 0, "stop retrying an RPC once this much time has been spent on it (0: attempt count only)")
+	fs.BoolVar(&f.retryJitter, "retry-jitter", false, "randomize the wait between retries (full jitter)")
+	fs.StringVar(&f.confBase, "confirmation-base", "block-inclusive", "how confirmation targets are counted: block-inclusive (node count) or block-exclusive (blocks on top of the containing block)")
+	fs.BoolVar(&f.requireSynced, "require-synced", false, "refuse to submit or wait while the node is in initial block download")
+	fs.BoolVar(&f.requireTxindex, "require-txindex", false, "fail status lookups with txindex_required when the node lacks -txindex instead of scanning recent blocks")
+	fs.BoolVar(&f.readOnly, "read-only", false, "refuse every mutating RPC (sendrawtransaction, prioritisetransaction, bumpfee, abandontransaction, z_getoperationresult) with code read_only")
+	fs.BoolVar(&f.emptyTxIDLocal, "empty-txid-fallback", false, "when sendrawtransaction succeeds with an empty txid (a misbehaving proxy), compute the txid from the raw tx instead of failing with empty_txid")
+	fs.StringVar(&f.otelEndpoint, "otel-endpoint", "", "OTLP/HTTP traces endpoint URL (requires a build with -tags otel)")
+}
+
+func (f *rpcFlags) config() (Config, error) {
+	var primary string
+	if len(f.urls) > 0 {
+		primary = f.urls[0]
+	}
+	confBase, err := broadcast.ParseConfirmationBase(f.confBase)
+	if err != nil {
+		return Config{}, errors.New(strings.TrimPrefix(err.Error(), "broadcast: "))
+	}
+	record, replay := strings.TrimSpace(f.record), strings.TrimSpace(f.replay)
+	if record != "" && replay != "" {
+		return Config{}, errors.New("use only one of --record and --replay")
+	}
+	// An explicit --rpc-user/--rpc-pass wins over its command, which wins
+	// over the environment.
+	user, pass := f.user, f.pass
+	if strings.TrimSpace(user) == "" && strings.TrimSpace(f.userCommand) != "" {
+		if user, err = credentialCommand(f.userCommand, "rpc-user-command"); err != nil {
+			return Config{}, err
+		}
+	}
+	if strings.TrimSpace(pass) == "" && strings.TrimSpace(f.passCommand) != "" {
+		if pass, err = credentialCommand(f.passCommand, "rpc-pass-command"); err != nil {
+			return Config{}, err
+		}
+	}
+	url, user, pass, err := rpcConfigFromFlags(primary, user, pass)
+	if err != nil && replay == "" {
+		return Config{}, err
+	}
+	bearer := strings.TrimSpace(f.bearer)
+	if bearer == "" {
+		bearer = strings.TrimSpace(os.Getenv("JUNO_RPC_BEARER"))
+	}
+	socks5 := strings.TrimSpace(f.socks5)
+	if socks5 != "" {
+		if _, _, err := net.SplitHostPort(socks5); err != nil {
+			return Config{}, errors.New("rpc-socks5 must be host:port")
+		}
+	}
+	if f.maxResponse <= 0 {
+		return Config{}, errors.New("rpc-max-response-bytes must be > 0")
+	}
+	if f.retryBudget < 0 {
+		return Config{}, errors.New("retry-budget must be >= 0")
+	}
+	jsonrpcVersion := strings.TrimSpace(f.jsonrpcVersion)
+	if jsonrpcVersion != "1.0" && jsonrpcVersion != "2.0" {
+		return Config{}, errors.New("rpc-jsonrpc-version must be 1.0 or 2.0")
+	}
+	urls := []string{url}
+	if len(f.urls) > 1 {
+		urls = append(urls, f.urls[1:]...)
+	}
+	return Config{
+		RPCURL:            url,
+		RPCURLs:           urls,
+		RPCUser:           user,
+		RPCPass:           pass,
+		RPCBearer:         bearer,
+		RPCSOCKS5:         socks5,
+		MaxResponseBytes:  f.maxResponse,
+		JSONRPCVersion:    jsonrpcVersion,
+		RecordPath:        record,
+		ReplayPath:        replay,
+		RetryOn:           splitList(f.retryOn),
+		RetryBudget:       f.retryBudget,
+		RetryJitter:       f.retryJitter,
+		OTelEndpoint:      strings.TrimSpace(f.otelEndpoint),
+		RequireSynced:     f.requireSynced,
+		RequireTxindex:    f.requireTxindex,
+		ReadOnly:          f.readOnly,
+		EmptyTxIDFallback: f.emptyTxIDLocal,
+		ConfirmationBase:  confBase,
+	}, nil
+}
+
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return errors.New("value must not be empty")
+	}
+	*l = append(*l, v)
+	return nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
 }
 
 func rpcConfigFromFlags(url, user, pass string) (string, string, string, error) {
@@ -308,6 +1560,40 @@ func rpcConfigFromFlags(url, user, pass string) (string, string, string, error)
 	return url, user, pass, nil
 }
 
+// loadAddressList reads one address per line, ignoring blank lines and
+// #-comments.
+func loadAddressList(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read allow-address-file: %w", err)
+	}
+	var addrs []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			addrs = append(addrs, line)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("allow-address-file lists no addresses")
+	}
+	return addrs, nil
+}
+
+// parseExpectedOutput parses an --expect-output value, <address>:<amount>.
+func parseExpectedOutput(v string) (broadcast.ExpectedOutput, error) {
+	addr, amount, ok := strings.Cut(v, ":")
+	addr = strings.TrimSpace(addr)
+	if !ok || addr == "" {
+		return broadcast.ExpectedOutput{}, fmt.Errorf("expect-output %q must be <address>:<amount>", v)
+	}
+	zat, err := broadcast.ParseAmount(amount)
+	if err != nil || zat <= 0 {
+		return broadcast.ExpectedOutput{}, fmt.Errorf("expect-output %q: amount must be a positive coin amount with at most 8 decimals", v)
+	}
+	return broadcast.ExpectedOutput{Address: addr, Amount: zat}, nil
+}
+
 func loadHexInput(hexValue, filePath, hexFlagName, fileFlagName string) (string, error) {
 	var sources int
 	if strings.TrimSpace(hexValue) != "" {
@@ -338,31 +1624,123 @@ func writeOK(w io.Writer, jsonOut bool, payload any) int {
 	if !jsonOut {
 		b, _ := json.Marshal(payload)
 		fmt.Fprintln(w, string(b))
-		return 0
 	}
-	_ = json.NewEncoder(w).Encode(map[string]any{
+	writeEnvelope(w, okEnvelope(payload), !jsonOut)
+	return 0
+}
+
+func okEnvelope(payload any) map[string]any {
+	return map[string]any{
 		"version": jsonVersionV1,
 		"status":  "ok",
 		"data":    payload,
-	})
-	return 0
+	}
+}
+
+// errCode maps a broadcast/RPC error to the CLI JSON error code.
+func errCode(err error) string {
+	switch {
+	case errors.Is(err, broadcast.ErrNodeSyncing):
+		return "node_syncing"
+	case errors.Is(err, broadcast.ErrMethodUnsupported):
+		return "method_unsupported"
+	case errors.Is(err, broadcast.ErrAuth):
+		return "auth_failed"
+	case errors.Is(err, broadcast.ErrTxindexRequired):
+		return "txindex_required"
+	case errors.Is(err, broadcast.ErrWaitTimeout):
+		return "timeout"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, broadcast.ErrTxUnconfirmed):
+		return "unconfirmed"
+	case errors.Is(err, broadcast.ErrNotInMempool), errors.Is(err, broadcast.ErrOperationNotFound):
+		return "not_found"
+	case errors.Is(err, broadcast.ErrOperationFailed):
+		return "operation_failed"
+	case errors.Is(err, broadcast.ErrMempoolTooLarge):
+		return "mempool_too_large"
+	case errors.Is(err, broadcast.ErrInvalidPSBT):
+		return "invalid_request"
+	case errors.Is(err, broadcast.ErrPSBTIncomplete):
+		return "psbt_incomplete"
+	case errors.Is(err, broadcast.ErrAddressNotAllowed):
+		return "address_not_allowed"
+	case errors.Is(err, broadcast.ErrOutputMismatch):
+		return "output_mismatch"
+	case errors.Is(err, broadcast.ErrLocktimeNotMet):
+		return "locktime_not_met"
+	case errors.Is(err, broadcast.ErrFeeTooLow):
+		return "fee_too_low"
+	case errors.Is(err, broadcast.ErrImmatureCoinbase):
+		return "immature_coinbase"
+	case errors.Is(err, broadcast.ErrRejected):
+		return "rejected"
+	case errors.Is(err, broadcast.ErrReadOnly):
+		return "read_only"
+	case errors.Is(err, broadcast.ErrEmptyTxID):
+		return "empty_txid"
+	default:
+		return "node_rpc_error"
+	}
+}
+
+// writeAttempts lists each retry attempt's error for --verbose.
+func writeAttempts(w io.Writer, err error) {
+	attempts := broadcast.AttemptErrors(err)
+	for i, a := range attempts {
+		fmt.Fprintf(w, "attempt %d/%d: %v\n", i+1, len(attempts), a)
+	}
+}
+
+func jsonErrWriter(stdout, stderr io.Writer, toStderr bool) io.Writer {
+	if !toStderr {
+		return stdout
+	}
+	if s, ok := stdout.(*outputSinks); ok {
+		return s.withPrimary(stderr)
+	}
+	return stderr
 }
 
 func writeErr(stdout, stderr io.Writer, jsonOut bool, code, msg string) int {
+	return writeErrData(stdout, stderr, jsonOut, code, msg, nil)
+}
+
+// writeErrData is writeErr with extra machine-readable detail, written as the
+// error's "data" object in JSON mode.
+func writeErrData(stdout, stderr io.Writer, jsonOut bool, code, msg string, data map[string]any) int {
+	e := map[string]any{
+		"code":    code,
+		"message": msg,
+	}
+	if data != nil {
+		e["data"] = data
+	}
+	env := map[string]any{
+		"version": jsonVersionV1,
+		"status":  "err",
+		"error":   e,
+	}
 	if jsonOut {
-		_ = json.NewEncoder(stdout).Encode(map[string]any{
-			"version": jsonVersionV1,
-			"status":  "err",
-			"error": map[string]any{
-				"code":    code,
-				"message": msg,
-			},
-		})
-		return 1
+		writeEnvelope(stdout, env, false)
+		return errExitStatus(code)
 	}
 	if msg == "" {
 		msg = code
 	}
 	fmt.Fprintln(stderr, msg)
+	writeEnvelope(stdout, env, true)
+	return errExitStatus(code)
+}
+
+// errExitStatus is the exit status for an error code: 130 (the shell's
+// status for SIGINT) when the command was cancelled, 1 otherwise.
+func errExitStatus(code string) int {
+	if code == "cancelled" {
+		return 130
+	}
 	return 1
 }
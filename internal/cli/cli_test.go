@@ -2,12 +2,28 @@ package cli
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
 )
 
 type fakeRunner struct {
@@ -31,7 +47,7 @@ func (f fakeRunner) WaitForConfirmations(ctx context.Context, txid string, confi
 func TestRun_Submit_RequiresRawTx(t *testing.T) {
 	var out, errBuf bytes.Buffer
 
-	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--json"}, func(string, string, string, time.Duration) (Runner, error) {
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--json"}, func(Config) (Runner, error) {
 		t.Fatalf("factory should not be called")
 		return nil, nil
 	}, &out, &errBuf)
@@ -50,7 +66,7 @@ func TestRun_Submit_RequiresRawTx(t *testing.T) {
 func TestRun_Status_NotFound(t *testing.T) {
 	var out, errBuf bytes.Buffer
 
-	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64), "--json"}, func(string, string, string, time.Duration) (Runner, error) {
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64), "--json"}, func(Config) (Runner, error) {
 		return fakeRunner{
 			status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
 				return broadcast.TxStatus{}, false, nil
@@ -68,3 +84,3074 @@ func TestRun_Status_NotFound(t *testing.T) {
 		t.Fatalf("expected not_found error, got: %s", out.String())
 	}
 }
+
+func TestRun_Submit_FIFOStreamsAcrossWriterReconnects(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "txs.fifo")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	done := make(chan int, 1)
+	go func() {
+		done <- RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-fifo", fifo, "--stop-on-error"}, func(Config) (Runner, error) {
+			return fakeRunner{
+				submit: func(ctx context.Context, rawTxHex string) (string, error) {
+					if rawTxHex == "zz" {
+						return "", errors.New("broadcast: raw tx hex must be hex")
+					}
+					return strings.Repeat(rawTxHex[:1], 64), nil
+				},
+			}, nil
+		}, &out, &errBuf)
+	}()
+
+	for _, chunk := range []string{"a0\n\nb0\n", "zz\nc0\n"} {
+		w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatalf("open fifo: %v", err)
+		}
+		if _, err := w.WriteString(chunk); err != nil {
+			t.Fatalf("write fifo: %v", err)
+		}
+		_ = w.Close()
+	}
+
+	select {
+	case code := <-done:
+		if code != 1 {
+			t.Fatalf("exit code=%d want 1", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for fifo stream to stop")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 result lines, got %d: %s", len(lines), out.String())
+	}
+	if !strings.Contains(lines[0], `"txid":"`+strings.Repeat("a", 64)+`"`) || !strings.Contains(lines[1], `"line":3`) {
+		t.Fatalf("unexpected results: %s", out.String())
+	}
+	if !strings.Contains(lines[2], `"status":"err"`) || !strings.Contains(lines[2], `"line":4`) {
+		t.Fatalf("expected error for line 4, got: %s", lines[2])
+	}
+}
+
+func TestRun_Status_JSONErrorsStderr(t *testing.T) {
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{
+			status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+				return broadcast.TxStatus{}, false, nil
+			},
+		}, nil
+	}
+	args := []string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64), "--json"}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO(args, factory, &out, &errBuf); code == 0 {
+		t.Fatalf("expected non-zero exit code")
+	}
+	if !strings.Contains(out.String(), `"code":"not_found"`) || errBuf.Len() != 0 {
+		t.Fatalf("expected error envelope on stdout by default, stdout=%q stderr=%q", out.String(), errBuf.String())
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	if code := RunWithIO(append(args, "--json-errors-stderr"), factory, &out, &errBuf); code == 0 {
+		t.Fatalf("expected non-zero exit code")
+	}
+	if !strings.Contains(errBuf.String(), `"code":"not_found"`) || out.Len() != 0 {
+		t.Fatalf("expected error envelope on stderr, stdout=%q stderr=%q", out.String(), errBuf.String())
+	}
+}
+
+func TestRun_Submit_RetryOnFlagReachesFactory(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	var got Config
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--retry-on", "busy, ,Try Again"}, func(cfg Config) (Runner, error) {
+		got = cfg
+		return fakeRunner{
+			submit: func(ctx context.Context, rawTxHex string) (string, error) {
+				return strings.Repeat("a", 64), nil
+			},
+		}, nil
+	}, &out, &errBuf)
+
+	if code != 0 {
+		t.Fatalf("exit code=%d stderr=%s", code, errBuf.String())
+	}
+	if len(got.RetryOn) != 2 || got.RetryOn[0] != "busy" || got.RetryOn[1] != "Try Again" {
+		t.Fatalf("RetryOn=%q", got.RetryOn)
+	}
+}
+
+func TestRun_Submit_NodeSyncingErrorCode(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--require-synced", "--json"}, func(cfg Config) (Runner, error) {
+		if !cfg.RequireSynced {
+			t.Fatalf("expected RequireSynced")
+		}
+		return fakeRunner{
+			submit: func(ctx context.Context, rawTxHex string) (string, error) {
+				return "", fmt.Errorf("%w (blocks=1 headers=2)", broadcast.ErrNodeSyncing)
+			},
+		}, nil
+	}, &out, &errBuf)
+
+	if code == 0 {
+		t.Fatalf("expected non-zero exit code")
+	}
+	if !strings.Contains(out.String(), `"code":"node_syncing"`) {
+		t.Fatalf("expected node_syncing error, got: %s", out.String())
+	}
+}
+
+type fakeMempoolRunner struct {
+	fakeRunner
+	txids []string
+	info  broadcast.MempoolInfo
+}
+
+func (f fakeMempoolRunner) Mempool(ctx context.Context) ([]string, error) {
+	return f.txids, nil
+}
+
+func (f fakeMempoolRunner) MempoolInfo(ctx context.Context) (broadcast.MempoolInfo, error) {
+	return f.info, nil
+}
+
+func TestRun_Mempool_Count(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	bytesUsed, usage := int64(1200), int64(4096)
+	code := RunWithIO([]string{"mempool", "--rpc-url", "http://127.0.0.1:8232", "--count", "--json"}, func(Config) (Runner, error) {
+		return fakeMempoolRunner{
+			txids: []string{strings.Repeat("a", 64)},
+			info:  broadcast.MempoolInfo{Size: 3, Bytes: &bytesUsed, Usage: &usage},
+		}, nil
+	}, &out, &errBuf)
+
+	if code != 0 {
+		t.Fatalf("exit code=%d stderr=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"data":{"size":3,"bytes":1200,"usage":4096}`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_SubcommandHelp(t *testing.T) {
+	for _, cmd := range []string{"submit", "status", "serve", "mempool"} {
+		for _, flagName := range []string{"-h", "--help"} {
+			var out, errBuf bytes.Buffer
+			code := RunWithIO([]string{cmd, flagName}, func(Config) (Runner, error) {
+				t.Fatalf("factory should not be called")
+				return nil, nil
+			}, &out, &errBuf)
+			if code != 0 {
+				t.Fatalf("%s %s: exit code=%d", cmd, flagName, code)
+			}
+			if !strings.Contains(out.String(), "Usage: juno-broadcast "+cmd) || !strings.Contains(out.String(), "-rpc-url") {
+				t.Fatalf("%s %s: unexpected usage: %s", cmd, flagName, out.String())
+			}
+		}
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO([]string{"help"}, nil, &out, &errBuf); code != 0 || !strings.Contains(out.String(), "Usage:") {
+		t.Fatalf("top-level help: code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_StatusBatch_NewerThanSkipsOldEntries(t *testing.T) {
+	recent := strings.Repeat("1", 64)
+	old := strings.Repeat("2", 64)
+	pending := strings.Repeat("3", 64)
+	unknown := strings.Repeat("4", 64)
+
+	txidFile := filepath.Join(t.TempDir(), "txids.txt")
+	if err := os.WriteFile(txidFile, []byte(strings.Join([]string{recent, old, "", pending, unknown}, "\n")), 0o600); err != nil {
+		t.Fatalf("write txids: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status-batch", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", txidFile, "--newer-than", "24h"}, func(Config) (Runner, error) {
+		return fakeRunner{
+			status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+				switch txid {
+				case recent:
+					return broadcast.TxStatus{TxID: txid, Confirmations: 3, BlockHash: "h", BlockTime: time.Now().Add(-time.Hour).Unix()}, true, nil
+				case old:
+					return broadcast.TxStatus{TxID: txid, Confirmations: 900, BlockHash: "h", BlockTime: time.Now().Add(-72 * time.Hour).Unix()}, true, nil
+				case pending:
+					return broadcast.TxStatus{TxID: txid, InMempool: true}, true, nil
+				default:
+					return broadcast.TxStatus{}, false, nil
+				}
+			},
+		}, nil
+	}, &out, &errBuf)
+
+	if code != 1 {
+		t.Fatalf("exit code=%d want 1 (unknown txid)", code)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 result lines, got %d: %s", len(lines), out.String())
+	}
+	for i, want := range []string{`"status":"ok"`, `"status":"skipped"`, `"status":"ok"`, `"code":"not_found"`} {
+		if !strings.Contains(lines[i], want) {
+			t.Fatalf("line %d: want %s, got %s", i+1, want, lines[i])
+		}
+	}
+}
+
+type fakeCachedRunner struct {
+	fakeRunner
+	blockTimes map[string]int64
+}
+
+func (f fakeCachedRunner) CachedBlockTime(txid string) (int64, bool) {
+	bt, ok := f.blockTimes[txid]
+	return bt, ok
+}
+
+func TestRun_StatusBatch_NewerThanSkipsCachedOldEntriesWithoutLookup(t *testing.T) {
+	recent := strings.Repeat("1", 64)
+	old := strings.Repeat("2", 64)
+
+	txidFile := filepath.Join(t.TempDir(), "txids.txt")
+	if err := os.WriteFile(txidFile, []byte(old+"\n"+recent+"\n"), 0o600); err != nil {
+		t.Fatalf("write txids: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status-batch", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", txidFile, "--newer-than", "24h"}, func(Config) (Runner, error) {
+		return fakeCachedRunner{
+			fakeRunner: fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+				if txid == old {
+					t.Fatalf("status looked up a txid the cache places before the cutoff")
+				}
+				return broadcast.TxStatus{TxID: txid, Confirmations: 3, BlockHash: "h", BlockTime: time.Now().Add(-time.Hour).Unix()}, true, nil
+			}},
+			blockTimes: map[string]int64{
+				old:    time.Now().Add(-72 * time.Hour).Unix(),
+				recent: time.Now().Add(-time.Hour).Unix(),
+			},
+		}, nil
+	}, &out, &errBuf)
+
+	if code != 0 {
+		t.Fatalf("exit code=%d out=%s", code, out.String())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"status":"skipped"`) || !strings.Contains(lines[1], `"status":"ok"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+type fakeMultiRunner struct {
+	fakeRunner
+	endpoints []string
+	submitAll func(ctx context.Context, rawTxHex string) (map[string]string, []error)
+}
+
+func (f fakeMultiRunner) Endpoints() []string { return f.endpoints }
+
+func (f fakeMultiRunner) SubmitAll(ctx context.Context, rawTxHex string) (map[string]string, []error) {
+	return f.submitAll(ctx, rawTxHex)
+}
+
+func TestRun_Submit_MultipleRPCURLs(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	txid := strings.Repeat("ab", 32)
+
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://a:8232", "--rpc-url", "http://b:8232", "--raw-tx-hex", "00", "--json"}, func(cfg Config) (Runner, error) {
+		if cfg.RPCURL != "http://a:8232" || len(cfg.RPCURLs) != 2 || cfg.RPCURLs[1] != "http://b:8232" {
+			t.Fatalf("cfg=%+v", cfg)
+		}
+		return fakeMultiRunner{
+			endpoints: []string{"http://a:8232", "http://b:8232"},
+			submitAll: func(context.Context, string) (map[string]string, []error) {
+				return map[string]string{"http://b:8232": txid}, []error{&broadcast.EndpointError{Endpoint: "http://a:8232", Err: errors.New("connection refused")}}
+			},
+		}, nil
+	}, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("code=%d out=%q err=%q", code, out.String(), errBuf.String())
+	}
+
+	var env struct {
+		Data struct {
+			TxID      string `json:"txid"`
+			Endpoints []struct {
+				Endpoint string `json:"endpoint"`
+				TxID     string `json:"txid"`
+				Error    *struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			} `json:"endpoints"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if env.Data.TxID != txid || len(env.Data.Endpoints) != 2 {
+		t.Fatalf("data=%+v", env.Data)
+	}
+	if e := env.Data.Endpoints[0]; e.Endpoint != "http://a:8232" || e.Error == nil || e.Error.Code != "node_rpc_error" {
+		t.Fatalf("endpoints[0]=%+v", e)
+	}
+	if e := env.Data.Endpoints[1]; e.TxID != txid || e.Error != nil {
+		t.Fatalf("endpoints[1]=%+v", e)
+	}
+}
+
+type fakeConflictRunner struct {
+	fakeRunner
+	err error
+}
+
+func (f fakeConflictRunner) CheckConflicts(context.Context, string) ([]broadcast.Conflict, error) {
+	return nil, f.err
+}
+
+func TestRun_CheckConflicts_MethodUnsupported(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	code := RunWithIO([]string{"check-conflicts", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, func(Config) (Runner, error) {
+		return fakeConflictRunner{err: fmt.Errorf("%w: gettxspendingprevout", broadcast.ErrMethodUnsupported)}, nil
+	}, &out, &errBuf)
+
+	if code != 1 {
+		t.Fatalf("exit code=%d", code)
+	}
+	if !strings.Contains(out.String(), `"code":"method_unsupported"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_Schema_MatchesEmittedFields(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO([]string{"schema"}, nil, &out, &errBuf); code != 0 {
+		t.Fatalf("exit code=%d stderr=%s", code, errBuf.String())
+	}
+
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &schema); err != nil {
+		t.Fatalf("schema json: %v", err)
+	}
+
+	for def, v := range map[string]any{
+		"txStatus":           broadcast.TxStatus{},
+		"mempoolInfo":        broadcast.MempoolInfo{},
+		"mempoolEntry":       broadcast.MempoolEntry{},
+		"operationResult":    broadcast.OperationResult{},
+		"outputsData":        outputsData{},
+		"txOutput":           broadcast.TxOutput{},
+		"nodeHealth":         broadcast.NodeHealth{},
+		"doctorData":         doctorData{},
+		"mempoolPolicy":      broadcast.MempoolPolicy{},
+		"inclusionProof":     broadcast.InclusionProof{},
+		"blockStatus":        broadcast.BlockStatus{},
+		"txFee":              broadcast.TxFee{},
+		"prioritiseData":     prioritiseResult{},
+		"dumpData":           dumpResult{},
+		"resubmitWalletData": resubmitWalletResult{},
+		"testAcceptData":     testAcceptData{},
+		"waitAllData":        waitAllData{},
+		"endpointResult":     endpointResult{},
+		"feeBump":            broadcast.FeeBump{},
+		"submitTimings":      submitTimings{},
+		"error":              streamError{},
+	} {
+		props := schema.Defs[def].Properties
+		typ := reflect.TypeOf(v)
+		if len(props) != typ.NumField() {
+			t.Fatalf("%s: schema has %d properties, struct has %d fields", def, len(props), typ.NumField())
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if _, ok := props[name]; !ok {
+				t.Fatalf("%s: schema missing property %q", def, name)
+			}
+		}
+	}
+}
+
+func TestRun_Status_TimeoutFlag(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64), "--timeout", "20ms", "--json"}, func(Config) (Runner, error) {
+		return fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+			<-ctx.Done()
+			return broadcast.TxStatus{}, false, ctx.Err()
+		}}, nil
+	}, &out, &errBuf)
+
+	if code != 1 {
+		t.Fatalf("exit code=%d", code)
+	}
+	if !strings.Contains(out.String(), `"code":"timeout"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_Status_Replay(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	path := filepath.Join(t.TempDir(), "session.ndjson")
+	transcript := fmt.Sprintf(`{"method":"getrawtransaction","params":["%s",1],"result":{"txid":"%s","blockhash":"%s","confirmations":7}}`+"\n", txid, txid, strings.Repeat("cd", 32))
+	if err := os.WriteFile(path, []byte(transcript), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--replay", path, "--txid", txid, "--json"}, defaultFactory, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("exit code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"confirmations":7`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_Submit_OnConfirmedHook(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env")
+	script := filepath.Join(dir, "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$JUNO_TXID $JUNO_CONFIRMATIONS $JUNO_BLOCKHASH $1\" > \""+envFile+"\"\nexit 3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	txid := strings.Repeat("ab", 32)
+	blockhash := strings.Repeat("cd", 32)
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--confirmations", "2", "--on-confirmed", script + " extra", "--json"}, func(Config) (Runner, error) {
+		return fakeRunner{
+			submit: func(context.Context, string) (string, error) { return txid, nil },
+			wait: func(context.Context, string, int64) (broadcast.TxStatus, error) {
+				return broadcast.TxStatus{TxID: txid, Confirmations: 2, BlockHash: blockhash}, nil
+			},
+		}, nil
+	}, &out, &errBuf)
+
+	if code != 0 {
+		t.Fatalf("exit code=%d stderr=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"hook":{"exit_code":3,`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+	got, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := txid + " 2 " + blockhash + " extra\n"; string(got) != want {
+		t.Fatalf("hook env=%q want %q", got, want)
+	}
+}
+
+func TestRunOnConfirmed_Timeout(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var errBuf bytes.Buffer
+	start := time.Now()
+	res := runOnConfirmed(script, broadcast.TxStatus{TxID: strings.Repeat("ab", 32)}, &errBuf, 100*time.Millisecond)
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("hook ran for %v", d)
+	}
+	if res.ExitCode != -1 || !strings.Contains(res.Error, "timed out after 100ms") {
+		t.Fatalf("res=%+v", res)
+	}
+}
+
+func TestRun_Submit_AuthFailed(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			return "", fmt.Errorf("%w: junocashd: http 401: Unauthorized", broadcast.ErrAuth)
+		}}, nil
+	}, &out, &errBuf)
+
+	if code != 1 {
+		t.Fatalf("exit code=%d", code)
+	}
+	if !strings.Contains(out.String(), `"code":"auth_failed"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+type fakePSBTRunner struct {
+	fakeRunner
+	decoded json.RawMessage
+}
+
+func (f fakePSBTRunner) DecodePSBT(context.Context, string) (json.RawMessage, error) {
+	return f.decoded, nil
+}
+
+func TestRun_PSBTDecode_Pretty(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	code := RunWithIO([]string{"psbt-decode", "--rpc-url", "http://127.0.0.1:8232", "--psbt", "cHNidP8=", "--pretty"}, func(Config) (Runner, error) {
+		return fakePSBTRunner{decoded: json.RawMessage(`{"fee":0.0001}`)}, nil
+	}, &out, &errBuf)
+
+	if code != 0 {
+		t.Fatalf("exit code=%d stderr=%s", code, errBuf.String())
+	}
+	if out.String() != "{\n  \"fee\": 0.0001\n}\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRun_StatusBatch_Stats(t *testing.T) {
+	known := strings.Repeat("1", 64)
+	unknown := strings.Repeat("2", 64)
+	txidFile := filepath.Join(t.TempDir(), "txids.txt")
+	if err := os.WriteFile(txidFile, []byte(known+"\n"+unknown+"\n"+unknown+"\n"), 0o600); err != nil {
+		t.Fatalf("write txids: %v", err)
+	}
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+			if txid == known {
+				return broadcast.TxStatus{TxID: txid, InMempool: true}, true, nil
+			}
+			return broadcast.TxStatus{}, false, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	RunWithIO([]string{"status-batch", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", txidFile, "--stats"}, factory, &out, &errBuf)
+	var summary struct {
+		Stats struct {
+			Total   int            `json:"total"`
+			OK      int            `json:"succeeded"`
+			Failed  int            `json:"failed"`
+			Elapsed string         `json:"elapsed"`
+			ByCode  map[string]int `json:"failures_by_code"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(errBuf.Bytes(), &summary); err != nil {
+		t.Fatalf("stats json: %v (%q)", err, errBuf.String())
+	}
+	if s := summary.Stats; s.Total != 3 || s.OK != 1 || s.Failed != 2 || s.ByCode["not_found"] != 2 || s.Elapsed == "" {
+		t.Fatalf("stats=%+v", s)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 3 {
+		t.Fatalf("stdout should only hold the 3 results, got %q", out.String())
+	}
+
+	errBuf.Reset()
+	RunWithIO([]string{"status-batch", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", txidFile, "--stats=text"}, factory, &out, &errBuf)
+	if got := errBuf.String(); !strings.HasPrefix(got, "total=3 succeeded=1 failed=2 skipped=0 elapsed=") || !strings.HasSuffix(got, " not_found=2\n") {
+		t.Fatalf("text stats=%q", got)
+	}
+}
+
+func TestRun_Status_RawTxDerivesTxID(t *testing.T) {
+	const raw = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	const want = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+			if txid != want {
+				t.Fatalf("txid=%s want %s", txid, want)
+			}
+			return broadcast.TxStatus{TxID: txid, Confirmations: 3}, true, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", raw, "--json"}, factory, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("exit code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"txid":"`+want+`"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", raw, "--txid", want, "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+		t.Fatalf("expected invalid_request for txid+raw, code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeTxIDRunner struct {
+	fakeRunner
+	txid func(ctx context.Context, rawTxHex string) (string, error)
+}
+
+func (f fakeTxIDRunner) TxID(ctx context.Context, rawTxHex string) (string, error) {
+	return f.txid(ctx, rawTxHex)
+}
+
+func TestRun_Status_RawTxV5AsksNode(t *testing.T) {
+	const raw = "050000800a27a726"
+	want := strings.Repeat("5e", 32)
+	factory := func(Config) (Runner, error) {
+		return fakeTxIDRunner{
+			fakeRunner: fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+				if txid != want {
+					t.Fatalf("txid=%s want %s", txid, want)
+				}
+				return broadcast.TxStatus{TxID: txid, InMempool: true}, true, nil
+			}},
+			txid: func(_ context.Context, rawTxHex string) (string, error) {
+				if rawTxHex != raw {
+					t.Fatalf("raw=%s", rawTxHex)
+				}
+				return want, nil
+			},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", raw, "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"txid":"`+want+`"`) {
+		t.Fatalf("exit code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+}
+
+type fakePingRunner struct {
+	fakeRunner
+	ping func(ctx context.Context) error
+}
+
+func (f fakePingRunner) Ping(ctx context.Context) error { return f.ping(ctx) }
+
+func TestRun_StatusBatch_WarmupFailureAborts(t *testing.T) {
+	txidFile := filepath.Join(t.TempDir(), "txids.txt")
+	if err := os.WriteFile(txidFile, []byte(strings.Repeat("1", 64)+"\n"), 0o600); err != nil {
+		t.Fatalf("write txids: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status-batch", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", txidFile}, func(Config) (Runner, error) {
+		return fakePingRunner{
+			fakeRunner: fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+				t.Fatalf("status called after failed warmup")
+				return broadcast.TxStatus{}, false, nil
+			}},
+			ping: func(context.Context) error {
+				return fmt.Errorf("%w: junocashd: http 401: Unauthorized", broadcast.ErrAuth)
+			},
+		}, nil
+	}, &out, &errBuf)
+
+	if code != 1 {
+		t.Fatalf("exit code=%d", code)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"code":"auth_failed"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_StatusBatch_LineCountsBlankLines(t *testing.T) {
+	known, unknown := strings.Repeat("1", 64), strings.Repeat("2", 64)
+	txidFile := filepath.Join(t.TempDir(), "txids.txt")
+	if err := os.WriteFile(txidFile, []byte("\n"+known+"\n\n  \n"+unknown+"\n"), 0o600); err != nil {
+		t.Fatalf("write txids: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	RunWithIO([]string{"status-batch", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", txidFile}, func(Config) (Runner, error) {
+		return fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+			return broadcast.TxStatus{TxID: txid, InMempool: true}, txid == known, nil
+		}}, nil
+	}, &out, &errBuf)
+
+	var got []streamResult
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var res streamResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		got = append(got, res)
+	}
+	if len(got) != 2 || got[0].TxID != known || got[0].Line != 2 || got[1].TxID != unknown || got[1].Line != 5 {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_Submit_RawTxURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tx.hex":
+			fmt.Fprintln(w, "  00ff  ")
+		case "/big.hex":
+			_, _ = w.Write(bytes.Repeat([]byte("00"), maxRawTxURLBytes))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(ctx context.Context, raw string) (string, error) {
+			if raw != "00ff" {
+				t.Fatalf("raw=%q", raw)
+			}
+			return strings.Repeat("a", 64), nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-url", srv.URL + "/tx.hex"}, factory, &out, &errBuf); code != 0 {
+		t.Fatalf("exit code=%d stderr=%s", code, errBuf.String())
+	}
+
+	for _, args := range [][]string{
+		{"--raw-tx-url", srv.URL + "/missing.hex"},
+		{"--raw-tx-url", srv.URL + "/big.hex"},
+		{"--raw-tx-url", srv.URL + "/tx.hex", "--raw-tx-hex", "00ff"},
+	} {
+		out.Reset()
+		code := RunWithIO(append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--json"}, args...), factory, &out, &errBuf)
+		if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d out=%s", args, code, out.String())
+		}
+	}
+}
+
+func TestRun_Status_TxindexRequired(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	var gotCfg Config
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64), "--require-txindex", "--json"}, func(cfg Config) (Runner, error) {
+		gotCfg = cfg
+		return fakeRunner{status: func(context.Context, string) (broadcast.TxStatus, bool, error) {
+			return broadcast.TxStatus{}, false, broadcast.ErrTxindexRequired
+		}}, nil
+	}, &out, &errBuf)
+
+	if code != 1 || !gotCfg.RequireTxindex {
+		t.Fatalf("code=%d cfg=%+v", code, gotCfg)
+	}
+	if !strings.Contains(out.String(), `"code":"txindex_required"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+type fakeFeeRateRunner struct {
+	fakeRunner
+	rate float64
+}
+
+func (f fakeFeeRateRunner) MempoolFeeRate(context.Context, string) (float64, error) {
+	return f.rate, nil
+}
+
+func TestRun_Submit_AssertMinFeerate(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	run := func(rate float64) (int, string) {
+		var out, errBuf bytes.Buffer
+		code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--assert-min-feerate", "2", "--json"}, func(Config) (Runner, error) {
+			return fakeFeeRateRunner{
+				fakeRunner: fakeRunner{submit: func(context.Context, string) (string, error) { return txid, nil }},
+				rate:       rate,
+			}, nil
+		}, &out, &errBuf)
+		return code, out.String()
+	}
+
+	if code, out := run(1.5); code != 1 || !strings.Contains(out, `"code":"feerate_below_assertion"`) {
+		t.Fatalf("below: code=%d out=%s", code, out)
+	}
+	if code, out := run(2.5); code != 0 || !strings.Contains(out, `"feerate":2.5`) {
+		t.Fatalf("above: code=%d out=%s", code, out)
+	}
+}
+
+func TestRun_Submit_PollValidation(t *testing.T) {
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return strings.Repeat("a", 64), nil }}, nil
+	}
+	for _, tc := range []struct {
+		args []string
+		ok   bool
+	}{
+		{[]string{"--poll", "0s"}, false},
+		{[]string{"--poll", "-1s"}, false},
+		{[]string{"--poll", "500us"}, false},
+		{[]string{"--poll", "1ns"}, false},
+		{[]string{"--poll", "10ms"}, true},
+		{[]string{"--poll", "500us", "--min-poll", "100us"}, true},
+	} {
+		var out, errBuf bytes.Buffer
+		args := append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, tc.args...)
+		code := RunWithIO(args, factory, &out, &errBuf)
+		if tc.ok && code != 0 {
+			t.Fatalf("%v: code=%d out=%s", tc.args, code, out.String())
+		}
+		if !tc.ok && (code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`)) {
+			t.Fatalf("%v: code=%d out=%s", tc.args, code, out.String())
+		}
+	}
+}
+
+func TestRun_Submit_WaitTimeoutReportsProgress(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--confirmations", "6", "--json"}, func(Config) (Runner, error) {
+		return fakeRunner{
+			submit: func(context.Context, string) (string, error) { return txid, nil },
+			wait: func(ctx context.Context, txid string, confirmations int64) (broadcast.TxStatus, error) {
+				return broadcast.TxStatus{}, &broadcast.WaitTimeoutError{
+					Elapsed:    90 * time.Second,
+					LastStatus: broadcast.TxStatus{TxID: txid, Confirmations: 2},
+					Target:     confirmations,
+					Err:        context.DeadlineExceeded,
+				}
+			},
+		}, nil
+	}, &out, &errBuf)
+
+	if code != 1 {
+		t.Fatalf("exit code=%d", code)
+	}
+	var env struct {
+		Error struct {
+			Code string         `json:"code"`
+			Data map[string]any `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("json: %v (%s)", err, out.String())
+	}
+	if env.Error.Code != "timeout" || env.Error.Data["elapsed"] != "1m30s" || env.Error.Data["last_confirmations"] != float64(2) || env.Error.Data["required_confs"] != float64(6) {
+		t.Fatalf("unexpected error: %+v", env.Error)
+	}
+}
+
+func TestRun_Submit_VerboseListsAttempts(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--verbose", "--json"}, func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			return "", &broadcast.AttemptsError{Attempts: []error{errors.New("connection refused"), errors.New("junocashd: http 503: busy")}}
+		}}, nil
+	}, &out, &errBuf)
+
+	if code != 1 {
+		t.Fatalf("exit code=%d", code)
+	}
+	want := "attempt 1/2: connection refused\nattempt 2/2: junocashd: http 503: busy\n"
+	if errBuf.String() != want {
+		t.Fatalf("stderr=%q", errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"message":"junocashd: http 503: busy"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_Submit_RawTxClipboard_NotCompiledIn(t *testing.T) {
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			t.Fatalf("unexpected submit")
+			return "", nil
+		}}, nil
+	}
+
+	for _, args := range [][]string{
+		{"--raw-tx-clipboard"},
+		{"--raw-tx-clipboard", "--raw-tx-hex", "00ff"},
+	} {
+		var out, errBuf bytes.Buffer
+		code := RunWithIO(append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--json"}, args...), factory, &out, &errBuf)
+		if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d out=%s", args, code, out.String())
+		}
+	}
+}
+
+func TestRun_Submit_PrecheckRejected(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	var gotCfg Config
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--precheck", "--json"}, func(cfg Config) (Runner, error) {
+		gotCfg = cfg
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			return "", &broadcast.RejectedError{Err: broadcast.ErrRejected, Reason: "bad-txns-inputs-spent"}
+		}}, nil
+	}, &out, &errBuf)
+	if code != 1 || !gotCfg.Precheck {
+		t.Fatalf("code=%d precheck=%v", code, gotCfg.Precheck)
+	}
+	if !strings.Contains(out.String(), `"code":"rejected"`) || !strings.Contains(out.String(), "bad-txns-inputs-spent") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_Submit_FIFODedupeSkipsKnownTxs(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "txs.fifo")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	known, err := broadcast.TxIDFromHex("01000000")
+	if err != nil {
+		t.Fatalf("TxIDFromHex: %v", err)
+	}
+
+	var submitted []string
+	var out, errBuf bytes.Buffer
+	done := make(chan int, 1)
+	go func() {
+		done <- RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-fifo", fifo, "--dedupe", "--stop-on-error"}, func(Config) (Runner, error) {
+			return fakeRunner{
+				status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+					if txid == known {
+						return broadcast.TxStatus{TxID: txid, InMempool: true}, true, nil
+					}
+					return broadcast.TxStatus{}, false, nil
+				},
+				submit: func(ctx context.Context, rawTxHex string) (string, error) {
+					submitted = append(submitted, rawTxHex)
+					if rawTxHex == "zz" {
+						return "", errors.New("broadcast: raw tx hex must be hex")
+					}
+					return strings.Repeat("b", 64), nil
+				},
+			}, nil
+		}, &out, &errBuf)
+	}()
+
+	w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open fifo: %v", err)
+	}
+	_, _ = w.WriteString("01000000\n02000000\nzz\n")
+	_ = w.Close()
+
+	select {
+	case code := <-done:
+		if code != 1 {
+			t.Fatalf("exit code=%d want 1", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for fifo stream to stop")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"status":"already_present"`) || !strings.Contains(lines[0], `"txid":"`+known+`"`) {
+		t.Fatalf("unexpected results: %s", out.String())
+	}
+	if len(submitted) != 2 || submitted[0] != "02000000" {
+		t.Fatalf("submitted=%v", submitted)
+	}
+}
+
+type fakePrioritiseRunner struct {
+	fakeRunner
+	prioritise func(ctx context.Context, txid string, feeDeltaSat int64) error
+}
+
+func (f fakePrioritiseRunner) Prioritise(ctx context.Context, txid string, feeDeltaSat int64) error {
+	return f.prioritise(ctx, txid, feeDeltaSat)
+}
+
+func TestRun_Prioritise(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	factory := func(Config) (Runner, error) {
+		return fakePrioritiseRunner{prioritise: func(_ context.Context, got string, delta int64) error {
+			if got != txid || delta != -100 {
+				t.Fatalf("txid=%s delta=%d", got, delta)
+			}
+			return nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO([]string{"prioritise", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.ToUpper(txid), "--fee-delta", "-100", "--json"}, factory, &out, &errBuf); code != 0 {
+		t.Fatalf("exit code=%d out=%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), `"data":{"txid":"`+txid+`","fee_delta":-100}`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	for _, args := range [][]string{
+		{"--txid", "abc", "--fee-delta", "100"},
+		{"--txid", txid},
+	} {
+		out.Reset()
+		code := RunWithIO(append([]string{"prioritise", "--rpc-url", "http://127.0.0.1:8232", "--json"}, args...), factory, &out, &errBuf)
+		if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d out=%s", args, code, out.String())
+		}
+	}
+}
+
+func TestRun_Submit_ReadOnly(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	var gotCfg Config
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--read-only", "--json"}, func(cfg Config) (Runner, error) {
+		gotCfg = cfg
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			return "", fmt.Errorf("%w: refusing to call sendrawtransaction", broadcast.ErrReadOnly)
+		}}, nil
+	}, &out, &errBuf)
+	if code != 1 || !gotCfg.ReadOnly || !strings.Contains(out.String(), `"code":"read_only"`) {
+		t.Fatalf("code=%d readOnly=%v out=%s", code, gotCfg.ReadOnly, out.String())
+	}
+}
+
+func TestReverseHexBytes(t *testing.T) {
+	for in, want := range map[string]string{
+		"":       "",
+		"ab":     "ab",
+		"0a0b0c": "0c0b0a",
+		"abc":    "abc",
+		"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b": "3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a",
+	} {
+		if got := reverseHexBytes(in); got != want {
+			t.Fatalf("reverseHexBytes(%q)=%q want %q", in, got, want)
+		}
+		if got := reverseHexBytes(reverseHexBytes(in)); got != in {
+			t.Fatalf("round trip %q=%q", in, got)
+		}
+	}
+}
+
+func TestRun_TxIDByteOrderInternal(t *testing.T) {
+	txid := strings.Repeat("01", 16) + strings.Repeat("02", 16)
+	internal := reverseHexBytes(txid)
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{
+			submit: func(context.Context, string) (string, error) { return txid, nil },
+			status: func(_ context.Context, got string) (broadcast.TxStatus, bool, error) {
+				if got != txid {
+					t.Fatalf("status txid=%s", got)
+				}
+				return broadcast.TxStatus{TxID: got, InMempool: true}, true, nil
+			},
+		}, nil
+	}
+
+	for _, args := range [][]string{
+		{"submit", "--raw-tx-hex", "00"},
+		{"submit", "--raw-tx-hex", "00", "--json"},
+		{"status", "--txid", txid, "--json"},
+	} {
+		var out, errBuf bytes.Buffer
+		args = append(args, "--rpc-url", "http://127.0.0.1:8232", "--txid-byte-order", "internal")
+		if code := RunWithIO(args, factory, &out, &errBuf); code != 0 {
+			t.Fatalf("%v: exit code=%d out=%s", args, code, out.String())
+		}
+		if !strings.Contains(out.String(), internal) || strings.Contains(out.String(), txid) {
+			t.Fatalf("%v: unexpected output: %s", args, out.String())
+		}
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--txid-byte-order", "little", "--json"}, factory, &out, &errBuf); code != 2 || !strings.Contains(errBuf.String(), "display or internal") {
+		t.Fatalf("exit code=%d stderr=%s", code, errBuf.String())
+	}
+}
+
+type fakeDetailedBumpRunner struct {
+	fakeFeeBumpRunner
+	submitDetailed func(ctx context.Context, rawTxHex string) (broadcast.SubmitResult, error)
+}
+
+func (f fakeDetailedBumpRunner) SubmitDetailed(ctx context.Context, rawTxHex string) (broadcast.SubmitResult, error) {
+	return f.submitDetailed(ctx, rawTxHex)
+}
+
+func TestRun_Submit_TxIDByteOrderInternalEverywhere(t *testing.T) {
+	orig := strings.Repeat("01", 16) + strings.Repeat("02", 16)
+	txid := strings.Repeat("03", 16) + strings.Repeat("04", 16)
+	wtxid := strings.Repeat("05", 16) + strings.Repeat("06", 16)
+
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env")
+	script := filepath.Join(dir, "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$JUNO_TXID\" > \""+envFile+"\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	factory := func(Config) (Runner, error) {
+		return fakeDetailedBumpRunner{
+			fakeFeeBumpRunner: fakeFeeBumpRunner{
+				fakeRunner: fakeRunner{wait: func(_ context.Context, got string, _ int64) (broadcast.TxStatus, error) {
+					return broadcast.TxStatus{TxID: got, Confirmations: 1, BlockHash: strings.Repeat("cd", 32)}, nil
+				}},
+				submitWithFeeBump: func(context.Context, string, int) (string, []broadcast.FeeBump, error) {
+					return txid, []broadcast.FeeBump{{OrigTxID: orig, TxID: txid, OrigFee: 0.0001, Fee: 0.0002}}, nil
+				},
+			},
+			submitDetailed: func(context.Context, string) (broadcast.SubmitResult, error) {
+				return broadcast.SubmitResult{TxID: txid, WTxID: wtxid}, nil
+			},
+		}, nil
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--include-wtxid", "--json"}, []string{txid, wtxid}},
+		{[]string{"--auto-bump", "--json"}, []string{txid, orig}},
+		{[]string{"--auto-bump"}, []string{txid, orig}},
+	} {
+		_ = os.Remove(envFile)
+		args := append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--txid-byte-order", "internal",
+			"--confirmations", "1", "--on-confirmed", script}, tc.args...)
+		var out, errBuf bytes.Buffer
+		if code := RunWithIO(args, factory, &out, &errBuf); code != 0 {
+			t.Fatalf("%v: exit code=%d out=%s stderr=%s", tc.args, code, out.String(), errBuf.String())
+		}
+		env, err := os.ReadFile(envFile)
+		if err != nil {
+			t.Fatalf("%v: hook did not run: %v", tc.args, err)
+		}
+		all := out.String() + errBuf.String()
+		for _, id := range tc.want {
+			if !strings.Contains(all, reverseHexBytes(id)) || strings.Contains(all, id) {
+				t.Fatalf("%v: %s not reported in internal order: out=%s stderr=%s", tc.args, id, out.String(), errBuf.String())
+			}
+		}
+		if got := strings.TrimSpace(string(env)); got != reverseHexBytes(txid) {
+			t.Fatalf("%v: JUNO_TXID=%s", tc.args, got)
+		}
+	}
+}
+
+func TestRun_Status_HTMLEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>Please log in</body></html>")
+	}))
+	defer srv.Close()
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", srv.URL, "--rpc-user", "u", "--rpc-pass", "p", "--txid", strings.Repeat("a", 64), "--json"}, defaultFactory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"node_rpc_error"`) || !strings.Contains(out.String(), "check the rpc url") || !strings.Contains(out.String(), "Please log in") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_MinBlocksOnTop(t *testing.T) {
+	for _, tc := range []struct {
+		k, want int64
+		base    string
+	}{
+		{0, 1, "block-inclusive"},
+		{1, 2, "block-inclusive"},
+		{5, 6, "block-exclusive"},
+	} {
+		var gotCfg Config
+		var gotConfs int64
+		factory := func(cfg Config) (Runner, error) {
+			gotCfg = cfg
+			return fakeRunner{
+				submit: func(context.Context, string) (string, error) { return strings.Repeat("a", 64), nil },
+				wait: func(_ context.Context, txid string, confs int64) (broadcast.TxStatus, error) {
+					gotConfs = confs
+					return broadcast.TxStatus{TxID: txid, Confirmations: confs}, nil
+				},
+			}, nil
+		}
+		var out, errBuf bytes.Buffer
+		args := []string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--confirmation-base", tc.base, "--min-blocks-on-top", strconv.FormatInt(tc.k, 10), "--json"}
+		if code := RunWithIO(args, factory, &out, &errBuf); code != 0 {
+			t.Fatalf("k=%d: exit code=%d out=%s", tc.k, code, out.String())
+		}
+		if gotConfs != tc.want || gotCfg.ConfirmationBase != broadcast.BlockInclusive {
+			t.Fatalf("k=%d: confirmations=%d base=%v", tc.k, gotConfs, gotCfg.ConfirmationBase)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--min-blocks-on-top", "2", "--confirmations", "3"},
+		{"--min-blocks-on-top", "-2"},
+	} {
+		var out, errBuf bytes.Buffer
+		code := RunWithIO(append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, args...), func(Config) (Runner, error) {
+			t.Fatalf("%v: unexpected factory call", args)
+			return nil, nil
+		}, &out, &errBuf)
+		if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d out=%s", args, code, out.String())
+		}
+	}
+}
+
+func TestRun_Submit_Webhook(t *testing.T) {
+	var gotCfg Config
+	factory := func(cfg Config) (Runner, error) {
+		gotCfg = cfg
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return strings.Repeat("a", 64), nil }}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--webhook", " https://hooks.example/tx ", "--json"}, factory, &out, &errBuf); code != 0 {
+		t.Fatalf("exit code=%d out=%s", code, out.String())
+	}
+	if gotCfg.Webhook != "https://hooks.example/tx" || gotCfg.WebhookLog != &errBuf {
+		t.Fatalf("webhook=%q", gotCfg.Webhook)
+	}
+
+	out.Reset()
+	if code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--webhook", "ftp://hooks.example", "--json"}, factory, &out, &errBuf); code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeRawTxRunner struct {
+	fakeRunner
+	rawTransaction func(ctx context.Context, txid string) (string, bool, error)
+}
+
+func (f fakeRawTxRunner) RawTransaction(ctx context.Context, txid string) (string, bool, error) {
+	return f.rawTransaction(ctx, txid)
+}
+
+func TestRun_Dump(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	factory := func(Config) (Runner, error) {
+		return fakeRawTxRunner{rawTransaction: func(_ context.Context, got string) (string, bool, error) {
+			if got != txid {
+				return "", false, nil
+			}
+			return "0100ff", true, nil
+		}}, nil
+	}
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		flags []string
+		want  string
+	}{
+		{nil, "\x01\x00\xff"},
+		{[]string{"--hex"}, "0100ff\n"},
+	} {
+		path := filepath.Join(dir, "tx.out")
+		var out, errBuf bytes.Buffer
+		args := append([]string{"dump", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--out", path, "--json"}, tc.flags...)
+		if code := RunWithIO(args, factory, &out, &errBuf); code != 0 {
+			t.Fatalf("%v: exit code=%d out=%s", tc.flags, code, out.String())
+		}
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != tc.want {
+			t.Fatalf("%v: file=%q err=%v", tc.flags, got, err)
+		}
+		if !strings.Contains(out.String(), fmt.Sprintf(`"bytes":%d`, len(tc.want))) {
+			t.Fatalf("%v: unexpected output: %s", tc.flags, out.String())
+		}
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"dump", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("b", 64), "--out", filepath.Join(dir, "missing"), "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"not_found"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Fatalf("unexpected file: %v", err)
+	}
+}
+
+func TestRun_Status_StateFileReportsCrossingOnce(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	path := filepath.Join(t.TempDir(), "state.json")
+	var confs int64
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{status: func(context.Context, string) (broadcast.TxStatus, bool, error) {
+			return broadcast.TxStatus{TxID: txid, Confirmations: confs}, true, nil
+		}}, nil
+	}
+
+	for _, step := range []struct {
+		confs   int64
+		crossed bool
+	}{
+		{1, false},
+		{3, true},
+		{4, false},
+		{2, false},
+		{3, true},
+	} {
+		confs = step.confs
+		var out, errBuf bytes.Buffer
+		code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--state-file", path, "--confirmations", "3", "--json"}, factory, &out, &errBuf)
+		if code != 0 {
+			t.Fatalf("confs=%d: exit code=%d out=%s", step.confs, code, out.String())
+		}
+		if !strings.Contains(out.String(), fmt.Sprintf(`"crossed":%v`, step.crossed)) || !strings.Contains(out.String(), `"required_confs":3`) {
+			t.Fatalf("confs=%d: unexpected output: %s", step.confs, out.String())
+		}
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--state-file", path, "--json"}, factory, &out, &errBuf); code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRecordConfirmations_ConcurrentRunsKeepEveryTxID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			txid := fmt.Sprintf("%064x", i)
+			if _, err := recordConfirmations(path, broadcast.TxStatus{TxID: txid, Confirmations: 1}, 6); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("recordConfirmations: %v", err)
+	}
+
+	state, err := loadConfirmationState(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(state.TxIDs) != n {
+		t.Fatalf("state has %d txids, want %d", len(state.TxIDs), n)
+	}
+}
+
+func TestRun_Submit_ExpectOutput(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	var gotCfg Config
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--expect-output", "t1pay:1.5", "--expect-output", "t1fee:0.0001", "--exact-outputs", "--json"}, func(cfg Config) (Runner, error) {
+		gotCfg = cfg
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			return "", &broadcast.OutputMismatchError{Problems: []string{"missing 1.50000000 to t1pay"}}
+		}}, nil
+	}, &out, &errBuf)
+	want := []broadcast.ExpectedOutput{{Address: "t1pay", Amount: 150000000}, {Address: "t1fee", Amount: 10000}}
+	if code != 1 || !reflect.DeepEqual(gotCfg.ExpectedOutputs, want) || !gotCfg.ExactOutputs {
+		t.Fatalf("code=%d expected=%+v exact=%v", code, gotCfg.ExpectedOutputs, gotCfg.ExactOutputs)
+	}
+	if !strings.Contains(out.String(), `"code":"output_mismatch"`) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	for _, args := range [][]string{
+		{"--expect-output", "t1pay"},
+		{"--expect-output", "t1pay:0"},
+		{"--expect-output", "t1pay:1.123456789"},
+		{"--exact-outputs"},
+	} {
+		out.Reset()
+		argv := append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, args...)
+		if code := RunWithIO(argv, func(Config) (Runner, error) { return fakeRunner{}, nil }, &out, &errBuf); code != 1 || !strings.Contains(out.String(), "invalid_request") {
+			t.Fatalf("%v: code=%d out=%s", args, code, out.String())
+		}
+	}
+}
+
+type fakeResubmitWalletRunner struct {
+	fakeRunner
+	resubmit func(ctx context.Context) ([]string, error)
+}
+
+func (f fakeResubmitWalletRunner) ResubmitWallet(ctx context.Context) ([]string, error) {
+	return f.resubmit(ctx)
+}
+
+func TestRun_ResubmitWallet(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"resubmit-wallet", "--rpc-url", "http://127.0.0.1:8232", "--json"}, func(Config) (Runner, error) {
+		return fakeResubmitWalletRunner{resubmit: func(context.Context) ([]string, error) {
+			return []string{txid}, nil
+		}}, nil
+	}, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"txids":["`+txid+`"]`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	code = RunWithIO([]string{"resubmit-wallet", "--rpc-url", "http://127.0.0.1:8232"}, func(Config) (Runner, error) {
+		return fakeResubmitWalletRunner{resubmit: func(context.Context) ([]string, error) {
+			return []string{txid}, errors.New("broadcast: resubmit " + strings.Repeat("b", 64) + ": bad-txns-inputs-spent")
+		}}, nil
+	}, &out, &errBuf)
+	if code != 1 || strings.TrimSpace(out.String()) != txid || !strings.Contains(errBuf.String(), "bad-txns-inputs-spent") {
+		t.Fatalf("code=%d out=%q err=%q", code, out.String(), errBuf.String())
+	}
+}
+
+type fakeTestAcceptRunner struct {
+	fakeRunner
+	testAccept func(ctx context.Context, rawTxHexes []string) ([]broadcast.AcceptResult, error)
+}
+
+func (f fakeTestAcceptRunner) TestAcceptBatch(ctx context.Context, rawTxHexes []string) ([]broadcast.AcceptResult, error) {
+	return f.testAccept(ctx, rawTxHexes)
+}
+
+func TestRun_TestAccept(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "txs")
+	if err := os.WriteFile(path, []byte("00\n\n01\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	allowed := true
+	factory := func(Config) (Runner, error) {
+		return fakeTestAcceptRunner{testAccept: func(_ context.Context, raws []string) ([]broadcast.AcceptResult, error) {
+			if strings.Join(raws, ",") != "00,01" {
+				t.Fatalf("raws=%v", raws)
+			}
+			return []broadcast.AcceptResult{
+				{TxID: strings.Repeat("a", 64), Allowed: allowed, Fees: &broadcast.AcceptFees{Base: 0.00012}},
+				{TxID: strings.Repeat("b", 64), RejectReason: "missing-inputs"},
+			}, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"test-accept", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-file", path, "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"fees":12000`) || !strings.Contains(out.String(), `"reject_reason":"missing-inputs"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	allowed = false
+	out.Reset()
+	code = RunWithIO([]string{"test-accept", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--raw-tx-hex", "01", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"rejected"`) || !strings.Contains(out.String(), `"results":[`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_RPCMaxResponseBytesFlag(t *testing.T) {
+	var gotCfg Config
+	factory := func(cfg Config) (Runner, error) {
+		gotCfg = cfg
+		return fakeRunner{status: func(context.Context, string) (broadcast.TxStatus, bool, error) {
+			return broadcast.TxStatus{}, false, nil
+		}}, nil
+	}
+	var out, errBuf bytes.Buffer
+	RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64)}, factory, &out, &errBuf)
+	if gotCfg.MaxResponseBytes != broadcast.DefaultMaxResponseBytes {
+		t.Fatalf("default MaxResponseBytes=%d", gotCfg.MaxResponseBytes)
+	}
+	RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--rpc-max-response-bytes", "1024", "--txid", strings.Repeat("a", 64)}, factory, &out, &errBuf)
+	if gotCfg.MaxResponseBytes != 1024 {
+		t.Fatalf("MaxResponseBytes=%d", gotCfg.MaxResponseBytes)
+	}
+
+	out.Reset()
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--rpc-max-response-bytes", "0", "--txid", strings.Repeat("a", 64), "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `rpc-max-response-bytes must be \u003e 0`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestErrCode_ContextErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code string
+		exit int
+	}{
+		{context.Canceled, "cancelled", 130},
+		{fmt.Errorf("broadcast: getrawtransaction: %w", context.Canceled), "cancelled", 130},
+		{context.DeadlineExceeded, "timeout", 1},
+		{fmt.Errorf("junocashd: request: %w", context.DeadlineExceeded), "timeout", 1},
+	} {
+		if got := errCode(tc.err); got != tc.code {
+			t.Fatalf("errCode(%v)=%q want %q", tc.err, got, tc.code)
+		}
+
+		var out, errBuf bytes.Buffer
+		code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, func(Config) (Runner, error) {
+			return fakeRunner{submit: func(context.Context, string) (string, error) { return "", tc.err }}, nil
+		}, &out, &errBuf)
+		if code != tc.exit || !strings.Contains(out.String(), `"code":"`+tc.code+`"`) {
+			t.Fatalf("%v: code=%d out=%s", tc.err, code, out.String())
+		}
+	}
+}
+
+type fakeWaitAllRunner struct {
+	fakeRunner
+	waitAll func(ctx context.Context, txids []string, confirmations int64, minSuccess int) ([]broadcast.WaitAllResult, error)
+}
+
+func (f fakeWaitAllRunner) WaitForAll(ctx context.Context, txids []string, confirmations int64, minSuccess int) ([]broadcast.WaitAllResult, error) {
+	return f.waitAll(ctx, txids, confirmations, minSuccess)
+}
+
+func TestRun_WaitAll_Quorum(t *testing.T) {
+	var ids []string
+	for i := 0; i < 10; i++ {
+		ids = append(ids, fmt.Sprintf("%064x", i))
+	}
+	path := filepath.Join(t.TempDir(), "txids")
+	if err := os.WriteFile(path, []byte(strings.Join(ids, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var gotMin int
+	timedOut := false
+	factory := func(Config) (Runner, error) {
+		return fakeWaitAllRunner{waitAll: func(_ context.Context, txids []string, confs int64, minSuccess int) ([]broadcast.WaitAllResult, error) {
+			gotMin = minSuccess
+			res := make([]broadcast.WaitAllResult, len(txids))
+			for i, txid := range txids {
+				res[i] = broadcast.WaitAllResult{TxStatus: broadcast.TxStatus{TxID: txid, Confirmations: confs}, Met: i < 9}
+			}
+			if timedOut {
+				return res, fmt.Errorf("%w: 9 of 10 txids reached 2 confirmations (need 10)", broadcast.ErrWaitTimeout)
+			}
+			return res, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"wait-all", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", path, "--confirmations", "2", "--quorum", "0.9", "--json"}, factory, &out, &errBuf)
+	if code != 0 || gotMin != 9 || !strings.Contains(out.String(), `"met":9`) || !strings.Contains(out.String(), `"met":false`) {
+		t.Fatalf("code=%d min=%d out=%s", code, gotMin, out.String())
+	}
+
+	timedOut = true
+	out.Reset()
+	code = RunWithIO([]string{"wait-all", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", path, "--json"}, factory, &out, &errBuf)
+	if code != 1 || gotMin != 10 || !strings.Contains(out.String(), `"code":"timeout"`) || !strings.Contains(out.String(), `"results":[`) {
+		t.Fatalf("code=%d min=%d out=%s", code, gotMin, out.String())
+	}
+
+	for _, args := range [][]string{
+		{"--txid", ids[0], "--min-success", "2"},
+		{"--txid", ids[0], "--quorum", "1.5"},
+		{"--txid", ids[0], "--quorum", "0.5", "--min-success", "1"},
+		{"--txid", ids[0], "--txid-file", path},
+	} {
+		out.Reset()
+		argv := append([]string{"wait-all", "--rpc-url", "http://127.0.0.1:8232", "--json"}, args...)
+		if code := RunWithIO(argv, factory, &out, &errBuf); code != 1 || !strings.Contains(out.String(), "invalid_request") {
+			t.Fatalf("%v: code=%d out=%s", args, code, out.String())
+		}
+	}
+	if quorumCount(0.5, 3) != 2 || quorumCount(0.01, 3) != 1 || quorumCount(1, 7) != 7 {
+		t.Fatalf("quorumCount rounding")
+	}
+}
+
+type fakeETARunner struct {
+	fakeRunner
+	eta func(ctx context.Context, txid string, target int64) (time.Duration, error)
+}
+
+func (f fakeETARunner) EstimateConfirmationTime(ctx context.Context, txid string, target int64) (time.Duration, error) {
+	return f.eta(ctx, txid, target)
+}
+
+func TestRun_Status_ETA(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	var gotCfg Config
+	etaErr := error(nil)
+	factory := func(cfg Config) (Runner, error) {
+		gotCfg = cfg
+		return fakeETARunner{
+			fakeRunner: fakeRunner{status: func(context.Context, string) (broadcast.TxStatus, bool, error) {
+				return broadcast.TxStatus{TxID: txid, InMempool: true}, true, nil
+			}},
+			eta: func(_ context.Context, _ string, target int64) (time.Duration, error) {
+				return time.Duration(target)*75*time.Second + 400*time.Millisecond, etaErr
+			},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--confirmations", "6", "--eta", "--eta-sample-blocks", "50", "--json"}, factory, &out, &errBuf)
+	if code != 0 || gotCfg.ETASampleBlocks != 50 || !strings.Contains(out.String(), `"eta":"7m30s"`) || !strings.Contains(out.String(), `"required_confs":6`) {
+		t.Fatalf("code=%d cfg=%d out=%s", code, gotCfg.ETASampleBlocks, out.String())
+	}
+
+	etaErr = errors.New("broadcast: chain too short to estimate block time")
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--confirmations", "6", "--eta", "--json"}, factory, &out, &errBuf)
+	if code != 0 || strings.Contains(out.String(), `"eta"`) || !strings.Contains(errBuf.String(), "warning: eta: broadcast: chain too short") {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--eta", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), "state-file and eta require --confirmations") {
+		t.Fatalf("eta without confirmations: code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--confirmations", "6", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), "confirmations requires --state-file, --eta, or --plus-blocks") {
+		t.Fatalf("confirmations alone: code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_OutputSinks(t *testing.T) {
+	dir := t.TempDir()
+	compactPath := filepath.Join(dir, "out.ndjson")
+	prettyPath := filepath.Join(dir, "out.json")
+	yamlPath := filepath.Join(dir, "out.yaml")
+	txid := strings.Repeat("a", 64)
+	submitErr := error(nil)
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return txid, submitErr }}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json",
+		"--output-file", compactPath, "--output-file", prettyPath + ",pretty", "--output-file", yamlPath + ",yaml"}, factory, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("code=%d stderr=%s", code, errBuf.String())
+	}
+	want := `{"data":{"txid":"` + txid + `"},"status":"ok","version":"v1"}` + "\n"
+	if out.String() != want {
+		t.Fatalf("stdout=%q", out.String())
+	}
+	if b, _ := os.ReadFile(compactPath); string(b) != want {
+		t.Fatalf("compact sink=%q", b)
+	}
+	if b, _ := os.ReadFile(prettyPath); !strings.Contains(string(b), "\n  \"status\": \"ok\",\n") {
+		t.Fatalf("pretty sink=%q", b)
+	}
+	if b, _ := os.ReadFile(yamlPath); !strings.HasPrefix(string(b), "---\n") || !strings.Contains(string(b), "status: ok\n") || !strings.Contains(string(b), "txid: "+txid) {
+		t.Fatalf("yaml sink=%q", b)
+	}
+
+	// Text mode keeps stdout plain; an error envelope still reaches the sinks.
+	submitErr = errors.New("bad-txns-inputs-missingorspent")
+	out.Reset()
+	errBuf.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--output-file", compactPath}, factory, &out, &errBuf)
+	if code == 0 || out.Len() != 0 || !strings.Contains(errBuf.String(), "missingorspent") {
+		t.Fatalf("code=%d stdout=%q stderr=%q", code, out.String(), errBuf.String())
+	}
+	b, _ := os.ReadFile(compactPath)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"status":"err"`) {
+		t.Fatalf("compact sink lines=%q", lines)
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json", "--output-format", "xml"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), "invalid_request") {
+		t.Fatalf("bad format: code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_OutputFormatYAMLOnStdout(t *testing.T) {
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json", "--output-format", "yaml"}, func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return strings.Repeat("b", 64), nil }}, nil
+	}, &out, &errBuf)
+	if code != 0 || !strings.HasPrefix(out.String(), "---\ndata:\n") || !strings.HasSuffix(out.String(), "status: ok\nversion: v1\n") {
+		t.Fatalf("code=%d out=%q", code, out.String())
+	}
+}
+
+func TestRun_Status_TrustTxIDReachesFactory(t *testing.T) {
+	for _, args := range [][]string{
+		{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64), "--trust-txid", "--json"},
+		{"wait-all", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("a", 64), "--trust-txid", "--json"},
+	} {
+		var got Config
+		var out, errBuf bytes.Buffer
+		RunWithIO(args, func(cfg Config) (Runner, error) {
+			got = cfg
+			return nil, errors.New("stop")
+		}, &out, &errBuf)
+		if !got.TrustTxID {
+			t.Fatalf("%s: TrustTxID not set, out=%s", args[0], out.String())
+		}
+	}
+}
+
+type fakeMultiWaitRunner struct {
+	fakeMultiRunner
+	waitAcross func(ctx context.Context, txid string, confirmations int64) (broadcast.TxStatus, error)
+}
+
+func (f fakeMultiWaitRunner) WaitAcross(ctx context.Context, txid string, confirmations int64) (broadcast.TxStatus, error) {
+	return f.waitAcross(ctx, txid, confirmations)
+}
+
+func TestRun_Submit_MultipleRPCURLsWaitAcrossNodes(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://a:8232", "--rpc-url", "http://b:8232", "--raw-tx-hex", "00", "--confirmations", "3", "--json"}, func(Config) (Runner, error) {
+		return fakeMultiWaitRunner{
+			fakeMultiRunner: fakeMultiRunner{
+				fakeRunner: fakeRunner{wait: func(context.Context, string, int64) (broadcast.TxStatus, error) {
+					t.Fatalf("expected the wait to poll every endpoint, not only the primary")
+					return broadcast.TxStatus{}, nil
+				}},
+				endpoints: []string{"http://a:8232", "http://b:8232"},
+				submitAll: func(context.Context, string) (map[string]string, []error) {
+					return map[string]string{"http://a:8232": txid, "http://b:8232": txid}, nil
+				},
+			},
+			waitAcross: func(_ context.Context, got string, confs int64) (broadcast.TxStatus, error) {
+				if got != txid || confs != 3 {
+					t.Fatalf("txid=%s confs=%d", got, confs)
+				}
+				return broadcast.TxStatus{TxID: txid, Confirmations: 3, BlockHash: strings.Repeat("01", 32)}, nil
+			},
+		}, nil
+	}, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"confirmations":3`) {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+}
+
+func TestRun_Submit_ImmatureCoinbaseHint(t *testing.T) {
+	nodeErr := errors.New("16: bad-txns-premature-spend-of-coinbase")
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			return "", &broadcast.ImmatureCoinbaseError{Err: nodeErr, Input: strings.Repeat("cb", 32) + ":0", Confirmations: 90}
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"immature_coinbase"`) || !strings.Contains(out.String(), `"blocks_remaining":10`) || !strings.Contains(out.String(), `"coinbase_maturity":100`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(errBuf.String(), "hint: coinbase outputs need 100 confirmations before they can be spent; "+strings.Repeat("cb", 32)+":0 has 90, retry in 10 more block(s)") {
+		t.Fatalf("code=%d stderr=%s", code, errBuf.String())
+	}
+}
+
+func TestSubmitStreamError_CarriesHintData(t *testing.T) {
+	err := &broadcast.ImmatureCoinbaseError{Err: errors.New("bad-txns-premature-spend-of-coinbase"), Input: strings.Repeat("cb", 32) + ":0", Confirmations: 90}
+	b, _ := json.Marshal(streamResult{Version: jsonVersionV1, Status: "err", Line: 1, Error: submitStreamError(err)})
+	if !strings.Contains(string(b), `"code":"immature_coinbase"`) || !strings.Contains(string(b), `"blocks_remaining":10`) {
+		t.Fatalf("record=%s", b)
+	}
+
+	b, _ = json.Marshal(submitStreamError(errors.New("boom")))
+	if strings.Contains(string(b), `"data"`) {
+		t.Fatalf("record=%s", b)
+	}
+}
+
+func TestRun_Status_SummaryOnly(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	var st broadcast.TxStatus
+	var found bool
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{status: func(context.Context, string) (broadcast.TxStatus, bool, error) {
+			return st, found, nil
+		}}, nil
+	}
+	run := func(extra ...string) (int, string, string) {
+		var out, errBuf bytes.Buffer
+		args := append([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--summary-only"}, extra...)
+		code := RunWithIO(args, factory, &out, &errBuf)
+		return code, out.String(), errBuf.String()
+	}
+
+	st, found = broadcast.TxStatus{TxID: txid, InMempool: true}, true
+	if code, out, _ := run(); code != 0 || out != "mempool\n" {
+		t.Fatalf("code=%d out=%q", code, out)
+	}
+	st = broadcast.TxStatus{TxID: txid, Confirmations: 2, BlockHash: strings.Repeat("01", 32)}
+	if code, out, _ := run(); code != 0 || out != "confirmed\n" {
+		t.Fatalf("code=%d out=%q", code, out)
+	}
+	st, found = broadcast.TxStatus{}, false
+	if code, out, _ := run(); code != 0 || out != "unknown\n" {
+		t.Fatalf("code=%d out=%q", code, out)
+	}
+	if code, out, stderr := run("--found-required"); code != 1 || out != "" || !strings.Contains(stderr, "unknown txid") {
+		t.Fatalf("found-required: code=%d out=%q stderr=%q", code, out, stderr)
+	}
+	if code, out, _ := run("--json"); code != 1 || !strings.Contains(out, "invalid_request") {
+		t.Fatalf("with --json: code=%d out=%q", code, out)
+	}
+}
+
+type fakeWaitTargetsRunner struct {
+	fakeWaitAllRunner
+	waitTargets func(ctx context.Context, targets map[string]int64, minSuccess int) ([]broadcast.WaitAllResult, error)
+}
+
+func (f fakeWaitTargetsRunner) WaitForAllTargets(ctx context.Context, targets map[string]int64, minSuccess int) ([]broadcast.WaitAllResult, error) {
+	return f.waitTargets(ctx, targets, minSuccess)
+}
+
+func TestRun_WaitAll_PerTxIDTargets(t *testing.T) {
+	small, large, plain := strings.Repeat("c", 64), strings.Repeat("a", 64), strings.Repeat("b", 64)
+	path := filepath.Join(t.TempDir(), "txids")
+	if err := os.WriteFile(path, []byte(small+",1\n"+large+", 6\n"+plain+"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var got map[string]int64
+	factory := func(Config) (Runner, error) {
+		return fakeWaitTargetsRunner{waitTargets: func(_ context.Context, targets map[string]int64, _ int) ([]broadcast.WaitAllResult, error) {
+			got = targets
+			// Sorted txid order, as WaitForAllTargets reports.
+			return []broadcast.WaitAllResult{
+				{TxStatus: broadcast.TxStatus{TxID: large, Confirmations: 3}, RequiredConfs: 6},
+				{TxStatus: broadcast.TxStatus{TxID: plain, Confirmations: 3}, RequiredConfs: 2, Met: true},
+				{TxStatus: broadcast.TxStatus{TxID: small, Confirmations: 3}, RequiredConfs: 1, Met: true},
+			}, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"wait-all", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", path, "--confirmations", "2", "--min-success", "2"}, factory, &out, &errBuf)
+	if code != 0 || got[small] != 1 || got[large] != 6 || got[plain] != 2 {
+		t.Fatalf("code=%d targets=%v stderr=%s", code, got, errBuf.String())
+	}
+	want := small + " met confirmations=3/1\n" + large + " pending confirmations=3/6\n" + plain + " met confirmations=3/2\n"
+	if out.String() != want {
+		t.Fatalf("out=%q", out.String())
+	}
+
+	if err := os.WriteFile(path, []byte(small+",x\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out.Reset()
+	code = RunWithIO([]string{"wait-all", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", path, "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), "txid-file line 1: confirmations must be an integer") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeDoctorRunner struct {
+	fakePingRunner
+	health  broadcast.NodeHealth
+	txindex bool
+}
+
+func (f fakeDoctorRunner) NodeHealth(context.Context) (broadcast.NodeHealth, error) {
+	return f.health, nil
+}
+
+func (f fakeDoctorRunner) TxindexEnabled(context.Context) (bool, error) { return f.txindex, nil }
+
+func TestRun_Doctor(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	pingErr := error(nil)
+	factory := func(Config) (Runner, error) {
+		return fakeDoctorRunner{
+			fakePingRunner: fakePingRunner{ping: func(context.Context) error { return pingErr }},
+			health:         broadcast.NodeHealth{Peers: 8, Blocks: 1000, Headers: 1000},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"doctor", "--rpc-url", srv.URL, "--rpc-user", "u", "--rpc-pass", "p"}, factory, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+	for _, want := range []string{"[pass] config", "[pass] url", "[pass] tcp", "[pass] auth", "[warn] txindex", "hint: restart junocashd with -txindex=1", "[pass] sync: blocks=1000 peers=8"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in:\n%s", want, out.String())
+		}
+	}
+
+	pingErr = fmt.Errorf("%w: http 401", broadcast.ErrAuth)
+	out.Reset()
+	code = RunWithIO([]string{"doctor", "--rpc-url", srv.URL, "--rpc-user", "u", "--rpc-pass", "bad", "--json"}, factory, &out, &errBuf)
+	var env struct {
+		Error struct {
+			Code string     `json:"code"`
+			Data doctorData `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil || code != 1 || env.Error.Code != "auth_failed" {
+		t.Fatalf("code=%d err=%v out=%s", code, err, out.String())
+	}
+	checks := env.Error.Data.Checks
+	if env.Error.Data.OK || len(checks) != 6 || checks[3].Status != "fail" || !strings.Contains(checks[3].Hint, "rpcuser/rpcpassword") || checks[5].Status != "skip" {
+		t.Fatalf("data=%+v", env.Error.Data)
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"doctor", "--rpc-url", "ftp://example", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) || !strings.Contains(out.String(), `"name":"url","status":"fail"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeFeeBumpRunner struct {
+	fakeRunner
+	submitWithFeeBump func(ctx context.Context, rawTxHex string, maxBumps int) (string, []broadcast.FeeBump, error)
+}
+
+func (f fakeFeeBumpRunner) SubmitWithFeeBump(ctx context.Context, rawTxHex string, maxBumps int) (string, []broadcast.FeeBump, error) {
+	return f.submitWithFeeBump(ctx, rawTxHex, maxBumps)
+}
+
+func TestRun_Submit_AutoBump(t *testing.T) {
+	orig, bumped := strings.Repeat("0a", 32), strings.Repeat("0b", 32)
+	bump := broadcast.FeeBump{OrigTxID: orig, TxID: bumped, OrigFee: 0.0001, Fee: 0.0002}
+	var fail bool
+	factory := func(Config) (Runner, error) {
+		return fakeFeeBumpRunner{submitWithFeeBump: func(_ context.Context, _ string, maxBumps int) (string, []broadcast.FeeBump, error) {
+			if maxBumps != 2 {
+				t.Fatalf("maxBumps=%d", maxBumps)
+			}
+			if fail {
+				return "", []broadcast.FeeBump{bump}, fmt.Errorf("%w: 66: min relay fee not met", broadcast.ErrFeeTooLow)
+			}
+			return bumped, []broadcast.FeeBump{bump}, nil
+		}}, nil
+	}
+	args := []string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--auto-bump", "--max-bumps", "2"}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO(append(args, "--json"), factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"txid":"`+bumped+`"`) || !strings.Contains(out.String(), `"bumps":[{"orig_txid":"`+orig+`","txid":"`+bumped+`","orig_fee":0.0001,"fee":0.0002}]`) {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	code = RunWithIO(args, factory, &out, &errBuf)
+	if code != 0 || strings.TrimSpace(out.String()) != bumped || !strings.Contains(errBuf.String(), "bumped fee: "+orig+" -> "+bumped) {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+
+	fail = true
+	out.Reset()
+	code = RunWithIO(append(args, "--json"), factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"fee_too_low"`) || !strings.Contains(out.String(), `"bumps":[`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	for _, bad := range [][]string{
+		{"--auto-bump", "--max-bumps", "0"},
+		{"--auto-bump", "--rpc-url", "http://b:8232"},
+		{"--auto-bump", "--include-wtxid"},
+	} {
+		out.Reset()
+		code = RunWithIO(append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, bad...), factory, &out, &errBuf)
+		if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d out=%s", bad, code, out.String())
+		}
+	}
+}
+
+func TestRun_Submit_RPCParam(t *testing.T) {
+	var cfg Config
+	factory := func(c Config) (Runner, error) {
+		cfg = c
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return strings.Repeat("a", 64), nil }}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--rpc-param", "true", "--rpc-param", `{"x":1}`, "--json"}, factory, &out, &errBuf)
+	if code != 0 || len(cfg.SendRawParams) != 2 || string(cfg.SendRawParams[0]) != "true" || string(cfg.SendRawParams[1]) != `{"x":1}` {
+		t.Fatalf("code=%d params=%q out=%s", code, cfg.SendRawParams, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--rpc-param", "{oops", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) || !strings.Contains(out.String(), "not valid JSON") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_RespectLocktime(t *testing.T) {
+	var cfg Config
+	factory := func(c Config) (Runner, error) {
+		cfg = c
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			return "", fmt.Errorf("%w: locktime is height 1010, the next block is 1001 (10 block(s) to go)", broadcast.ErrLocktimeNotMet)
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--respect-locktime", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !cfg.RespectLocktime || !strings.Contains(out.String(), `"code":"locktime_not_met"`) {
+		t.Fatalf("code=%d respect=%v out=%s", code, cfg.RespectLocktime, out.String())
+	}
+}
+
+func TestRun_Submit_VerboseLogsEveryCall(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--verbose", "--json"}, func(cfg Config) (Runner, error) {
+		if cfg.AttemptLog == nil {
+			t.Fatalf("expected --verbose to set AttemptLog")
+		}
+		return fakeRunner{submit: func(context.Context, string) (string, error) {
+			cfg.AttemptLog(broadcast.AttemptEvent{Method: "sendrawtransaction", Attempt: 1, Err: errors.New("connection refused")})
+			cfg.AttemptLog(broadcast.AttemptEvent{Method: "sendrawtransaction", Attempt: 2, Duration: 12 * time.Millisecond})
+			return txid, nil
+		}}, nil
+	}, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(errBuf.String()), "\n")
+	re := regexp.MustCompile(`^run=([0-9a-f]{8}) elapsed=\S+ poll=0 attempt=(\d) sendrawtransaction (.*)$`)
+	var ids []string
+	for i, want := range []string{"error: connection refused (0s)", "ok (12ms)"} {
+		m := re.FindStringSubmatch(lines[i])
+		if m == nil || m[2] != strconv.Itoa(i+1) || m[3] != want {
+			t.Fatalf("line %d=%q", i, lines[i])
+		}
+		ids = append(ids, m[1])
+	}
+	if len(lines) != 2 || ids[0] != ids[1] {
+		t.Fatalf("stderr=%q", errBuf.String())
+	}
+}
+
+func TestRun_Submit_RawTxGzip(t *testing.T) {
+	txHex := "0400008085202f89"
+	var got string
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(_ context.Context, raw string) (string, error) {
+			got = raw
+			return strings.Repeat("a", 64), nil
+		}}, nil
+	}
+	gz := func(s string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+		return buf.Bytes()
+	}
+	txBytes, _ := hex.DecodeString(txHex)
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{
+		"hex.gz":        gz(txHex + "\n"),
+		"b64-of-gz.txt": []byte(base64.StdEncoding.EncodeToString(gz(txHex))),
+		"gz-of-b64":     gz(base64.StdEncoding.EncodeToString(txBytes)),
+		"plain.hex":     []byte(txHex),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		got = ""
+		var out, errBuf bytes.Buffer
+		code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-file", path, "--raw-tx-gzip", "--json"}, factory, &out, &errBuf)
+		if code != 0 || got != txHex {
+			t.Fatalf("%s: code=%d got=%q out=%s", name, code, got, out.String())
+		}
+	}
+
+	truncated := gz(txHex)
+	path := filepath.Join(dir, "truncated.gz")
+	if err := os.WriteFile(path, truncated[:len(truncated)-6], 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	bomb := filepath.Join(dir, "bomb.gz")
+	if err := os.WriteFile(bomb, gz(strings.Repeat("0", maxGzipRawTxBytes+1)), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, args := range [][]string{
+		{"--raw-tx-file", path, "--raw-tx-gzip"},
+		{"--raw-tx-hex", txHex, "--raw-tx-gzip"},
+		{"--raw-tx-file", bomb, "--raw-tx-gzip"},
+		{"--raw-tx-file", bomb, "--raw-tx-hex", txHex, "--raw-tx-gzip"},
+	} {
+		var out, errBuf bytes.Buffer
+		code := RunWithIO(append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--json"}, args...), factory, &out, &errBuf)
+		if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d out=%s", args, code, out.String())
+		}
+	}
+}
+
+type fakeMempoolEntryRunner struct {
+	fakeRunner
+	entry func(ctx context.Context, txid string) (broadcast.MempoolEntry, error)
+}
+
+func (f fakeMempoolEntryRunner) MempoolEntry(ctx context.Context, txid string) (broadcast.MempoolEntry, error) {
+	return f.entry(ctx, txid)
+}
+
+func TestRun_MempoolEntry(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	inMempool := true
+	factory := func(Config) (Runner, error) {
+		return fakeMempoolEntryRunner{entry: func(_ context.Context, got string) (broadcast.MempoolEntry, error) {
+			if !inMempool {
+				return broadcast.MempoolEntry{}, fmt.Errorf("%w: %s", broadcast.ErrNotInMempool, got)
+			}
+			return broadcast.MempoolEntry{TxID: got, Size: 250, Fee: 0.0001, ModifiedFee: 0.0001, DescendantCount: 2, DescendantSize: 500, DescendantFees: 0.0003, Depends: []string{}}, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"mempool-entry", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"descendant_fees":0.0003`) || strings.Contains(out.String(), "ancestor_count") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"mempool-entry", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), "descendants: count=2 size=500 fees=0.0003\n") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	inMempool = false
+	out.Reset()
+	code = RunWithIO([]string{"mempool-entry", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"not_found"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestStatusBatch_CancelledMidBatch(t *testing.T) {
+	txids := []string{strings.Repeat("a", 64), strings.Repeat("b", 64), strings.Repeat("c", 64), strings.Repeat("d", 64)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := fakeRunner{status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+		if txid == txids[1] {
+			cancel()
+			return broadcast.TxStatus{}, false, ctx.Err()
+		}
+		return broadcast.TxStatus{TxID: txid, InMempool: true}, true, nil
+	}}
+
+	in := strings.Join(txids, "\n\n") + "\n"
+	var out bytes.Buffer
+	code := statusBatch(ctx, r, strings.NewReader(in), true, time.Time{}, nil, &out)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if code != 130 || len(lines) != 2 {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+	var first streamResult
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.Status != "ok" || first.TxID != txids[0] {
+		t.Fatalf("first=%s err=%v", lines[0], err)
+	}
+	if lines[1] != `{"version":"v1","status":"cancelled","processed":1,"remaining":3}` {
+		t.Fatalf("trailer=%s", lines[1])
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	out.Reset()
+	statusBatch(ctx, r, strings.NewReader(in), false, time.Time{}, nil, &out)
+	if !strings.HasSuffix(out.String(), `{"version":"v1","status":"cancelled","processed":1}`+"\n") {
+		t.Fatalf("out=%s", out.String())
+	}
+}
+
+func TestSubmitFIFO_CancelledMidSubmit(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "txs.fifo")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := fakeRunner{submit: func(ctx context.Context, rawTxHex string) (string, error) {
+		if rawTxHex == "b0" {
+			cancel()
+			return "", ctx.Err()
+		}
+		return strings.Repeat(rawTxHex[:1], 64), nil
+	}}
+
+	var out bytes.Buffer
+	done := make(chan int, 1)
+	go func() {
+		done <- runSubmitFIFO(ctx, r, fifo, false, false, "", nil, &out)
+	}()
+	w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open fifo: %v", err)
+	}
+	_, _ = w.WriteString("a0\nb0\nc0\n")
+	_ = w.Close()
+
+	select {
+	case code := <-done:
+		if code != 0 {
+			t.Fatalf("exit code=%d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the stream to stop")
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"txid":"`+strings.Repeat("a", 64)+`"`) || lines[1] != `{"version":"v1","status":"cancelled","processed":1}` {
+		t.Fatalf("out=%s", out.String())
+	}
+}
+
+func TestRun_Submit_CompareTxID(t *testing.T) {
+	got := strings.Repeat("ab", 32)
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return got, nil }}, nil
+	}
+	base := []string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO(append(base, "--compare-txid", strings.ToUpper(got)), factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"txid":"`+got+`"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	want := strings.Repeat("cd", 32)
+	out.Reset()
+	code = RunWithIO(append(base, "--compare-txid", want), factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"txid_mismatch"`) || !strings.Contains(out.String(), `"expected":"`+want+`"`) || !strings.Contains(out.String(), `"txid":"`+got+`"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	for _, bad := range [][]string{{"--compare-txid", "abc"}, {"--compare-txid", want, "--auto-bump"}} {
+		out.Reset()
+		code = RunWithIO(append(base, bad...), factory, &out, &errBuf)
+		if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d out=%s", bad, code, out.String())
+		}
+	}
+}
+
+func TestRun_Submit_CompareTxIDByteOrderInternal(t *testing.T) {
+	got := strings.Repeat("01", 16) + strings.Repeat("02", 16)
+	internal := reverseHexBytes(got)
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return got, nil }}, nil
+	}
+	base := []string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--txid-byte-order", "internal", "--json"}
+
+	var out, errBuf bytes.Buffer
+	if code := RunWithIO(append(base, "--compare-txid", internal), factory, &out, &errBuf); code != 0 {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code := RunWithIO(append(base, "--compare-txid", got), factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"txid_mismatch"`) || !strings.Contains(out.String(), `"txid":"`+internal+`"`) ||
+		!strings.Contains(out.String(), "node reported txid "+internal) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeOperationRunner struct {
+	fakeRunner
+	operation func(ctx context.Context, opid string) (broadcast.OperationResult, error)
+	wait      func(ctx context.Context, opid string) (broadcast.OperationResult, error)
+}
+
+func (f fakeOperationRunner) Operation(ctx context.Context, opid string) (broadcast.OperationResult, error) {
+	return f.operation(ctx, opid)
+}
+
+func (f fakeOperationRunner) WaitForOperation(ctx context.Context, opid string) (broadcast.OperationResult, error) {
+	return f.wait(ctx, opid)
+}
+
+func TestRun_OpResult(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	factory := func(Config) (Runner, error) {
+		return fakeOperationRunner{
+			operation: func(_ context.Context, opid string) (broadcast.OperationResult, error) {
+				if opid == "opid-failed" {
+					return broadcast.OperationResult{OpID: opid, Status: "failed", Error: "Insufficient funds"}, nil
+				}
+				return broadcast.OperationResult{OpID: opid, Status: "executing"}, nil
+			},
+			wait: func(_ context.Context, opid string) (broadcast.OperationResult, error) {
+				return broadcast.OperationResult{OpID: opid, Status: "success", TxID: txid}, nil
+			},
+		}, nil
+	}
+	base := []string{"op-result", "--rpc-url", "http://127.0.0.1:8232"}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO(append(base, "--opid", "opid-1"), factory, &out, &errBuf)
+	if code != 0 || out.String() != "executing\n" {
+		t.Fatalf("code=%d out=%q", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO(append(base, "--opid", "opid-1", "--wait"), factory, &out, &errBuf)
+	if code != 0 || out.String() != txid+"\n" {
+		t.Fatalf("code=%d out=%q", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO(append(base, "--opid", "opid-failed", "--json"), factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"operation_failed"`) || !strings.Contains(out.String(), "Insufficient funds") || !strings.Contains(out.String(), `"status":"failed"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO(append(base, "--json"), factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeOutputsRunner struct {
+	fakeRunner
+	inspect func(ctx context.Context, raw string) ([]broadcast.TxOutput, error)
+}
+
+func (f fakeOutputsRunner) InspectOutputs(ctx context.Context, raw string) ([]broadcast.TxOutput, error) {
+	return f.inspect(ctx, raw)
+}
+
+func TestRun_Outputs(t *testing.T) {
+	zat := int64(150000000)
+	factory := func(Config) (Runner, error) {
+		return fakeOutputsRunner{inspect: func(_ context.Context, raw string) ([]broadcast.TxOutput, error) {
+			if raw != "00" {
+				t.Fatalf("raw=%q", raw)
+			}
+			return []broadcast.TxOutput{
+				{Pool: broadcast.PoolTransparent, Index: 0, Type: "pubkeyhash", Addresses: []string{"t1pay"}, Value: "1.5", ValueZat: &zat},
+				{Pool: broadcast.PoolOrchard, Index: 0, Opaque: true},
+			}, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"outputs", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `{"pool":"transparent","index":0,"type":"pubkeyhash","addresses":["t1pay"],"value":"1.5","value_zat":150000000,"opaque":false}`) ||
+		!strings.Contains(out.String(), `{"pool":"orchard","index":0,"opaque":true}`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"outputs", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00"}, factory, &out, &errBuf)
+	if code != 0 || out.String() != "transparent 0 pubkeyhash 1.5 t1pay\norchard 0 opaque\n" {
+		t.Fatalf("code=%d out=%q", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"outputs", "--rpc-url", "http://127.0.0.1:8232", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Status_FoundGrace(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var calls int
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{status: func(_ context.Context, got string) (broadcast.TxStatus, bool, error) {
+			calls++
+			if calls < 3 {
+				return broadcast.TxStatus{}, false, nil
+			}
+			return broadcast.TxStatus{TxID: got, InMempool: true}, true, nil
+		}}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--found-grace", "5s", "--poll", "10ms", "--json"}, factory, &out, &errBuf)
+	if code != 0 || calls != 3 || !strings.Contains(out.String(), `"in_mempool":true`) {
+		t.Fatalf("code=%d calls=%d out=%s", code, calls, out.String())
+	}
+
+	// Without a grace period the first miss is final.
+	calls = 0
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json"}, factory, &out, &errBuf)
+	if code != 1 || calls != 1 || !strings.Contains(out.String(), `"code":"not_found"`) {
+		t.Fatalf("code=%d calls=%d out=%s", code, calls, out.String())
+	}
+
+	// A grace shorter than the node's delay still ends in not_found.
+	calls = -100
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--found-grace", "50ms", "--poll", "10ms", "--json"}, factory, &out, &errBuf)
+	if code != 1 || calls < -98 || !strings.Contains(out.String(), `"code":"not_found"`) {
+		t.Fatalf("code=%d calls=%d out=%s", code, calls, out.String())
+	}
+}
+
+type fakeNodeWarningsRunner struct {
+	fakeDoctorRunner
+	warnings []string
+}
+
+func (f fakeNodeWarningsRunner) NodeWarnings(context.Context) ([]string, error) {
+	return f.warnings, nil
+}
+
+func TestRun_NodeWarnings(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	warnings := []string{"unknown new rules activated"}
+	var submitted bool
+	factory := func(Config) (Runner, error) {
+		return fakeNodeWarningsRunner{
+			fakeDoctorRunner: fakeDoctorRunner{
+				fakePingRunner: fakePingRunner{
+					fakeRunner: fakeRunner{submit: func(context.Context, string) (string, error) {
+						submitted = true
+						return strings.Repeat("ab", 32), nil
+					}},
+					ping: func(context.Context) error { return nil },
+				},
+				health:  broadcast.NodeHealth{Peers: 8, Blocks: 1000, Headers: 1000},
+				txindex: true,
+			},
+			warnings: warnings,
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"doctor", "--rpc-url", srv.URL, "--rpc-user", "u", "--rpc-pass", "p", "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `{"name":"warnings","status":"warn","detail":"unknown new rules activated"`) ||
+		!strings.Contains(out.String(), `"warnings":["unknown new rules activated"]`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--node-warnings"}, factory, &out, &errBuf)
+	if code != 0 || !submitted || errBuf.String() != "warning: node reports: unknown new rules activated\n" {
+		t.Fatalf("code=%d submitted=%v stderr=%q", code, submitted, errBuf.String())
+	}
+
+	warnings = nil
+	out.Reset()
+	errBuf.Reset()
+	code = RunWithIO([]string{"doctor", "--rpc-url", srv.URL, "--rpc-user", "u", "--rpc-pass", "p"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), "[pass] warnings: none") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--node-warnings"}, factory, &out, &errBuf)
+	if code != 0 || errBuf.Len() != 0 {
+		t.Fatalf("code=%d stderr=%q", code, errBuf.String())
+	}
+}
+
+func TestRun_Submit_WithEntry(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	mined := false
+	factory := func(Config) (Runner, error) {
+		return fakeMempoolEntryRunner{
+			fakeRunner: fakeRunner{submit: func(context.Context, string) (string, error) { return txid, nil }},
+			entry: func(_ context.Context, got string) (broadcast.MempoolEntry, error) {
+				if mined {
+					return broadcast.MempoolEntry{}, fmt.Errorf("%w: %s", broadcast.ErrNotInMempool, got)
+				}
+				return broadcast.MempoolEntry{TxID: got, Size: 250, Fee: 0.0001, ModifiedFee: 0.0001, DescendantCount: 1, DescendantSize: 250, DescendantFees: 0.0001, Depends: []string{}}, nil
+			},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--with-entry", "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"entry":{"txid":"`+txid+`","size":250,"fee":0.0001`) || !strings.Contains(out.String(), `"txid":"`+txid+`"}`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	// A tx mined before the lookup just has no entry.
+	mined = true
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--with-entry", "--json"}, factory, &out, &errBuf)
+	if code != 0 || strings.Contains(out.String(), `"entry"`) || errBuf.Len() != 0 {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+}
+
+func TestRun_RPCJSONRPCVersion(t *testing.T) {
+	var versions []any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		versions = append(versions, req["jsonrpc"])
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":null,"error":{"code":-5,"message":"No such mempool or blockchain transaction"},"id":1}`))
+	}))
+	defer srv.Close()
+
+	var out, errBuf bytes.Buffer
+	RunWithIO([]string{"status", "--rpc-url", srv.URL, "--rpc-user", "u", "--rpc-pass", "p", "--rpc-jsonrpc-version", "2.0", "--txid", strings.Repeat("a", 64), "--json"}, defaultFactory, &out, &errBuf)
+	if len(versions) == 0 || versions[0] != "2.0" {
+		t.Fatalf("jsonrpc versions sent=%v out=%s", versions, out.String())
+	}
+
+	out.Reset()
+	code := RunWithIO([]string{"status", "--rpc-url", srv.URL, "--rpc-jsonrpc-version", "3.0", "--txid", strings.Repeat("a", 64), "--json"}, defaultFactory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), "rpc-jsonrpc-version must be 1.0 or 2.0") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Fields(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	var calls int
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{
+			submit: func(context.Context, string) (string, error) { calls++; return txid, nil },
+			status: func(_ context.Context, got string) (broadcast.TxStatus, bool, error) {
+				calls++
+				return broadcast.TxStatus{TxID: got, Confirmations: 3, BlockHash: "00ff", BlockTime: 1700000000}, true, nil
+			},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json", "--fields", "txid, confirmations"}, factory, &out, &errBuf)
+	if code != 0 || out.String() != `{"data":{"confirmations":3,"txid":"`+txid+`"},"status":"ok","version":"v1"}`+"\n" {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json", "--fields", "txid"}, factory, &out, &errBuf)
+	if code != 0 || out.String() != `{"data":{"txid":"`+txid+`"},"status":"ok","version":"v1"}`+"\n" {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	// Unknown names fail before anything is sent.
+	calls = 0
+	for _, args := range [][]string{
+		{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json", "--fields", "txid,fee"},
+		{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json", "--fields", "confirmed"},
+	} {
+		out.Reset()
+		code = RunWithIO(args, factory, &out, &errBuf)
+		if code != 1 || calls != 0 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+			t.Fatalf("%v: code=%d calls=%d out=%s", args, code, calls, out.String())
+		}
+	}
+	errBuf.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--fields", "txid"}, factory, &out, &errBuf)
+	if code != 1 || calls != 0 || !strings.Contains(errBuf.String(), "fields requires --json") {
+		t.Fatalf("code=%d calls=%d stderr=%s", code, calls, errBuf.String())
+	}
+}
+
+type fakePropagationRunner struct {
+	fakeMultiRunner
+	waitPropagation func(ctx context.Context, txid string, k int) error
+}
+
+func (f fakePropagationRunner) WaitPropagation(ctx context.Context, txid string, k int) error {
+	return f.waitPropagation(ctx, txid, k)
+}
+
+func TestRun_Submit_RequireNodes(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	propErr := error(nil)
+	var gotK int
+	factory := func(Config) (Runner, error) {
+		return fakePropagationRunner{
+			fakeMultiRunner: fakeMultiRunner{
+				endpoints: []string{"a", "b", "c"},
+				submitAll: func(context.Context, string) (map[string]string, []error) {
+					return map[string]string{"a": txid, "b": txid, "c": txid}, nil
+				},
+			},
+			waitPropagation: func(_ context.Context, got string, k int) error {
+				if got != txid {
+					t.Fatalf("txid=%s", got)
+				}
+				gotK = k
+				return propErr
+			},
+		}, nil
+	}
+	args := []string{"submit", "--rpc-url", "http://a:8232", "--rpc-url", "http://b:8232", "--rpc-url", "http://c:8232", "--raw-tx-hex", "00", "--json"}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO(append(args, "--require-nodes", "2"), factory, &out, &errBuf)
+	if code != 0 || gotK != 2 || !strings.Contains(out.String(), `"status":"ok"`) {
+		t.Fatalf("code=%d k=%d out=%s", code, gotK, out.String())
+	}
+
+	propErr = fmt.Errorf("broadcast: tx seen by 1 of 2 required nodes: %w", context.DeadlineExceeded)
+	out.Reset()
+	code = RunWithIO(append(args, "--require-nodes", "2"), factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"timeout"`) || !strings.Contains(out.String(), `"require_nodes":2`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO(append(args, "--require-nodes", "4"), factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeRawStatusRunner struct {
+	fakeRunner
+	raw json.RawMessage
+}
+
+func (f fakeRawStatusRunner) StatusRaw(_ context.Context, txid string) (broadcast.TxStatus, bool, json.RawMessage, error) {
+	return broadcast.TxStatus{TxID: txid, Confirmations: 2}, true, f.raw, nil
+}
+
+func TestRun_Status_Raw(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	raw := json.RawMessage(`{"txid":"` + txid + `","confirmations":2,"size":250}`)
+	factory := func(Config) (Runner, error) { return fakeRawStatusRunner{raw: raw}, nil }
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--raw", "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"raw":{"txid":"`+txid+`","confirmations":2,"size":250}`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--raw", "--json", "--pretty"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), "\n      \"size\": 250") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	// Found through a fallback: no raw, and a note on stderr.
+	factory = func(Config) (Runner, error) { return fakeRawStatusRunner{}, nil }
+	out.Reset()
+	errBuf.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--raw", "--json"}, factory, &out, &errBuf)
+	if code != 0 || strings.Contains(out.String(), `"raw"`) || !strings.Contains(errBuf.String(), "no verbose getrawtransaction response") {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--raw", "--eta", "--confirmations", "1", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), "raw cannot be combined") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_RetryBudgetFlagsReachFactory(t *testing.T) {
+	var out, errBuf bytes.Buffer
+
+	var got Config
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--retry-budget", "3s", "--retry-jitter"}, func(cfg Config) (Runner, error) {
+		got = cfg
+		return fakeRunner{
+			submit: func(ctx context.Context, rawTxHex string) (string, error) {
+				return strings.Repeat("a", 64), nil
+			},
+		}, nil
+	}, &out, &errBuf)
+	if code != 0 || got.RetryBudget != 3*time.Second || !got.RetryJitter {
+		t.Fatalf("code=%d cfg=%+v stderr=%s", code, got, errBuf.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--retry-budget", "-1s", "--json"}, func(Config) (Runner, error) {
+		t.Fatalf("factory called")
+		return nil, nil
+	}, &out, &errBuf)
+	if code == 0 || !strings.Contains(out.String(), "retry-budget must be") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Drain_SettlesQueueFiles(t *testing.T) {
+	dir := t.TempDir()
+	known, _ := broadcast.TxIDFromHex("01000000")
+	files := map[string]string{
+		"a.hex":            "01000000\n",
+		"b.hex":            "02000000",
+		"c.hex":            "03000000",
+		"d.hex":            "04000000",
+		"e.hex.processing": "05000000",
+		"f.hex":            "not hex",
+		"notes.txt":        "ignored",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	var submitted []string
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{
+			status: func(_ context.Context, txid string) (broadcast.TxStatus, bool, error) {
+				if txid == known {
+					return broadcast.TxStatus{TxID: txid, Confirmations: 1}, true, nil
+				}
+				return broadcast.TxStatus{}, false, nil
+			},
+			submit: func(_ context.Context, raw string) (string, error) {
+				submitted = append(submitted, raw)
+				switch raw {
+				case "03000000":
+					return "", &junocashd.RPCError{Code: -26, Message: "bad-txns-inputs-spent"}
+				case "04000000":
+					return "", errors.New("dial tcp 127.0.0.1:8232: connect: connection refused")
+				}
+				return strings.Repeat("b", 64), nil
+			},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"drain", "--rpc-url", "http://127.0.0.1:8232", "--queue-dir", dir, "--once"}, factory, &out, &errBuf)
+	if code != 1 {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 || !strings.Contains(lines[0], `"file":"e.hex"`) || !strings.Contains(lines[1], `"status":"already_present"`) {
+		t.Fatalf("results:\n%s", out.String())
+	}
+	if fmt.Sprint(submitted) != "[05000000 02000000 03000000 04000000]" {
+		t.Fatalf("submitted=%v", submitted)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := "[a.hex.done b.hex.done c.hex.failed d.hex e.hex.done f.hex.failed notes.txt]"
+	if fmt.Sprint(names) != want {
+		t.Fatalf("files=%v want %s", names, want)
+	}
+	if !strings.Contains(lines[4], `"status":"retry"`) || !strings.Contains(lines[3], `"status":"err"`) {
+		t.Fatalf("results:\n%s", out.String())
+	}
+}
+
+func TestRun_Status_PlusBlocks(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{status: func(_ context.Context, got string) (broadcast.TxStatus, bool, error) {
+			return broadcast.TxStatus{TxID: got, Confirmations: 4, BlockHash: strings.Repeat("c", 64)}, true, nil
+		}}, nil
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--plus-blocks", "2"}, `"plus_blocks":2,"projected_confirmations":6}`},
+		{[]string{"--plus-blocks", "2", "--confirmations", "6"}, `"projected_confirmations":6,"required_confs":6,"meets_target":true}`},
+		{[]string{"--plus-blocks", "2", "--confirmations", "6", "--confirmation-base", "block-exclusive"}, `"required_confs":6,"meets_target":false}`},
+	} {
+		var out, errBuf bytes.Buffer
+		args := append([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json"}, tc.args...)
+		if code := RunWithIO(args, factory, &out, &errBuf); code != 0 || !strings.Contains(out.String(), tc.want) {
+			t.Fatalf("%v: code=%d out=%s", tc.args, code, out.String())
+		}
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--plus-blocks", "2", "--raw", "--json"}, factory, &out, &errBuf)
+	if code == 0 || !strings.Contains(out.String(), "plus-blocks cannot be combined") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_Timings(t *testing.T) {
+	txid := strings.Repeat("a", 64)
+	factory := func(Config) (Runner, error) {
+		return fakeRunner{
+			submit: func(context.Context, string) (string, error) {
+				time.Sleep(20 * time.Millisecond)
+				return txid, nil
+			},
+			wait: func(_ context.Context, got string, n int64) (broadcast.TxStatus, error) {
+				time.Sleep(30 * time.Millisecond)
+				return broadcast.TxStatus{TxID: got, Confirmations: n}, nil
+			},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--confirmations", "1", "--timings", "--json"}, factory, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+	var env struct {
+		Data struct {
+			Timings map[string]string `json:"timings"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	send, err1 := time.ParseDuration(env.Data.Timings["send"])
+	confirm, err2 := time.ParseDuration(env.Data.Timings["confirm"])
+	total, err3 := time.ParseDuration(env.Data.Timings["total"])
+	if err := errors.Join(err1, err2, err3); err != nil || send < 20*time.Millisecond || confirm < 30*time.Millisecond || total < send+confirm {
+		t.Fatalf("timings=%v err=%v", env.Data.Timings, err)
+	}
+
+	// Without a wait there is no confirm phase.
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--timings", "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"timings":{"send":"`) || strings.Contains(out.String(), `"confirm"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+type fakeBlockStatusRunner struct {
+	fakeRunner
+	st broadcast.BlockStatus
+}
+
+func (f fakeBlockStatusRunner) BlockStatus(_ context.Context, hash string) (broadcast.BlockStatus, bool, error) {
+	if hash != f.st.BlockHash {
+		return broadcast.BlockStatus{}, false, nil
+	}
+	return f.st, true, nil
+}
+
+func TestRun_BlockStatus(t *testing.T) {
+	hash := strings.Repeat("1", 64)
+	st := broadcast.BlockStatus{BlockHash: hash, ActiveChain: true, Height: 100, Confirmations: 3}
+	factory := func(Config) (Runner, error) { return fakeBlockStatusRunner{st: st}, nil }
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"block-status", "--rpc-url", "http://127.0.0.1:8232", "--hash", hash, "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"active_chain":true,"height":100,"confirmations":3`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	// An orphaned block is reported, but exits 1.
+	st.ActiveChain, st.Confirmations = false, -1
+	out.Reset()
+	code = RunWithIO([]string{"block-status", "--rpc-url", "http://127.0.0.1:8232", "--hash", hash, "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"status":"ok"`) || !strings.Contains(out.String(), `"confirmations":-1`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"block-status", "--rpc-url", "http://127.0.0.1:8232", "--hash", strings.Repeat("2", 64), "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"not_found"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_SettleReachesFactory(t *testing.T) {
+	var got Config
+	factory := func(cfg Config) (Runner, error) {
+		got = cfg
+		return fakeRunner{
+			submit: func(context.Context, string) (string, error) { return strings.Repeat("a", 64), nil },
+			wait: func(_ context.Context, txid string, n int64) (broadcast.TxStatus, error) {
+				return broadcast.TxStatus{TxID: txid, Confirmations: n}, nil
+			},
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--confirmations", "2", "--settle", "25s", "--poll", "10s", "--json"}, factory, &out, &errBuf)
+	if code != 0 || got.SettlePolls != 3 {
+		t.Fatalf("code=%d settle polls=%d out=%s", code, got.SettlePolls, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--settle", "25s", "--json"}, factory, &out, &errBuf)
+	if code == 0 || !strings.Contains(out.String(), "settle requires --confirmations") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_RPCCredentialCommands(t *testing.T) {
+	t.Setenv("JUNO_RPC_USER", "envuser")
+	t.Setenv("JUNO_RPC_PASS", "envpass")
+	var got Config
+	factory := func(cfg Config) (Runner, error) {
+		got = cfg
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return strings.Repeat("a", 64), nil }}, nil
+	}
+	run := func(extra ...string) (int, string) {
+		var out, errBuf bytes.Buffer
+		args := append([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--json"}, extra...)
+		return RunWithIO(args, factory, &out, &errBuf), out.String()
+	}
+
+	// The command wins over the environment.
+	if code, out := run("--rpc-pass-command", "echo  s3cret "); code != 0 || got.RPCPass != "s3cret" || got.RPCUser != "envuser" {
+		t.Fatalf("code=%d user=%q pass=%q out=%s", code, got.RPCUser, got.RPCPass, out)
+	}
+	// An explicit value wins over the command.
+	if code, out := run("--rpc-user", "flaguser", "--rpc-user-command", "echo cmduser"); code != 0 || got.RPCUser != "flaguser" {
+		t.Fatalf("code=%d user=%q out=%s", code, got.RPCUser, out)
+	}
+	if code, out := run("--rpc-pass-command", "false"); code == 0 || !strings.Contains(out, `"code":"invalid_request"`) || !strings.Contains(out, "rpc-pass-command failed") {
+		t.Fatalf("code=%d out=%s", code, out)
+	}
+	if code, out := run("--rpc-pass-command", "true"); code == 0 || !strings.Contains(out, "rpc-pass-command printed nothing") {
+		t.Fatalf("code=%d out=%s", code, out)
+	}
+}
+
+type fakeFeeRunner struct {
+	fakeRunner
+	fee broadcast.TxFee
+	err error
+}
+
+func (f fakeFeeRunner) TxFee(_ context.Context, txid string) (broadcast.TxFee, bool, error) {
+	if f.err != nil {
+		return broadcast.TxFee{}, true, f.err
+	}
+	if txid != f.fee.TxID {
+		return broadcast.TxFee{}, false, nil
+	}
+	return f.fee, true, nil
+}
+
+func TestRun_Fee(t *testing.T) {
+	txid := strings.Repeat("1", 64)
+	fee := broadcast.TxFee{TxID: txid, Fee: 10_000, TransparentIn: 110_000, TransparentOut: 100_000}
+	factory := func(Config) (Runner, error) { return fakeFeeRunner{fee: fee}, nil }
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"fee", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json"}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(out.String(), `"fee_zat":10000,"transparent_in_zat":110000`) || strings.Contains(out.String(), `"note"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	// Text mode also writes the shielded note to stderr.
+	fee.Shielded, fee.Note = true, "tx has shielded components"
+	out.Reset()
+	code = RunWithIO([]string{"fee", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid}, factory, &out, &errBuf)
+	if code != 0 || !strings.Contains(errBuf.String(), "note: tx has shielded components") {
+		t.Fatalf("code=%d out=%s stderr=%s", code, out.String(), errBuf.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"fee", "--rpc-url", "http://127.0.0.1:8232", "--txid", strings.Repeat("2", 64), "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"not_found"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	factory = func(Config) (Runner, error) { return fakeFeeRunner{err: broadcast.ErrCoinbaseFee}, nil }
+	out.Reset()
+	code = RunWithIO([]string{"fee", "--rpc-url", "http://127.0.0.1:8232", "--txid", txid, "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), `"code":"invalid_request"`) {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+}
+
+func TestRun_Submit_EmptyTxID(t *testing.T) {
+	var got Config
+	factory := func(cfg Config) (Runner, error) {
+		got = cfg
+		return fakeRunner{
+			submit: func(context.Context, string) (string, error) { return "", broadcast.ErrEmptyTxID },
+		}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--empty-txid-fallback", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !got.EmptyTxIDFallback || !strings.Contains(out.String(), `"code":"empty_txid"`) {
+		t.Fatalf("code=%d cfg=%+v out=%s", code, got.EmptyTxIDFallback, out.String())
+	}
+}
+
+func TestErrCode_BearerTransportKeepsContextErrors(t *testing.T) {
+	const token = "s3cr3t-token"
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(block)
+	rpc := junocashd.New(srv.URL, "", "", broadcast.WithBearerToken(token))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := rpc.Call(ctx, "getblockcount", nil, nil)
+	if got := errCode(err); got != "timeout" || strings.Contains(err.Error(), token) {
+		t.Fatalf("code=%s err=%v", got, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	err = rpc.Call(ctx, "getblockcount", nil, nil)
+	if got := errCode(err); got != "cancelled" {
+		t.Fatalf("code=%s err=%v", got, err)
+	}
+}
+
+func TestRun_Submit_AllowShieldedOutputs(t *testing.T) {
+	var got Config
+	factory := func(cfg Config) (Runner, error) {
+		got = cfg
+		return fakeRunner{submit: func(context.Context, string) (string, error) { return strings.Repeat("a", 64), nil }}, nil
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--allow-shielded-outputs", "--json"}, factory, &out, &errBuf)
+	if code != 1 || !strings.Contains(out.String(), "allow-shielded-outputs requires --allow-address-file or --exact-outputs") {
+		t.Fatalf("code=%d out=%s", code, out.String())
+	}
+
+	out.Reset()
+	code = RunWithIO([]string{"submit", "--rpc-url", "http://127.0.0.1:8232", "--raw-tx-hex", "00", "--expect-output", "t1pay:1", "--exact-outputs", "--allow-shielded-outputs", "--json"}, factory, &out, &errBuf)
+	if code != 0 || !got.AllowShieldedOutputs || !got.ExactOutputs {
+		t.Fatalf("code=%d cfg=%+v out=%s", code, got, out.String())
+	}
+}
+
+type fakeBulkRunner struct {
+	fakeRunner
+	bulk func(ctx context.Context, txids []string) ([]broadcast.TxStatus, error)
+}
+
+func (f fakeBulkRunner) Batching() bool { return true }
+
+func (f fakeBulkRunner) StatusBulk(ctx context.Context, txids []string) ([]broadcast.TxStatus, error) {
+	return f.bulk(ctx, txids)
+}
+
+func TestRun_StatusBatch_UsesBulkLookups(t *testing.T) {
+	var txids []string
+	for i := range statusBatchChunk + 2 {
+		txids = append(txids, fmt.Sprintf("%064x", i))
+	}
+	scanned := txids[5]
+	txidFile := filepath.Join(t.TempDir(), "txids")
+	if err := os.WriteFile(txidFile, []byte(strings.Join(txids, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks []int
+	var single []string
+	r := fakeBulkRunner{
+		fakeRunner: fakeRunner{status: func(_ context.Context, txid string) (broadcast.TxStatus, bool, error) {
+			single = append(single, txid)
+			// Only the recent-block scan of Status finds it.
+			return broadcast.TxStatus{TxID: txid, Confirmations: 2, BlockHash: strings.Repeat("b", 64)}, txid == scanned, nil
+		}},
+		bulk: func(_ context.Context, ids []string) ([]broadcast.TxStatus, error) {
+			chunks = append(chunks, len(ids))
+			sts := make([]broadcast.TxStatus, len(ids))
+			for i, id := range ids {
+				sts[i] = broadcast.TxStatus{TxID: id, InMempool: true}
+				if id == scanned || id == txids[7] {
+					sts[i] = broadcast.TxStatus{TxID: id}
+				}
+			}
+			return sts, nil
+		},
+	}
+
+	var out, errBuf bytes.Buffer
+	code := RunWithIO([]string{"status-batch", "--rpc-url", "http://127.0.0.1:8232", "--txid-file", txidFile}, func(Config) (Runner, error) { return r, nil }, &out, &errBuf)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if code != 1 || len(lines) != len(txids) || !reflect.DeepEqual(chunks, []int{statusBatchChunk, 2}) {
+		t.Fatalf("code=%d lines=%d chunks=%v", code, len(lines), chunks)
+	}
+	if !reflect.DeepEqual(single, []string{scanned, txids[7]}) {
+		t.Fatalf("single lookups=%v", single)
+	}
+	if !strings.Contains(lines[5], `"status":"ok"`) || !strings.Contains(lines[5], `"confirmations":2`) ||
+		!strings.Contains(lines[7], `"code":"not_found"`) || !strings.Contains(lines[101], `"line":102`) {
+		t.Fatalf("out=%s", out.String())
+	}
+}
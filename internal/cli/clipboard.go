@@ -0,0 +1,48 @@
+//go:build clipboard
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// readClipboard returns the system clipboard's text using the platform's
+// paste tool: pbpaste on macOS, PowerShell's Get-Clipboard on Windows, and
+// wl-paste, xclip or xsel (whichever is installed first) elsewhere.
+func readClipboard() (string, error) {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbpaste"}}
+	case "windows":
+		candidates = [][]string{{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}}
+	case "linux", "freebsd", "netbsd", "openbsd", "dragonfly":
+		candidates = [][]string{
+			{"wl-paste", "--no-newline"},
+			{"xclip", "-selection", "clipboard", "-o"},
+			{"xsel", "--clipboard", "--output"},
+		}
+	default:
+		return "", fmt.Errorf("raw-tx-clipboard: clipboard is not supported on %s", runtime.GOOS)
+	}
+
+	for _, argv := range candidates {
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		out, err := exec.CommandContext(ctx, path, argv[1:]...).Output()
+		cancel()
+		if err != nil {
+			return "", fmt.Errorf("raw-tx-clipboard: %s: %w", argv[0], err)
+		}
+		return string(out), nil
+	}
+	return "", errors.New("raw-tx-clipboard: no clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
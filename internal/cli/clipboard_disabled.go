@@ -0,0 +1,9 @@
+//go:build !clipboard
+
+package cli
+
+import "errors"
+
+func readClipboard() (string, error) {
+	return "", errors.New("raw-tx-clipboard: clipboard support not compiled in (build with -tags clipboard)")
+}
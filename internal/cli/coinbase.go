@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+// writeSubmitErr is writeErr for a failed submit, adding a maturity hint to
+// immature-coinbase rejections: error data in JSON mode, a "hint:" line on
+// stderr otherwise.
+func writeSubmitErr(stdout, stderr io.Writer, jsonOut bool, err error) int {
+	data := submitErrData(err)
+	if data == nil {
+		return writeErr(stdout, stderr, jsonOut, errCode(err), err.Error())
+	}
+	code := writeErrData(stdout, stderr, jsonOut, errCode(err), err.Error(), data)
+	if !jsonOut {
+		fmt.Fprintf(stderr, "hint: %s\n", data["hint"])
+	}
+	return code
+}
+
+// submitStreamError is the NDJSON error record for a failed submit, with the
+// data writeSubmitErr would add.
+func submitStreamError(err error) *streamError {
+	return &streamError{Code: errCode(err), Message: err.Error(), Data: submitErrData(err)}
+}
+
+// submitErrData is the error data for a failed submit: the maturity hint for
+// immature-coinbase rejections, nil for anything else.
+func submitErrData(err error) map[string]any {
+	var ce *broadcast.ImmatureCoinbaseError
+	if !errors.As(err, &ce) {
+		return nil
+	}
+	hint := fmt.Sprintf("coinbase outputs need %d confirmations before they can be spent", broadcast.CoinbaseMaturity)
+	data := map[string]any{
+		"hint":              hint,
+		"coinbase_maturity": broadcast.CoinbaseMaturity,
+	}
+	if n := ce.BlocksRemaining(); n >= 0 {
+		data["hint"] = fmt.Sprintf("%s; %s has %d, retry in %d more block(s)", hint, ce.Input, ce.Confirmations, n)
+		data["coinbase_input"] = ce.Input
+		data["confirmations"] = ce.Confirmations
+		data["blocks_remaining"] = n
+	}
+	return data
+}
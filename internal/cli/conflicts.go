@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type conflictRunner interface {
+	CheckConflicts(ctx context.Context, rawTxHex string) ([]broadcast.Conflict, error)
+}
+
+func runCheckConflicts(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check-conflicts", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var rawTxHex string
+	var rawTxFile string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&rawTxHex, "raw-tx-hex", "", "signed raw tx hex")
+	fs.StringVar(&rawTxFile, "raw-tx-file", "", "path to file containing signed raw tx hex")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	raw, err := loadHexInput(rawTxHex, rawTxFile, "raw-tx-hex", "raw-tx-file")
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	cr, ok := r.(conflictRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "conflict checks are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conflicts, err := cr.CheckConflicts(ctx, raw)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		if conflicts == nil {
+			conflicts = []broadcast.Conflict{}
+		}
+		return writeOK(stdout, jsonOut, map[string]any{"conflicts": conflicts})
+	}
+	for _, c := range conflicts {
+		fmt.Fprintf(stdout, "%s:%d spent by %s\n", c.TxID, c.Vout, c.SpendingTxID)
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// credentialCommand runs a --rpc-*-command credential helper (split on
+// whitespace, no shell, like --on-confirmed) and returns its trimmed stdout.
+// The helper's stderr is only used to explain a failure.
+func credentialCommand(command, flagName string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("%s is empty", flagName)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			return "", fmt.Errorf("%s failed: %v: %s", flagName, err, msg)
+		}
+		return "", fmt.Errorf("%s failed: %v", flagName, err)
+	}
+	v := strings.TrimSpace(stdout.String())
+	if v == "" {
+		return "", errors.New(flagName + " printed nothing")
+	}
+	return v, nil
+}
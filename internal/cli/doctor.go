@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type doctorRunner interface {
+	Ping(ctx context.Context) error
+	NodeHealth(ctx context.Context) (broadcast.NodeHealth, error)
+	TxindexEnabled(ctx context.Context) (bool, error)
+}
+
+// doctorCheck is one line of the doctor checklist. Status is pass, warn,
+// fail, or skip (an earlier check failed).
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+type doctorData struct {
+	OK       bool          `json:"ok"`
+	Checks   []doctorCheck `json:"checks"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// doctor accumulates checks; once one fails, the ones that depend on it are
+// recorded as skipped.
+type doctor struct {
+	checks   []doctorCheck
+	warnings []string
+	failCode string
+	failMsg  string
+}
+
+func (d *doctor) add(c doctorCheck) {
+	d.checks = append(d.checks, c)
+}
+
+func (d *doctor) pass(name, detail string) {
+	d.add(doctorCheck{Name: name, Status: "pass", Detail: detail})
+}
+
+func (d *doctor) warn(name, detail, hint string) {
+	d.add(doctorCheck{Name: name, Status: "warn", Detail: detail, Hint: hint})
+}
+
+func (d *doctor) fail(name, code, detail, hint string) {
+	d.add(doctorCheck{Name: name, Status: "fail", Detail: detail, Hint: hint})
+	if d.failCode == "" {
+		d.failCode, d.failMsg = code, fmt.Sprintf("doctor: %s check failed: %s", name, detail)
+	}
+}
+
+func (d *doctor) skip(names ...string) {
+	for _, name := range names {
+		d.add(doctorCheck{Name: name, Status: "skip"})
+	}
+}
+
+func runDoctor(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var timeout time.Duration
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "overall deadline for the checks")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+	if timeout <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "timeout must be > 0")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	d := &doctor{}
+	d.run(ctx, &rf, factory)
+
+	data := doctorData{OK: d.failCode == "", Checks: d.checks, Warnings: d.warnings}
+	if !jsonOut {
+		for _, c := range d.checks {
+			line := fmt.Sprintf("[%s] %s", c.Status, c.Name)
+			if c.Detail != "" {
+				line += ": " + c.Detail
+			}
+			fmt.Fprintln(stdout, line)
+			if c.Hint != "" {
+				fmt.Fprintf(stdout, "       hint: %s\n", c.Hint)
+			}
+		}
+	}
+	if !data.OK {
+		if jsonOut {
+			return writeErrData(errOut, stderr, jsonOut, d.failCode, d.failMsg, map[string]any{
+				"ok":     data.OK,
+				"checks": data.Checks,
+			})
+		}
+		return writeErr(errOut, stderr, jsonOut, d.failCode, d.failMsg)
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, data)
+	}
+	return 0
+}
+
+func (d *doctor) run(ctx context.Context, rf *rpcFlags, factory Factory) {
+	cfg, err := rf.config()
+	var env []string
+	for _, name := range []string{"JUNO_RPC_URL", "JUNO_RPC_USER", "JUNO_RPC_PASS", "JUNO_RPC_BEARER"} {
+		state := "unset"
+		if strings.TrimSpace(os.Getenv(name)) != "" {
+			state = "set"
+		}
+		env = append(env, name+"="+state)
+	}
+	if err != nil {
+		d.fail("config", "invalid_request", err.Error(), "pass --rpc-url, --rpc-user, and --rpc-pass, or set JUNO_RPC_URL, JUNO_RPC_USER, and JUNO_RPC_PASS")
+		d.skip("url", "tcp", "auth", "txindex", "sync")
+		return
+	}
+	if cfg.ReplayPath == "" && cfg.RPCBearer == "" && (cfg.RPCUser == "" || cfg.RPCPass == "") {
+		d.warn("config", strings.Join(env, " "), "no RPC credentials; junocashd needs --rpc-user/--rpc-pass (or JUNO_RPC_USER/JUNO_RPC_PASS) matching its rpcuser/rpcpassword")
+	} else {
+		d.pass("config", strings.Join(env, " "))
+	}
+
+	if cfg.ReplayPath != "" {
+		d.skip("url", "tcp")
+	} else {
+		u, err := url.Parse(cfg.RPCURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			detail := fmt.Sprintf("cannot use %q as an RPC URL", redactURL(cfg.RPCURL))
+			d.fail("url", "invalid_request", detail, "use http://host:port, e.g. http://127.0.0.1:8232")
+			d.skip("tcp", "auth", "txindex", "sync")
+			return
+		}
+		d.pass("url", redactURL(cfg.RPCURL))
+
+		addr := u.Host
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), map[string]string{"http": "80", "https": "443"}[u.Scheme])
+		}
+		if cfg.RPCSOCKS5 != "" {
+			addr = cfg.RPCSOCKS5
+		}
+		var dialer net.Dialer
+		dctx, dcancel := context.WithTimeout(ctx, 5*time.Second)
+		conn, err := dialer.DialContext(dctx, "tcp", addr)
+		dcancel()
+		if err != nil {
+			hint := "is junocashd running with -server=1, and do -rpcbind/-rpcallowip cover this host?"
+			if cfg.RPCSOCKS5 != "" {
+				hint = "is the SOCKS5 proxy (e.g. Tor) running at --rpc-socks5?"
+			}
+			d.fail("tcp", "node_rpc_error", err.Error(), hint)
+			d.skip("auth", "txindex", "sync")
+			return
+		}
+		conn.Close()
+		d.pass("tcp", "connected to "+addr)
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		d.fail("auth", "internal", err.Error(), "")
+		d.skip("txindex", "sync")
+		return
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	dr, ok := r.(doctorRunner)
+	if !ok {
+		d.fail("auth", "internal", "doctor checks are not supported", "")
+		d.skip("txindex", "sync")
+		return
+	}
+
+	if err := dr.Ping(ctx); err != nil {
+		hint := "the node answered but the call failed; check the URL path and any proxy in front of junocashd"
+		if errors.Is(err, broadcast.ErrAuth) {
+			hint = "check --rpc-user/--rpc-pass against junocashd's rpcuser/rpcpassword (or its .cookie file), or --rpc-bearer for a gateway"
+		}
+		d.fail("auth", errCode(err), err.Error(), hint)
+		d.skip("txindex", "sync")
+		return
+	}
+	d.pass("auth", "getblockcount succeeded")
+
+	switch ok, err := dr.TxindexEnabled(ctx); {
+	case err != nil:
+		d.warn("txindex", err.Error(), "")
+	case !ok:
+		d.warn("txindex", "getrawtransaction cannot find mined transactions",
+			"restart junocashd with -txindex=1 (then -reindex); without it status falls back to scanning the mempool and recent blocks")
+	default:
+		d.pass("txindex", "enabled")
+	}
+
+	h, err := dr.NodeHealth(ctx)
+	switch {
+	case err != nil:
+		d.fail("sync", errCode(err), err.Error(), "")
+		return
+	case h.InitialBlockDownload:
+		d.warn("sync", fmt.Sprintf("initial block download (blocks=%d headers=%d)", h.Blocks, h.Headers),
+			"wait for the node to sync before submitting; confirmations reported meanwhile may be misleading")
+	case h.Peers == 0:
+		d.warn("sync", fmt.Sprintf("blocks=%d, no peers", h.Blocks), "a submitted tx will not propagate until the node has peers; check its network connectivity")
+	default:
+		d.pass("sync", fmt.Sprintf("blocks=%d peers=%d", h.Blocks, h.Peers))
+	}
+
+	// Runners that cannot read the node's warnings field just omit the check.
+	wr, ok := r.(nodeWarningsRunner)
+	if !ok {
+		return
+	}
+	switch ws, err := wr.NodeWarnings(ctx); {
+	case err != nil:
+		d.warn("warnings", err.Error(), "")
+	case len(ws) > 0:
+		d.warnings = ws
+		d.warn("warnings", strings.Join(ws, "; "),
+			"the node flags a problem with itself or the chain; a warning about unknown block versions usually means junocashd is out of date")
+	default:
+		d.pass("warnings", "none")
+	}
+}
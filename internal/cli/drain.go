@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+	"github.com/Abdullah1738/juno-sdk-go/junocashd"
+)
+
+// Queue file suffixes. A queued tx is <name>.hex; drain claims it by renaming
+// it to <name>.hex.processing and, once settled, to .done or .failed.
+const (
+	queueSuffix      = ".hex"
+	processingSuffix = ".processing"
+	doneSuffix       = ".done"
+	failedSuffix     = ".failed"
+)
+
+// drainResult is one NDJSON record written by drain, per file handled.
+// Status is ok, already_present, err (moved to .failed), or retry (left
+// queued after a transient failure).
+type drainResult struct {
+	Version  string              `json:"version"`
+	Status   string              `json:"status"`
+	File     string              `json:"file"`
+	TxID     string              `json:"txid,omitempty"`
+	TxStatus *broadcast.TxStatus `json:"tx_status,omitempty"`
+	Error    *streamError        `json:"error,omitempty"`
+}
+
+func runDrain(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("drain", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var queueDir string
+	var poll time.Duration
+	var retryDelay time.Duration
+	var once bool
+	var dedupeWindow time.Duration
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&queueDir, "queue-dir", "", "directory to take <name>.hex raw tx files from")
+	fs.DurationVar(&poll, "poll", time.Second, "how often to rescan the queue directory when it is empty")
+	fs.DurationVar(&retryDelay, "retry-delay", 30*time.Second, "how long a file that failed transiently stays queued before it is retried")
+	fs.DurationVar(&dedupeWindow, "dedupe-window", 0, "answer a file repeating a tx submitted within this long with its txid, without sending it again (0 = off)")
+	fs.BoolVar(&once, "once", false, "handle every file queued at startup once, then exit")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "write setup errors to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, true, "invalid_request", err.Error())
+	}
+	queueDir = strings.TrimSpace(queueDir)
+	if queueDir == "" {
+		return writeErr(errOut, stderr, true, "invalid_request", "queue-dir is required")
+	}
+	if fi, err := os.Stat(queueDir); err != nil || !fi.IsDir() {
+		return writeErr(errOut, stderr, true, "invalid_request", "queue-dir must be an existing directory")
+	}
+	if poll <= 0 {
+		return writeErr(errOut, stderr, true, "invalid_request", "poll must be > 0")
+	}
+	if retryDelay < 0 {
+		return writeErr(errOut, stderr, true, "invalid_request", "retry-delay must be >= 0")
+	}
+	if dedupeWindow < 0 {
+		return writeErr(errOut, stderr, true, "invalid_request", "dedupe-window must be >= 0")
+	}
+	cfg.DedupeWindow = dedupeWindow
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, true, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := warmup(ctx, r); err != nil {
+		return writeErr(errOut, stderr, true, errCode(err), err.Error())
+	}
+
+	q := &drainQueue{dir: queueDir, r: r, retryDelay: retryDelay, enc: json.NewEncoder(stdout), notBefore: make(map[string]time.Time)}
+	return q.run(ctx, poll, once)
+}
+
+// drainQueue submits the raw txs queued as files in dir, at least once each.
+// A file is claimed by an atomic rename, so a tx is only lost if its file is;
+// a crash between the submit and the final rename leaves it .processing, and
+// it is submitted again on the next start. Txs the node already has are
+// reported as already_present, so resubmitting is harmless.
+type drainQueue struct {
+	dir        string
+	r          Runner
+	retryDelay time.Duration
+	enc        *json.Encoder
+
+	// notBefore holds when each transiently failed file may be retried.
+	notBefore map[string]time.Time
+	processed int
+	failed    bool
+	retried   bool
+}
+
+func (q *drainQueue) run(ctx context.Context, poll time.Duration, once bool) int {
+	// Files left .processing were claimed by a run that stopped before
+	// settling them; they go first.
+	if names, err := q.list(processingSuffix); err == nil {
+		for _, name := range names {
+			if ctx.Err() != nil {
+				break
+			}
+			q.handle(ctx, name)
+		}
+	}
+
+	started := make(map[string]bool)
+	for ctx.Err() == nil {
+		names, err := q.list(queueSuffix)
+		if err != nil {
+			_ = q.enc.Encode(drainResult{Version: jsonVersionV1, Status: "err", File: q.dir, Error: &streamError{Code: "internal", Message: err.Error()}})
+			return 1
+		}
+		var handled int
+		for _, name := range names {
+			if ctx.Err() != nil {
+				break
+			}
+			if once && started[name] {
+				continue
+			}
+			if t, ok := q.notBefore[name]; ok && time.Now().Before(t) {
+				continue
+			}
+			started[name] = true
+			if q.claim(name) {
+				q.handle(ctx, name+processingSuffix)
+				handled++
+			}
+		}
+		if once && handled == 0 {
+			return exitCode(q.failed || q.retried)
+		}
+		if handled > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(poll):
+		}
+	}
+	writeCancelled(q.enc, q.processed, nil)
+	return exitCode(q.failed)
+}
+
+// list returns the names in dir with suffix, in name order.
+func (q *drainQueue) list(suffix string) ([]string, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), suffix) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// claim takes name for this worker; it fails if another worker took it first.
+func (q *drainQueue) claim(name string) bool {
+	path := filepath.Join(q.dir, name)
+	return os.Rename(path, path+processingSuffix) == nil
+}
+
+// handle submits the claimed file name (<name>.hex.processing) and settles
+// it: .done on success, .failed on a permanent rejection, and back to .hex
+// for a retry after retryDelay on a transient failure.
+func (q *drainQueue) handle(ctx context.Context, name string) {
+	path := filepath.Join(q.dir, name)
+	base := strings.TrimSuffix(name, processingSuffix)
+	res := drainResult{Version: jsonVersionV1, File: base}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return // settled by another worker
+	}
+	raw := strings.TrimSpace(string(b))
+	if err == nil {
+		if _, herr := hex.DecodeString(raw); herr != nil || raw == "" {
+			err = errors.New("file does not hold a raw tx hex")
+		}
+	}
+	if err != nil {
+		res.Status, res.Error = "err", &streamError{Code: "invalid_request", Message: err.Error()}
+		q.failed = true
+		q.settle(path, base+failedSuffix, res)
+		return
+	}
+
+	if known, ok := alreadyPresent(ctx, q.r, raw, 0); ok {
+		res.Status, res.TxID, res.TxStatus = known.Status, known.TxID, known.TxStatus
+		q.settle(path, base+doneSuffix, res)
+		return
+	}
+
+	submitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	txid, err := q.r.Submit(submitCtx, raw)
+	cancel()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// Interrupted mid-submit: leave the file .processing so the next
+		// run picks it up.
+		return
+	case err == nil:
+		res.Status, res.TxID = "ok", txid
+		q.settle(path, base+doneSuffix, res)
+	case broadcast.IsAlreadyKnown(err):
+		res.Status = "already_present"
+		res.TxID, _ = txidOf(ctx, q.r, raw)
+		q.settle(path, base+doneSuffix, res)
+	case isTransientSubmitErr(err):
+		res.Status, res.Error = "retry", submitStreamError(err)
+		q.notBefore[base] = time.Now().Add(q.retryDelay)
+		q.retried = true
+		q.settle(path, base, res)
+	default:
+		res.Status, res.Error = "err", submitStreamError(err)
+		q.failed = true
+		q.settle(path, base+failedSuffix, res)
+	}
+}
+
+// settle renames the claimed file at path to name and reports res.
+func (q *drainQueue) settle(path, name string, res drainResult) {
+	if res.Status != "retry" {
+		delete(q.notBefore, res.File)
+	}
+	if err := os.Rename(path, filepath.Join(q.dir, name)); err != nil {
+		res.Status, res.Error = "err", &streamError{Code: "internal", Message: err.Error()}
+		q.failed = true
+	}
+	q.processed++
+	_ = q.enc.Encode(res)
+}
+
+// isTransientSubmitErr reports whether a failed submit may succeed later
+// unchanged: the node was unreachable, busy, or syncing, or the tx is valid
+// but not yet final (locktime, coinbase maturity). Rejections of the tx
+// itself, and policy checks the tx fails, are permanent.
+func isTransientSubmitErr(err error) bool {
+	switch errCode(err) {
+	case "locktime_not_met", "immature_coinbase", "node_syncing", "timeout":
+		return true
+	case "node_rpc_error":
+	default:
+		return false
+	}
+	var rpcErr *junocashd.RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case -22, -25, -26: // deserialization error, verify error, verify rejected
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type rawTxRunner interface {
+	RawTransaction(ctx context.Context, txid string) (string, bool, error)
+}
+
+type dumpResult struct {
+	TxID   string `json:"txid"`
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Bytes  int    `json:"bytes"`
+}
+
+func runDump(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var txid string
+	var outPath string
+	var hexOut bool
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&txid, "txid", "", "transaction id")
+	fs.StringVar(&outPath, "out", "", "file to write the raw tx to")
+	fs.BoolVar(&hexOut, "hex", false, "write hex instead of binary")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	txid = strings.ToLower(strings.TrimSpace(txid))
+	if txid == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "txid is required")
+	}
+	outPath = strings.TrimSpace(outPath)
+	if outPath == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "out is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	rr, ok := r.(rawTxRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "raw tx lookups are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	raw, found, err := rr.RawTransaction(ctx, txid)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if !found {
+		return writeErr(errOut, stderr, jsonOut, "not_found", "unknown txid")
+	}
+
+	res := dumpResult{TxID: txid, Path: outPath, Format: "binary"}
+	var data []byte
+	if hexOut {
+		res.Format = "hex"
+		data = []byte(raw + "\n")
+	} else {
+		data, _ = hex.DecodeString(raw)
+	}
+	res.Bytes = len(data)
+	if err := writeFileAtomic(outPath, data); err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+
+	if jsonOut {
+		return writeOK(stdout, jsonOut, res)
+	}
+	fmt.Fprintf(stdout, "%s: wrote %d bytes (%s) to %s\n", txid, res.Bytes, res.Format, outPath)
+	return 0
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into
+// place, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type etaRunner interface {
+	EstimateConfirmationTime(ctx context.Context, txid string, target int64) (time.Duration, error)
+}
+
+// statusETAResult is status output with --eta.
+type statusETAResult struct {
+	broadcast.TxStatus
+	RequiredConfs int64  `json:"required_confs"`
+	ETA           string `json:"eta,omitempty"`
+}
+
+// estimateETA returns the estimated time until txid reaches target as a
+// duration string rounded to the second, or "" if it cannot be estimated. An
+// estimate is only ever a hint, so failures are a warning on warn rather than
+// an error.
+func estimateETA(ctx context.Context, r Runner, txid string, target int64, warn io.Writer) string {
+	er, ok := r.(etaRunner)
+	if !ok {
+		return ""
+	}
+	d, err := er.EstimateConfirmationTime(ctx, txid, target)
+	if err != nil {
+		fmt.Fprintf(warn, "warning: eta: %v\n", err)
+		return ""
+	}
+	return d.Round(time.Second).String()
+}
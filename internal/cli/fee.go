@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type feeRunner interface {
+	TxFee(ctx context.Context, txid string) (broadcast.TxFee, bool, error)
+}
+
+func runFee(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("fee", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var txid string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&txid, "txid", "", "transaction id")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	txid = strings.TrimSpace(txid)
+	if txid == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "txid is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	fr, ok := r.(feeRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "fee lookups are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	fee, found, err := fr.TxFee(ctx, txid)
+	switch {
+	case errors.Is(err, broadcast.ErrCoinbaseFee):
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	case err != nil:
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	case !found:
+		return writeErr(errOut, stderr, jsonOut, "not_found", "transaction not found")
+	}
+	if !jsonOut && fee.Note != "" {
+		fmt.Fprintf(stderr, "note: %s\n", fee.Note)
+	}
+	return writeOK(stdout, jsonOut, fee)
+}
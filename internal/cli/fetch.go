@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxRawTxURLBytes caps the body fetched for --raw-tx-url; it is generous for
+// any standard tx in hex.
+const maxRawTxURLBytes = 4 << 20
+
+// loadRawTxInput is loadHexInput with --raw-tx-url and --raw-tx-clipboard as
+// additional input sources.
+func loadRawTxInput(hexValue, filePath, rawURL string, clipboard bool) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if err := checkRawTxSources(hexValue, filePath, rawURL, clipboard); err != nil {
+		return "", err
+	}
+	switch {
+	case rawURL != "":
+		return fetchRawTx(rawURL)
+	case clipboard:
+		s, err := readClipboard()
+		if err != nil {
+			return "", err
+		}
+		raw := strings.TrimSpace(s)
+		if raw == "" {
+			return "", errors.New("raw-tx-clipboard: clipboard is empty")
+		}
+		if _, err := hex.DecodeString(raw); err != nil {
+			return "", errors.New("raw-tx-clipboard: clipboard does not hold raw tx hex")
+		}
+		return raw, nil
+	}
+	return loadHexInput(hexValue, filePath, "raw-tx-hex", "raw-tx-file")
+}
+
+// checkRawTxSources fails if more than one raw tx input source is set.
+func checkRawTxSources(hexValue, filePath, rawURL string, clipboard bool) error {
+	n := 0
+	for _, set := range []bool{strings.TrimSpace(hexValue) != "", strings.TrimSpace(filePath) != "", strings.TrimSpace(rawURL) != "", clipboard} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		return errors.New("input source conflict (use only one of --raw-tx-hex, --raw-tx-file, --raw-tx-url, --raw-tx-clipboard)")
+	}
+	return nil
+}
+
+// fetchRawTx downloads raw tx hex from an http(s) URL. Proxies are taken from
+// the standard HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables.
+func fetchRawTx(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.New("raw-tx-url must be an http(s) URL")
+	}
+	name := redactURL(rawURL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("fetch raw-tx-url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", name, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("fetch %s: http %d", name, resp.StatusCode)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxRawTxURLBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", name, err)
+	}
+	if len(b) > maxRawTxURLBytes {
+		return "", fmt.Errorf("fetch %s: body exceeds %d bytes", name, maxRawTxURLBytes)
+	}
+	raw := strings.TrimSpace(string(b))
+	if raw == "" {
+		return "", fmt.Errorf("fetch %s: empty body", name)
+	}
+	if _, err := hex.DecodeString(raw); err != nil {
+		return "", fmt.Errorf("fetch %s: body is not raw tx hex", name)
+	}
+	return raw, nil
+}
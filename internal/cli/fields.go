@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseFields parses --fields, a comma-separated list of top-level fields of
+// the JSON data object to keep. Names are checked against the properties of
+// the schema defs the command can emit, so a typo fails up front instead of
+// projecting the result to an empty object after the work is done.
+func parseFields(s string, defs ...string) ([]string, error) {
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, d := range defs {
+		for name := range schema.Defs[d].Properties {
+			known[name] = true
+		}
+	}
+
+	fields := splitList(s)
+	if len(fields) == 0 {
+		return nil, errors.New("fields must list at least one field")
+	}
+	for _, f := range fields {
+		if !known[f] {
+			names := make([]string, 0, len(known))
+			for name := range known {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return nil, fmt.Errorf("unknown field %q in --fields (want %s)", f, strings.Join(names, ", "))
+		}
+	}
+	return fields, nil
+}
+
+// projectFields returns payload's JSON object with only fields kept, or
+// payload itself when fields is nil. Listed fields the payload omits, such as
+// an empty blockhash, stay omitted.
+func projectFields(payload any, fields []string) any {
+	if fields == nil {
+		return payload
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return payload
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return payload
+	}
+	out := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			out[f] = v
+		}
+	}
+	return out
+}
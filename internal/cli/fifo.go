@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runSubmitFIFO submits each line written to the FIFO at path and writes one
+// NDJSON result per line. The FIFO is reopened whenever its writer goes away,
+// so it keeps running until ctx is done, the FIFO is removed, or (with
+// stopOnError) a submit fails. With dedupe, lines whose txid the node
+// already knows (in mempool or on chain) are reported as already_present
+// instead of being submitted. Per-line results are tallied into stats, which
+// may be nil. Reported txids are written in order's byte order. When ctx is
+// done (the usual way to stop), a submit in flight is dropped without a
+// result and a trailing cancelled record reports how many lines were
+// processed; how many were still queued in the FIFO is unknown.
+func runSubmitFIFO(ctx context.Context, r Runner, path string, stopOnError, dedupe bool, order txidByteOrder, stats *batchStats, stdout io.Writer) int {
+	ctx, cancel := context.WithCancel(ctx)
+
+	lines := make(chan fifoLine)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- readFIFOLines(ctx, path, lines)
+	}()
+	defer func() {
+		cancel()
+		wakeFIFOReader(path)
+	}()
+
+	enc := json.NewEncoder(stdout)
+	var lineNo, processed int
+	var failed bool
+	for {
+		select {
+		case <-ctx.Done():
+			writeCancelled(enc, processed, nil)
+			return exitCode(failed)
+		case err := <-readErr:
+			if err != nil {
+				_ = enc.Encode(streamResult{
+					Version: jsonVersionV1,
+					Status:  "err",
+					Line:    lineNo,
+					Error:   &streamError{Code: "invalid_request", Message: err.Error()},
+				})
+				return 1
+			}
+			return exitCode(failed)
+		case l := <-lines:
+			lineNo, raw := l.n, l.raw
+
+			if dedupe {
+				if res, ok := alreadyPresent(ctx, r, raw, lineNo); ok {
+					stats.record(res)
+					_ = enc.Encode(order.result(res))
+					processed++
+					continue
+				}
+			}
+
+			submitCtx, submitCancel := context.WithTimeout(ctx, 2*time.Minute)
+			txid, err := r.Submit(submitCtx, raw)
+			submitCancel()
+			if err != nil && ctx.Err() != nil {
+				// Interrupted mid-submit: the line gets no result, so it is
+				// resubmitted (or deduped) on the next run.
+				writeCancelled(enc, processed, nil)
+				return exitCode(failed)
+			}
+
+			res := streamResult{Version: jsonVersionV1, Status: "ok", Line: lineNo, TxID: txid}
+			if err != nil {
+				failed = true
+				res = streamResult{
+					Version: jsonVersionV1,
+					Status:  "err",
+					Line:    lineNo,
+					Error:   submitStreamError(err),
+				}
+			}
+			stats.record(res)
+			_ = enc.Encode(order.result(res))
+			processed++
+			if err != nil && stopOnError {
+				return 1
+			}
+		}
+	}
+}
+
+// alreadyPresent reports the node's status for raw when it already knows the
+// tx. Txs whose txid cannot be computed or whose status lookup fails are
+// treated as unknown and submitted as usual.
+func alreadyPresent(ctx context.Context, r Runner, raw string, lineNo int) (streamResult, bool) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	txid, err := txidOf(ctx, r, raw)
+	if err != nil {
+		return streamResult{}, false
+	}
+	st, found, err := r.Status(ctx, txid)
+	if err != nil || !found {
+		return streamResult{}, false
+	}
+	return streamResult{Version: jsonVersionV1, Status: "already_present", Line: lineNo, TxID: txid, TxStatus: &st}, true
+}
+
+// fifoLine is one non-empty line read from the FIFO. n counts every line
+// read, blank ones included, across writers.
+type fifoLine struct {
+	n   int
+	raw string
+}
+
+func readFIFOLines(ctx context.Context, path string, lines chan<- fifoLine) error {
+	var n int
+	for {
+		// Opening a FIFO for reading blocks until a writer connects.
+		f, err := os.Open(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			_ = f.Close()
+			return nil
+		}
+
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64<<10), 20<<20)
+		for sc.Scan() {
+			n++
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			select {
+			case lines <- fifoLine{n: n, raw: line}:
+			case <-ctx.Done():
+				_ = f.Close()
+				return nil
+			}
+		}
+		err = sc.Err()
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// wakeFIFOReader unblocks a reader waiting in open(2) by briefly connecting as
+// a writer; it is a no-op when nobody is waiting.
+func wakeFIFOReader(path string) {
+	f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return
+	}
+	_ = f.Close()
+}
+
+func exitCode(failed bool) int {
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cli
+
+// lockFile is a no-op where flock is unavailable; concurrent runs sharing a
+// file there can lose each other's updates.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maxGzipRawTxBytes caps what --raw-tx-gzip decompresses to; the hex of the
+// largest tx junocashd accepts (2 MB) fits.
+const maxGzipRawTxBytes = 4 << 20
+
+// loadGzipRawTx reads --raw-tx-file for --raw-tx-gzip: gzip data, raw or
+// base64-wrapped, is decompressed, and the result (or the file as is, if it
+// is not gzip at all) must be raw tx hex or base64 of the raw tx bytes. The
+// file is read once and its magic checked on those bytes. The tx is returned
+// as hex.
+func loadGzipRawTx(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	name := filepath.Base(path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", name, err)
+	}
+	if !bytes.HasPrefix(b, gzipMagic) {
+		if dec, err := decodeBase64(string(b)); err == nil && bytes.HasPrefix(dec, gzipMagic) {
+			b = dec
+		}
+	}
+	if bytes.HasPrefix(b, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return "", fmt.Errorf("raw-tx-gzip: %s: %w", name, err)
+		}
+		b, err = io.ReadAll(io.LimitReader(zr, maxGzipRawTxBytes+1))
+		if err != nil {
+			return "", fmt.Errorf("raw-tx-gzip: %s: %w", name, err)
+		}
+		if len(b) > maxGzipRawTxBytes {
+			return "", fmt.Errorf("raw-tx-gzip: %s: decompresses to more than %d bytes", name, maxGzipRawTxBytes)
+		}
+	}
+
+	text := strings.TrimSpace(string(b))
+	if text == "" {
+		return "", fmt.Errorf("raw-tx-gzip: %s holds no tx", name)
+	}
+	if _, err := hex.DecodeString(text); err == nil {
+		return text, nil
+	}
+	if dec, err := decodeBase64(text); err == nil && len(dec) > 0 {
+		return hex.EncodeToString(dec), nil
+	}
+	return "", fmt.Errorf("raw-tx-gzip: %s does not hold raw tx hex or base64", name)
+}
+
+// decodeBase64 accepts standard or URL-safe base64, padded or not, ignoring
+// surrounding whitespace.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	var err error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		var b []byte
+		if b, err = enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type nodeHealthRunner interface {
+	NodeHealth(ctx context.Context) (broadcast.NodeHealth, error)
+}
+
+// nodeWarningsRunner is implemented by runners that can read the node's own
+// warnings field.
+type nodeWarningsRunner interface {
+	NodeWarnings(ctx context.Context) ([]string, error)
+}
+
+// warnNodeWarnings prints the node's own warnings to stderr on one line, if it
+// reports any. Failing to read them is only a warning too.
+func warnNodeWarnings(ctx context.Context, r Runner, stderr io.Writer) {
+	wr, ok := r.(nodeWarningsRunner)
+	if !ok {
+		return
+	}
+	ws, err := wr.NodeWarnings(ctx)
+	switch {
+	case err != nil:
+		fmt.Fprintf(stderr, "warning: cannot read node warnings: %v\n", err)
+	case len(ws) > 0:
+		fmt.Fprintf(stderr, "warning: node reports: %s\n", strings.Join(ws, "; "))
+	}
+}
+
+func runNodeHealth(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("node-health", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	hr, ok := r.(nodeHealthRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "node health checks are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	h, err := hr.NodeHealth(ctx)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, h)
+	}
+	fmt.Fprintf(stdout, "peers=%d blocks=%d headers=%d initial_block_download=%t\n", h.Peers, h.Blocks, h.Headers, h.InitialBlockDownload)
+	for _, w := range h.Warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+	return 0
+}
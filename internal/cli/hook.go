@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type hookResult struct {
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+const onConfirmedTimeout = 60 * time.Second
+
+// runOnConfirmed execs the --on-confirmed command (split on whitespace, no
+// shell) with the confirmed status in its environment. Its output goes to
+// stderr so it cannot corrupt JSON on stdout. A command still running after
+// timeout is killed and reported as a failed hook.
+func runOnConfirmed(command string, st broadcast.TxStatus, stderr io.Writer, timeout time.Duration) hookResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := strings.Fields(command)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"JUNO_TXID="+st.TxID,
+		"JUNO_CONFIRMATIONS="+strconv.FormatInt(st.Confirmations, 10),
+		"JUNO_BLOCKHASH="+st.BlockHash,
+	)
+	cmd.Stdout = stderr
+	cmd.Stderr = stderr
+	// Children that inherited the output pipe must not hold the hook open.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if err == nil {
+		return hookResult{}
+	}
+	if ctx.Err() != nil {
+		return hookResult{ExitCode: -1, Error: fmt.Sprintf("timed out after %s", timeout)}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return hookResult{ExitCode: exitErr.ExitCode(), Error: err.Error()}
+	}
+	return hookResult{ExitCode: -1, Error: err.Error()}
+}
+
+func warnHook(stderr io.Writer, res hookResult) {
+	if res.Error != "" {
+		fmt.Fprintf(stderr, "warning: on-confirmed hook: %s\n", res.Error)
+	}
+}
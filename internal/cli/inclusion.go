@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type inclusionRunner interface {
+	VerifyInclusion(ctx context.Context, txid string) (broadcast.InclusionProof, bool, error)
+}
+
+func runVerifyInclusion(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify-inclusion", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var txid string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&txid, "txid", "", "confirmed transaction id")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	txid = strings.TrimSpace(txid)
+	if txid == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "txid is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	ir, ok := r.(inclusionRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "inclusion proofs are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	proof, found, err := ir.VerifyInclusion(ctx, txid)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if !found {
+		return writeErr(errOut, stderr, jsonOut, "not_found", "unknown txid")
+	}
+
+	// A proof that does not verify is still reported as data, but fails the
+	// command so scripts can rely on the exit code.
+	writeOK(stdout, jsonOut, proof)
+	return exitCode(!proof.Verified)
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type mempoolRunner interface {
+	Mempool(ctx context.Context) ([]string, error)
+	MempoolInfo(ctx context.Context) (broadcast.MempoolInfo, error)
+}
+
+type feeRateRunner interface {
+	MempoolFeeRate(ctx context.Context, txid string) (float64, error)
+}
+
+// checkMinFeeRate reads txid's mempool fee rate and returns it, or a CLI error
+// code and message when it is below min sat/vB.
+func checkMinFeeRate(ctx context.Context, r Runner, txid string, min float64) (float64, string, error) {
+	fr, ok := r.(feeRateRunner)
+	if !ok {
+		return 0, "internal", errors.New("fee rate assertions are not supported")
+	}
+	rate, err := fr.MempoolFeeRate(ctx, txid)
+	if err != nil {
+		return 0, errCode(err), err
+	}
+	if rate < min {
+		return rate, "feerate_below_assertion", fmt.Errorf("tx %s has fee rate %g sat/vB in the mempool, below the asserted %g sat/vB", txid, rate, min)
+	}
+	return rate, "", nil
+}
+
+func runMempool(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mempool", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var countOnly bool
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.BoolVar(&countOnly, "count", false, "only report the mempool size (getmempoolinfo)")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	mr, ok := r.(mempoolRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "mempool queries are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if countOnly {
+		info, err := mr.MempoolInfo(ctx)
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+		}
+		return writeOK(stdout, jsonOut, info)
+	}
+
+	txids, err := mr.Mempool(ctx)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, map[string]any{"txids": txids, "size": len(txids)})
+	}
+	for _, txid := range txids {
+		fmt.Fprintln(stdout, txid)
+	}
+	return 0
+}
+
+type mempoolEntryRunner interface {
+	MempoolEntry(ctx context.Context, txid string) (broadcast.MempoolEntry, error)
+}
+
+// submittedEntry fetches the mempool entry of a just-submitted tx for
+// submit --with-entry. A tx that has already left the mempool, usually by
+// being mined, has no entry and yields nil; so does a failed lookup, with a
+// warning, since the tx is broadcast either way.
+func submittedEntry(ctx context.Context, mr mempoolEntryRunner, txid string, stderr io.Writer) *broadcast.MempoolEntry {
+	e, err := mr.MempoolEntry(ctx, txid)
+	switch {
+	case errors.Is(err, broadcast.ErrNotInMempool):
+		return nil
+	case err != nil:
+		fmt.Fprintf(stderr, "warning: cannot read the mempool entry of %s: %v\n", txid, err)
+		return nil
+	}
+	return &e
+}
+
+func runMempoolEntry(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mempool-entry", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var txid string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&txid, "txid", "", "transaction id")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	txid = strings.TrimSpace(txid)
+	if txid == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "txid is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	mr, ok := r.(mempoolEntryRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "mempool entry lookups are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	e, err := mr.MempoolEntry(ctx, txid)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, e)
+	}
+	fmt.Fprintf(stdout, "txid=%s size=%d fee=%g modified_fee=%g height=%d\n", e.TxID, e.Size, e.Fee, e.ModifiedFee, e.Height)
+	if e.AncestorCount != nil && e.AncestorSize != nil && e.AncestorFees != nil {
+		fmt.Fprintf(stdout, "ancestors: count=%d size=%d fees=%g\n", *e.AncestorCount, *e.AncestorSize, *e.AncestorFees)
+	}
+	fmt.Fprintf(stdout, "descendants: count=%d size=%d fees=%g\n", e.DescendantCount, e.DescendantSize, e.DescendantFees)
+	for _, d := range e.Depends {
+		fmt.Fprintf(stdout, "depends: %s\n", d)
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type multiSubmitter interface {
+	Endpoints() []string
+	SubmitAll(ctx context.Context, rawTxHex string) (map[string]string, []error)
+}
+
+// multiWaiter is implemented by runners that can wait for confirmations on
+// every --rpc-url at once.
+type multiWaiter interface {
+	WaitAcross(ctx context.Context, txid string, confirmations int64) (broadcast.TxStatus, error)
+}
+
+// propagationWaiter is implemented by runners that can wait for a tx to reach
+// several of the --rpc-url nodes.
+type propagationWaiter interface {
+	WaitPropagation(ctx context.Context, txid string, k int) error
+}
+
+type endpointResult struct {
+	Endpoint string       `json:"endpoint"`
+	TxID     string       `json:"txid,omitempty"`
+	Error    *streamError `json:"error,omitempty"`
+}
+
+// submitAll broadcasts to every endpoint and reports the outcomes in endpoint
+// order. It succeeds if at least one endpoint accepted the transaction.
+func submitAll(ctx context.Context, ms multiSubmitter, raw string, stderr io.Writer, jsonOut bool) (string, []endpointResult, error) {
+	accepted, errs := ms.SubmitAll(ctx, raw)
+	failed := make(map[string]error, len(errs))
+	for _, err := range errs {
+		var ee *broadcast.EndpointError
+		if errors.As(err, &ee) {
+			failed[ee.Endpoint] = ee.Err
+		}
+	}
+
+	var txid string
+	results := make([]endpointResult, 0, len(ms.Endpoints()))
+	for _, name := range ms.Endpoints() {
+		res := endpointResult{Endpoint: name}
+		if id, ok := accepted[name]; ok {
+			res.TxID = id
+			if txid == "" {
+				txid = id
+			}
+		} else if err := failed[name]; err != nil {
+			res.Error = submitStreamError(err)
+			if !jsonOut {
+				fmt.Fprintf(stderr, "warning: %s: %s\n", name, err)
+			}
+		}
+		results = append(results, res)
+	}
+
+	if txid == "" {
+		if len(errs) == 0 {
+			return "", results, errors.New("broadcast: no endpoint accepted the transaction")
+		}
+		return "", results, errors.Join(errs...)
+	}
+	return txid, results, nil
+}
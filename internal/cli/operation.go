@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type operationRunner interface {
+	Operation(ctx context.Context, opid string) (broadcast.OperationResult, error)
+	WaitForOperation(ctx context.Context, opid string) (broadcast.OperationResult, error)
+}
+
+func runOpResult(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("op-result", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var opid string
+	var wait bool
+	var pollStr string
+	var minPoll time.Duration
+	var timeout time.Duration
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&opid, "opid", "", "async operation id (from z_sendmany, z_shieldcoinbase, ...)")
+	fs.BoolVar(&wait, "wait", false, "block until the operation finishes")
+	fs.StringVar(&pollStr, "poll", "500ms", "with --wait, poll interval (e.g. 500ms, 2s)")
+	fs.DurationVar(&minPoll, "min-poll", defaultMinPoll, "smallest accepted --poll value")
+	fs.DurationVar(&timeout, "timeout", 10*time.Minute, "with --wait, give up after this long")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	opid = strings.TrimSpace(opid)
+	if opid == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "opid is required")
+	}
+	if timeout <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "timeout must be > 0")
+	}
+	poll, err := parsePoll(pollStr, minPoll)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+
+	cfg.PollInterval = poll
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	or, ok := r.(operationRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "async operation lookups are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var res broadcast.OperationResult
+	if wait {
+		res, err = or.WaitForOperation(ctx, opid)
+	} else {
+		res, err = or.Operation(ctx, opid)
+		if err == nil && res.Done() && res.Status != "success" {
+			err = fmt.Errorf("%w: %s: %s", broadcast.ErrOperationFailed, res.Status, res.Error)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, broadcast.ErrOperationFailed) {
+			return writeErrData(errOut, stderr, jsonOut, errCode(err), err.Error(), map[string]any{
+				"opid":   res.OpID,
+				"status": res.Status,
+			})
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return writeErr(errOut, stderr, jsonOut, "timeout", fmt.Sprintf("operation %s still %s after %s", opid, res.Status, timeout))
+		}
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+
+	if jsonOut {
+		return writeOK(stdout, jsonOut, res)
+	}
+	if res.TxID != "" {
+		fmt.Fprintln(stdout, res.TxID)
+	} else {
+		fmt.Fprintln(stdout, res.Status)
+	}
+	return 0
+}
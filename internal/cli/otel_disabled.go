@@ -0,0 +1,14 @@
+//go:build !otel
+
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracer(ctx context.Context, endpoint string) (trace.Tracer, func(context.Context) error, error) {
+	return nil, nil, errors.New("otel-endpoint: OpenTelemetry support not compiled in (build with -tags otel)")
+}
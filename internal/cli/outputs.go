@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type outputsRunner interface {
+	InspectOutputs(ctx context.Context, rawTxHex string) ([]broadcast.TxOutput, error)
+}
+
+type outputsData struct {
+	Outputs []broadcast.TxOutput `json:"outputs"`
+}
+
+func runOutputs(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("outputs", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var rawTxHex string
+	var rawTxFile string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&rawTxHex, "raw-tx-hex", "", "raw tx hex")
+	fs.StringVar(&rawTxFile, "raw-tx-file", "", "path to file containing raw tx hex")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	raw, err := loadHexInput(rawTxHex, rawTxFile, "raw-tx-hex", "raw-tx-file")
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	or, ok := r.(outputsRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "output inspection is not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	outs, err := or.InspectOutputs(ctx, raw)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, outputsData{Outputs: outs})
+	}
+	for _, o := range outs {
+		if o.Opaque {
+			fmt.Fprintf(stdout, "%s %d opaque\n", o.Pool, o.Index)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s %d %s %s %s\n", o.Pool, o.Index, o.Type, o.Value, strings.Join(o.Addresses, ","))
+	}
+	return 0
+}
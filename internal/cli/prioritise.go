@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type prioritiseRunner interface {
+	Prioritise(ctx context.Context, txid string, feeDeltaSat int64) error
+}
+
+type prioritiseResult struct {
+	TxID     string `json:"txid"`
+	FeeDelta int64  `json:"fee_delta"`
+}
+
+func runPrioritise(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("prioritise", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var txid string
+	var feeDelta int64
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&txid, "txid", "", "mempool transaction id")
+	fs.Int64Var(&feeDelta, "fee-delta", 0, "fee delta in zatoshis to add for mining (may be negative)")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	txid = strings.ToLower(strings.TrimSpace(txid))
+	if _, err := hex.DecodeString(txid); err != nil || len(txid) != 64 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "txid must be 32-byte hex")
+	}
+	if feeDelta == 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "fee-delta is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	pr, ok := r.(prioritiseRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "prioritisetransaction is not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := pr.Prioritise(ctx, txid, feeDelta); err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, prioritiseResult{TxID: txid, FeeDelta: feeDelta})
+	}
+	fmt.Fprintf(stdout, "%s fee_delta=%d\n", txid, feeDelta)
+	return 0
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type psbtDecoder interface {
+	DecodePSBT(ctx context.Context, psbtBase64 string) (json.RawMessage, error)
+}
+
+type psbtSubmitter interface {
+	SubmitPSBT(ctx context.Context, psbtBase64 string) (string, error)
+}
+
+func runPSBTDecode(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("psbt-decode", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var psbt string
+	var pretty bool
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&psbt, "psbt", "", "base64-encoded PSBT")
+	fs.BoolVar(&pretty, "pretty", false, "indent the JSON output")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	psbt = strings.TrimSpace(psbt)
+	if psbt == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "psbt is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	pd, ok := r.(psbtDecoder)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "psbt decoding is not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	decoded, err := pd.DecodePSBT(ctx, psbt)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+
+	var payload any = decoded
+	if jsonOut {
+		payload = map[string]any{"version": jsonVersionV1, "status": "ok", "data": decoded}
+	}
+	enc := json.NewEncoder(stdout)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	_ = enc.Encode(payload)
+	return 0
+}
+
+func runPSBTBroadcast(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("psbt-broadcast", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var psbt string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&psbt, "psbt", "", "base64-encoded PSBT to finalize and submit")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	psbt = strings.TrimSpace(psbt)
+	if psbt == "" {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "psbt is required")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	ps, ok := r.(psbtSubmitter)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "psbt broadcasting is not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	txid, err := ps.SubmitPSBT(ctx, psbt)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, map[string]any{"txid": txid})
+	}
+	fmt.Fprintln(stdout, txid)
+	return 0
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	_ "embed"
+	"flag"
+	"io"
+)
+
+// schemaJSON describes the --json envelopes written by writeOK/writeErr; keep
+// it in sync when a command's data shape or error codes change.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return writeErr(stderr, stderr, false, "invalid_request", "schema takes no arguments")
+	}
+	_, _ = stdout.Write(schemaJSON)
+	return 0
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormat is how a sink encodes result envelopes.
+type outputFormat string
+
+const (
+	formatCompact outputFormat = "compact"
+	formatPretty  outputFormat = "pretty"
+	formatYAML    outputFormat = "yaml"
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch f := outputFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", formatCompact:
+		return formatCompact, nil
+	case formatPretty, formatYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want compact, pretty, or yaml)", s)
+	}
+}
+
+// outputSink is one destination for result envelopes.
+type outputSink struct {
+	w      io.Writer
+	format outputFormat
+}
+
+// outputSinks fans the result envelope written by writeOK/writeErrData out to
+// an ordered list of sinks, each in its own format. The first sink is the
+// command's stdout (or stderr for --json-errors-stderr errors); it alone
+// receives plain-text output outside --json mode, while the other sinks
+// always get the envelope. Direct writes (text lines, NDJSON streams) go to
+// the first sink only.
+type outputSinks struct {
+	sinks  []outputSink
+	closer []io.Closer
+}
+
+func (s *outputSinks) Write(p []byte) (int, error) { return s.sinks[0].w.Write(p) }
+
+// withPrimary returns the same sinks with w, in the first sink's format,
+// taking the first sink's place.
+func (s *outputSinks) withPrimary(w io.Writer) *outputSinks {
+	sinks := append([]outputSink{{w: w, format: s.sinks[0].format}}, s.sinks[1:]...)
+	return &outputSinks{sinks: sinks}
+}
+
+func (s *outputSinks) Close() error {
+	var errs []error
+	for _, c := range s.closer {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// openOutputSinks builds the sink list for stdout in stdoutFormat plus one
+// sink per --output-file value, <path>[,<format>]. Files are appended to, so
+// a log accumulates one envelope per run.
+func openOutputSinks(stdout io.Writer, stdoutFormat string, files []string) (*outputSinks, error) {
+	f, err := parseOutputFormat(stdoutFormat)
+	if err != nil {
+		return nil, err
+	}
+	s := &outputSinks{sinks: []outputSink{{w: stdout, format: f}}}
+	for _, spec := range files {
+		path, format, _ := strings.Cut(spec, ",")
+		f, err := parseOutputFormat(format)
+		if err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("output-file %q: %w", spec, err)
+		}
+		file, err := os.OpenFile(strings.TrimSpace(path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("output-file: %w", err)
+		}
+		s.sinks = append(s.sinks, outputSink{w: file, format: f})
+		s.closer = append(s.closer, file)
+	}
+	return s, nil
+}
+
+// writeEnvelope writes env to w, or to every sink when w is an *outputSinks.
+// primaryText means the first sink already got plain-text output and is
+// skipped.
+func writeEnvelope(w io.Writer, env map[string]any, primaryText bool) {
+	s, ok := w.(*outputSinks)
+	if !ok {
+		if !primaryText {
+			_ = encodeEnvelope(w, env, formatCompact)
+		}
+		return
+	}
+	for i, sink := range s.sinks {
+		if i == 0 && primaryText {
+			continue
+		}
+		_ = encodeEnvelope(sink.w, env, sink.format)
+	}
+}
+
+func encodeEnvelope(w io.Writer, env map[string]any, format outputFormat) error {
+	switch format {
+	case formatPretty:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(env)
+	case formatYAML:
+		// Round-trip through JSON so struct payloads keep their json tags
+		// and field names.
+		b, err := json.Marshal(env)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(yamlNumbers(v))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append([]byte("---\n"), out...))
+		return err
+	default:
+		return json.NewEncoder(w).Encode(env)
+	}
+}
+
+// yamlNumbers replaces json.Numbers, which yaml would quote as strings, with
+// int64 or float64 values.
+func yamlNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = yamlNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = yamlNumbers(e)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+// confirmationState is the --state-file contents: the last confirmation count
+// observed for each txid.
+type confirmationState struct {
+	TxIDs map[string]int64 `json:"txids"`
+}
+
+// statusStateResult is status output with --state-file.
+type statusStateResult struct {
+	broadcast.TxStatus
+	RequiredConfs         int64  `json:"required_confs"`
+	PreviousConfirmations *int64 `json:"previous_confirmations,omitempty"`
+	Crossed               bool   `json:"crossed"`
+	ETA                   string `json:"eta,omitempty"`
+}
+
+func loadConfirmationState(path string) (confirmationState, error) {
+	st := confirmationState{TxIDs: map[string]int64{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, fmt.Errorf("state-file: %w", err)
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("state-file: %w", err)
+	}
+	if st.TxIDs == nil {
+		st.TxIDs = map[string]int64{}
+	}
+	return st, nil
+}
+
+// recordConfirmations stores st's confirmation count in the state file at
+// path and reports whether it reached target for the first time: the
+// previous count was below target (or unknown) and the current one is not.
+// A count that drops (a reorg) is stored too, so crossing again fires again.
+// The load and write happen under a lock on path+".lock", so concurrent runs
+// sharing a state file do not drop each other's txids.
+func recordConfirmations(path string, st broadcast.TxStatus, target int64) (statusStateResult, error) {
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return statusStateResult{}, fmt.Errorf("state-file: lock: %w", err)
+	}
+	defer unlock()
+
+	state, err := loadConfirmationState(path)
+	if err != nil {
+		return statusStateResult{}, err
+	}
+	res := statusStateResult{TxStatus: st, RequiredConfs: target}
+	prev, seen := state.TxIDs[st.TxID]
+	if seen {
+		res.PreviousConfirmations = &prev
+	}
+	res.Crossed = (!seen || prev < target) && st.Confirmations >= target
+
+	state.TxIDs[st.TxID] = st.Confirmations
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return statusStateResult{}, err
+	}
+	if err := writeFileAtomic(path, append(b, '\n')); err != nil {
+		return statusStateResult{}, fmt.Errorf("state-file: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statsFlag is --stats (JSON summary) or --stats=text (one human line).
+type statsFlag string
+
+func (f *statsFlag) String() string { return string(*f) }
+
+func (f *statsFlag) IsBoolFlag() bool { return true }
+
+func (f *statsFlag) Set(v string) error {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "true", "json":
+		*f = "json"
+	case "text":
+		*f = "text"
+	case "false", "":
+		*f = ""
+	default:
+		return fmt.Errorf("stats must be json or text")
+	}
+	return nil
+}
+
+// batchStats tallies the per-line results of a batch/stream run.
+type batchStats struct {
+	start   time.Time
+	Total   int            `json:"total"`
+	OK      int            `json:"succeeded"`
+	Failed  int            `json:"failed"`
+	Skipped int            `json:"skipped"`
+	Elapsed string         `json:"elapsed"`
+	ByCode  map[string]int `json:"failures_by_code,omitempty"`
+}
+
+func newBatchStats() *batchStats {
+	return &batchStats{start: time.Now()}
+}
+
+func (s *batchStats) record(res streamResult) {
+	if s == nil {
+		return
+	}
+	s.Total++
+	switch res.Status {
+	case "ok":
+		s.OK++
+	case "skipped", "already_present":
+		s.Skipped++
+	default:
+		s.Failed++
+		if res.Error != nil {
+			if s.ByCode == nil {
+				s.ByCode = make(map[string]int)
+			}
+			s.ByCode[res.Error.Code]++
+		}
+	}
+}
+
+func (s *batchStats) write(w io.Writer, mode statsFlag) {
+	if s == nil || mode == "" {
+		return
+	}
+	s.Elapsed = time.Since(s.start).Round(time.Millisecond).String()
+	if mode == "json" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"version": jsonVersionV1, "stats": s})
+		return
+	}
+
+	line := fmt.Sprintf("total=%d succeeded=%d failed=%d skipped=%d elapsed=%s", s.Total, s.OK, s.Failed, s.Skipped, s.Elapsed)
+	codes := make([]string, 0, len(s.ByCode))
+	for code := range s.ByCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		line += fmt.Sprintf(" %s=%d", code, s.ByCode[code])
+	}
+	fmt.Fprintln(w, line)
+}
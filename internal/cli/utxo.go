@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type outpointRunner interface {
+	Outpoint(ctx context.Context, txid string, vout uint32) (broadcast.OutpointStatus, error)
+}
+
+func runUTXO(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("utxo", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var outpoint string
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.StringVar(&outpoint, "outpoint", "", "outpoint to check, as <txid>:<vout>")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	txid, vout, err := parseOutpoint(outpoint)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	or, ok := r.(outpointRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "utxo queries are not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	st, err := or.Outpoint(ctx, txid, vout)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+
+	payload := map[string]any{"outpoint": fmt.Sprintf("%s:%d", txid, vout)}
+	if st.Spent {
+		payload["status"] = "spent"
+		if st.SpendingTxID != "" {
+			payload["by"] = st.SpendingTxID
+		}
+	} else {
+		payload["status"] = "unspent"
+		payload["confirmations"] = st.Confirmations
+	}
+	return writeOK(stdout, jsonOut, payload)
+}
+
+func parseOutpoint(s string) (string, uint32, error) {
+	txid, voutStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || txid == "" {
+		return "", 0, fmt.Errorf("outpoint must be <txid>:<vout>")
+	}
+	vout, err := strconv.ParseUint(voutStr, 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("outpoint vout must be a non-negative integer")
+	}
+	return strings.ToLower(txid), uint32(vout), nil
+}
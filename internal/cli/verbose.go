@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+// verboseLog writes a --verbose line to w for every RPC call, tagged with an
+// id that is stable for the invocation, the time since it started, and the
+// call's poll iteration and retry attempt, so interleaved retries and polls
+// can be reconstructed from logs alone.
+type verboseLog struct {
+	mu    sync.Mutex
+	w     io.Writer
+	id    string
+	start time.Time
+}
+
+func newVerboseLog(w io.Writer) *verboseLog {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return &verboseLog{w: w, id: hex.EncodeToString(b[:]), start: time.Now()}
+}
+
+func (l *verboseLog) event(e broadcast.AttemptEvent) {
+	result := "ok"
+	if e.Err != nil {
+		result = "error: " + e.Err.Error()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, "run=%s elapsed=%s poll=%d attempt=%d %s %s (%s)\n",
+		l.id, time.Since(l.start).Round(time.Millisecond), e.Poll, e.Attempt, e.Method, result, e.Duration.Round(time.Millisecond))
+}
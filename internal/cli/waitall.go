@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Abdullah1738/juno-broadcast/internal/broadcast"
+)
+
+type waitAllRunner interface {
+	WaitForAll(ctx context.Context, txids []string, confirmations int64, minSuccess int) ([]broadcast.WaitAllResult, error)
+}
+
+// waitTargetsRunner is implemented by runners that can wait with a
+// confirmation target per txid.
+type waitTargetsRunner interface {
+	WaitForAllTargets(ctx context.Context, targets map[string]int64, minSuccess int) ([]broadcast.WaitAllResult, error)
+}
+
+type waitAllData struct {
+	RequiredConfs int64                     `json:"required_confs"`
+	MinSuccess    int                       `json:"min_success"`
+	Met           int                       `json:"met"`
+	Results       []broadcast.WaitAllResult `json:"results"`
+}
+
+func runWaitAll(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("wait-all", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var txids stringList
+	var txidFile string
+	var confirmations int64
+	var minSuccess int
+	var quorum float64
+	var pollStr string
+	var timeout time.Duration
+	var jsonOut bool
+	var jsonErrorsStderr bool
+	var trustTxID bool
+
+	rf.register(fs)
+	fs.Var(&txids, "txid", "transaction id to wait for (repeatable)")
+	fs.StringVar(&txidFile, "txid-file", "", "path to a file with one txid[,confirmations] per line (- for stdin)")
+	fs.Int64Var(&confirmations, "confirmations", 1, "confirmations each txid must reach, unless its --txid-file line sets its own")
+	fs.IntVar(&minSuccess, "min-success", 0, "succeed once this many txids reach the target (0 = all)")
+	fs.Float64Var(&quorum, "quorum", 0, "succeed once this fraction of txids (0-1] reaches the target, rounded up; replaces --min-success")
+	fs.StringVar(&pollStr, "poll", "500ms", "poll interval (e.g. 500ms, 2s)")
+	fs.DurationVar(&timeout, "timeout", 10*time.Minute, "give up after this long")
+	fs.BoolVar(&trustTxID, "trust-txid", false, "skip txid hex/length validation; malformed txids reach the node as is")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	ids := []string(txids)
+	var targets map[string]int64
+	if strings.TrimSpace(txidFile) != "" {
+		if len(ids) > 0 {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "input source conflict (use only one of --txid, --txid-file)")
+		}
+		ids, targets, err = readTxidLines(txidFile)
+		if err != nil {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+		}
+	}
+	if len(ids) == 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "txid or txid-file is required")
+	}
+	if confirmations < 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "confirmations must be >= 0")
+	}
+	if timeout <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "timeout must be > 0")
+	}
+	switch {
+	case quorum != 0 && minSuccess != 0:
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "use only one of --min-success and --quorum")
+	case quorum != 0:
+		if quorum < 0 || quorum > 1 {
+			return writeErr(errOut, stderr, jsonOut, "invalid_request", "quorum must be in (0, 1]")
+		}
+		minSuccess = quorumCount(quorum, len(ids))
+	case minSuccess < 0 || minSuccess > len(ids):
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", fmt.Sprintf("min-success must be between 0 and the number of txids (%d)", len(ids)))
+	case minSuccess == 0:
+		minSuccess = len(ids)
+	}
+	poll, err := parsePoll(pollStr, defaultMinPoll)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	cfg.PollInterval = poll
+	cfg.TrustTxID = trustTxID
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	wr, ok := r.(waitAllRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "wait-all is not supported")
+	}
+	tr, ok := r.(waitTargetsRunner)
+	if !ok && targets != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", "per-txid confirmation targets are not supported")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var results []broadcast.WaitAllResult
+	if targets != nil {
+		results, err = waitTargets(ctx, tr, ids, targets, confirmations, minSuccess)
+	} else {
+		results, err = wr.WaitForAll(ctx, ids, confirmations, minSuccess)
+	}
+	data := waitAllData{RequiredConfs: confirmations, MinSuccess: minSuccess, Results: results}
+	for _, res := range results {
+		if res.Met {
+			data.Met++
+		}
+	}
+	if err != nil && results == nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+
+	if !jsonOut {
+		for _, res := range results {
+			mark := "pending"
+			if res.Met {
+				mark = "met"
+			}
+			fmt.Fprintf(stdout, "%s %s confirmations=%d/%d\n", res.TxID, mark, res.Confirmations, res.RequiredConfs)
+		}
+	}
+	if err != nil {
+		if jsonOut {
+			return writeErrData(errOut, stderr, jsonOut, errCode(err), err.Error(), map[string]any{
+				"required_confs": data.RequiredConfs,
+				"min_success":    data.MinSuccess,
+				"met":            data.Met,
+				"results":        data.Results,
+			})
+		}
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		return writeOK(stdout, jsonOut, data)
+	}
+	return 0
+}
+
+// quorumCount is the number of n txids a --quorum fraction requires, rounded
+// up so that e.g. 0.9 of 10 needs 9 and 0.5 of 3 needs 2.
+func quorumCount(fraction float64, n int) int {
+	// Trim float noise so 0.9*10 is 9, not 10.
+	need := int(math.Ceil(fraction*float64(n) - 1e-9))
+	return max(need, 1)
+}
+
+// waitTargets waits with each txid's own target from targets (the default
+// for txids without one) and returns the results in ids order.
+func waitTargets(ctx context.Context, tr waitTargetsRunner, ids []string, targets map[string]int64, def int64, minSuccess int) ([]broadcast.WaitAllResult, error) {
+	all := make(map[string]int64, len(ids))
+	for _, id := range ids {
+		all[id] = def
+		if n, ok := targets[id]; ok {
+			all[id] = n
+		}
+	}
+	results, err := tr.WaitForAllTargets(ctx, all, minSuccess)
+	if results == nil {
+		return nil, err
+	}
+	// WaitForAllTargets reports in sorted txid order.
+	sorted := slices.Sorted(maps.Keys(all))
+	byID := make(map[string]broadcast.WaitAllResult, len(sorted))
+	for i, id := range sorted {
+		if i < len(results) {
+			byID[id] = results[i]
+		}
+	}
+	ordered := make([]broadcast.WaitAllResult, len(ids))
+	for i, id := range ids {
+		ordered[i] = byID[id]
+	}
+	return ordered, err
+}
+
+// readTxidLines reads one txid per line from path ("-" for stdin), skipping
+// blank lines. A line may add its own confirmation target as
+// "txid,confirmations"; targets holds those, and is nil when no line sets
+// one. With targets, a txid may only be listed once.
+func readTxidLines(path string) ([]string, map[string]int64, error) {
+	in, closeIn, err := openLineInput(path, "txid-file")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeIn()
+
+	var ids []string
+	var targets map[string]int64
+	sc := bufio.NewScanner(in)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		id, confs, ok := strings.Cut(line, ",")
+		id = strings.TrimSpace(id)
+		ids = append(ids, id)
+		if !ok {
+			continue
+		}
+		target, err := strconv.ParseInt(strings.TrimSpace(confs), 10, 64)
+		if err != nil || target < 0 {
+			return nil, nil, fmt.Errorf("txid-file line %d: confirmations must be an integer >= 0", n)
+		}
+		if targets == nil {
+			targets = make(map[string]int64)
+		}
+		targets[id] = target
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read txid-file: %w", err)
+	}
+	if targets != nil {
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				return nil, nil, fmt.Errorf("txid-file: %s is listed more than once", id)
+			}
+			seen[id] = true
+		}
+	}
+	return ids, targets, nil
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+type resubmitWalletRunner interface {
+	ResubmitWallet(ctx context.Context) ([]string, error)
+}
+
+type resubmitWalletResult struct {
+	TxIDs []string `json:"txids"`
+}
+
+func runResubmitWallet(args []string, factory Factory, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("resubmit-wallet", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var rf rpcFlags
+	var timeout time.Duration
+	var jsonOut bool
+	var jsonErrorsStderr bool
+
+	rf.register(fs)
+	fs.DurationVar(&timeout, "timeout", 2*time.Minute, "overall time limit")
+	fs.BoolVar(&jsonOut, "json", false, "JSON output")
+	fs.BoolVar(&jsonErrorsStderr, "json-errors-stderr", false, "in JSON mode, write the error envelope to stderr instead of stdout")
+
+	if code, ok := parseFlags(fs, args, stdout, stderr); !ok {
+		return code
+	}
+	errOut := jsonErrWriter(stdout, stderr, jsonErrorsStderr)
+
+	cfg, err := rf.config()
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", err.Error())
+	}
+	if timeout <= 0 {
+		return writeErr(errOut, stderr, jsonOut, "invalid_request", "timeout must be > 0")
+	}
+
+	r, err := factory(cfg)
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, "internal", err.Error())
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	wr, ok := r.(resubmitWalletRunner)
+	if !ok {
+		return writeErr(errOut, stderr, jsonOut, "internal", "resubmit-wallet is not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	txids, err := wr.ResubmitWallet(ctx)
+	if !jsonOut {
+		// Report what was handled even if some txs failed.
+		for _, txid := range txids {
+			fmt.Fprintln(stdout, txid)
+		}
+	}
+	if err != nil {
+		return writeErr(errOut, stderr, jsonOut, errCode(err), err.Error())
+	}
+	if jsonOut {
+		if txids == nil {
+			txids = []string{}
+		}
+		return writeOK(stdout, jsonOut, resubmitWalletResult{TxIDs: txids})
+	}
+	return 0
+}
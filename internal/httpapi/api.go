@@ -18,6 +18,12 @@ type Broadcaster interface {
 	WaitForConfirmations(ctx context.Context, txid string, confirmations int64) (broadcast.TxStatus, error)
 }
 
+// HealthReporter is optionally implemented by a Broadcaster that tracks node
+// connectivity; /healthz reports degraded while it is unhealthy.
+type HealthReporter interface {
+	Healthy() (bool, error)
+}
+
 type API struct {
 	bc           Broadcaster
 	maxBodyBytes int64
@@ -58,6 +64,16 @@ func (a *API) Handler() http.Handler {
 }
 
 func (a *API) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if hr, ok := a.bc.(HealthReporter); ok {
+		if healthy, err := hr.Healthy(); !healthy {
+			resp := map[string]string{"status": "degraded"}
+			if err != nil {
+				resp["error"] = err.Error()
+			}
+			writeJSON(w, http.StatusServiceUnavailable, resp)
+			return
+		}
+	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
@@ -91,7 +107,7 @@ func (a *API) handleSubmit(w http.ResponseWriter, r *http.Request) {
 	if req.WaitConfirmations != nil && *req.WaitConfirmations > 0 {
 		txid, err := a.bc.Submit(ctx, raw)
 		if err != nil {
-			writeError(w, http.StatusBadGateway, "node_rpc_error", err.Error())
+			writeRPCError(w, err)
 			return
 		}
 
@@ -100,7 +116,7 @@ func (a *API) handleSubmit(w http.ResponseWriter, r *http.Request) {
 
 		st, err := a.bc.WaitForConfirmations(waitCtx, txid, *req.WaitConfirmations)
 		if err != nil {
-			writeError(w, http.StatusBadGateway, "node_rpc_error", err.Error())
+			writeRPCError(w, err)
 			return
 		}
 
@@ -110,7 +126,7 @@ func (a *API) handleSubmit(w http.ResponseWriter, r *http.Request) {
 
 	txid, err := a.bc.Submit(ctx, raw)
 	if err != nil {
-		writeError(w, http.StatusBadGateway, "node_rpc_error", err.Error())
+		writeRPCError(w, err)
 		return
 	}
 
@@ -130,7 +146,7 @@ func (a *API) handleStatus(w http.ResponseWriter, r *http.Request) {
 
 	st, found, err := a.bc.Status(r.Context(), txid)
 	if err != nil {
-		writeError(w, http.StatusBadGateway, "node_rpc_error", err.Error())
+		writeRPCError(w, err)
 		return
 	}
 	if !found {
@@ -148,6 +164,23 @@ type errorResponse struct {
 	} `json:"error"`
 }
 
+func writeRPCError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, broadcast.ErrNodeSyncing):
+		writeError(w, http.StatusServiceUnavailable, "node_syncing", err.Error())
+	case errors.Is(err, broadcast.ErrAuth):
+		writeError(w, http.StatusBadGateway, "auth_failed", err.Error())
+	case errors.Is(err, broadcast.ErrTxindexRequired):
+		writeError(w, http.StatusBadGateway, "txindex_required", err.Error())
+	case errors.Is(err, broadcast.ErrReadOnly):
+		writeError(w, http.StatusForbidden, "read_only", err.Error())
+	case errors.Is(err, broadcast.ErrEmptyTxID):
+		writeError(w, http.StatusBadGateway, "empty_txid", err.Error())
+	default:
+		writeError(w, http.StatusBadGateway, "node_rpc_error", err.Error())
+	}
+}
+
 func writeError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
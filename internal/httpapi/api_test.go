@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -194,3 +196,65 @@ func TestAPI_Status_Success(t *testing.T) {
 		t.Fatalf("unexpected status: %+v", st)
 	}
 }
+
+func TestAPI_Status_NodeSyncing(t *testing.T) {
+	api, err := New(fakeBroadcaster{
+		status: func(ctx context.Context, txid string) (broadcast.TxStatus, bool, error) {
+			return broadcast.TxStatus{}, false, fmt.Errorf("%w: block height 100 of 200", broadcast.ErrNodeSyncing)
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/tx/"+strings.Repeat("e", 64), nil)
+	api.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status=%d want %d body=%s", rr.Code, http.StatusServiceUnavailable, rr.Body.String())
+	}
+
+	var resp errorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v body=%s", err, rr.Body.String())
+	}
+	if resp.Error.Code != "node_syncing" {
+		t.Fatalf("code=%q want node_syncing", resp.Error.Code)
+	}
+}
+
+type healthBroadcaster struct {
+	fakeBroadcaster
+	err error
+}
+
+func (h healthBroadcaster) Healthy() (bool, error) {
+	return h.err == nil, h.err
+}
+
+func TestAPI_Healthz_Degraded(t *testing.T) {
+	api, err := New(healthBroadcaster{err: errors.New("connection refused")})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	api.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status=%d want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(rr.Body.String(), `"status":"degraded"`) {
+		t.Fatalf("unexpected body: %s", rr.Body.String())
+	}
+
+	api, err = New(healthBroadcaster{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	api.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status=%d want %d", rr.Code, http.StatusOK)
+	}
+}